@@ -5,9 +5,9 @@ Fast, local ingredient detection using Salesforce BLIP and OpenAI CLIP models
 import io
 import logging
 import re
-from typing import List, Dict, Any, Set
+from typing import List, Dict, Any, Optional, Set
 from PIL import Image
-from fastapi import FastAPI, File, UploadFile, HTTPException
+from fastapi import FastAPI, File, Header, UploadFile, HTTPException
 from fastapi.middleware.cors import CORSMiddleware
 import torch
 from transformers import BlipProcessor, BlipForConditionalGeneration, CLIPProcessor, CLIPModel
@@ -293,18 +293,19 @@ async def health():
 
 @app.post("/detect")
 @app.post("/detect-ingredients")
-async def detect_ingredients(file: UploadFile = File(...)):
+async def detect_ingredients(file: UploadFile = File(...), x_request_id: Optional[str] = Header(None)):
     """
     Detect ingredients from an uploaded image using both BLIP and CLIP
     
     Args:
         file: Uploaded image file (JPEG, PNG, etc.)
+        x_request_id: Request ID forwarded by the backend, for correlating logs
     
     Returns:
         JSON with detected ingredients list, cuisine, dish type, caption, and confidence
     """
     try:
-        logger.info(f"Received request - filename: {file.filename}, content_type: {file.content_type}")
+        logger.info(f"Received request - request_id: {x_request_id or '-'}, filename: {file.filename}, content_type: {file.content_type}")
         
         if blip_model is None or blip_processor is None or clip_model is None or clip_processor is None:
             logger.error("Models not loaded!")
@@ -0,0 +1,12 @@
+// Package api embeds the OpenAPI 3.1 document describing the HTTP API,
+// which the server serves at /openapi.json and validates requests and
+// responses against. Every route must have an entry: the server refuses to
+// start otherwise.
+package api
+
+import _ "embed"
+
+// OpenAPI is the OpenAPI document, as JSON.
+//
+//go:embed openapi.json
+var OpenAPI []byte
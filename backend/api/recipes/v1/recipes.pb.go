@@ -0,0 +1,1172 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: recipes/v1/recipes.proto
+
+package recipesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// RecipeSort orders a listing of recipes.
+type RecipeSort int32
+
+const (
+	// By ID, or by relevance for searches.
+	RecipeSort_RECIPE_SORT_UNSPECIFIED RecipeSort = 0
+	// Best rated first.
+	RecipeSort_RECIPE_SORT_RATING RecipeSort = 1
+	// Most recently added first.
+	RecipeSort_RECIPE_SORT_NEWEST RecipeSort = 2
+	// Shortest total time first.
+	RecipeSort_RECIPE_SORT_QUICKEST RecipeSort = 3
+	// Most favorited, rated and viewed first.
+	RecipeSort_RECIPE_SORT_POPULAR RecipeSort = 4
+)
+
+// Enum value maps for RecipeSort.
+var (
+	RecipeSort_name = map[int32]string{
+		0: "RECIPE_SORT_UNSPECIFIED",
+		1: "RECIPE_SORT_RATING",
+		2: "RECIPE_SORT_NEWEST",
+		3: "RECIPE_SORT_QUICKEST",
+		4: "RECIPE_SORT_POPULAR",
+	}
+	RecipeSort_value = map[string]int32{
+		"RECIPE_SORT_UNSPECIFIED": 0,
+		"RECIPE_SORT_RATING":      1,
+		"RECIPE_SORT_NEWEST":      2,
+		"RECIPE_SORT_QUICKEST":    3,
+		"RECIPE_SORT_POPULAR":     4,
+	}
+)
+
+func (x RecipeSort) Enum() *RecipeSort {
+	p := new(RecipeSort)
+	*p = x
+	return p
+}
+
+func (x RecipeSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecipeSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_recipes_v1_recipes_proto_enumTypes[0].Descriptor()
+}
+
+func (RecipeSort) Type() protoreflect.EnumType {
+	return &file_recipes_v1_recipes_proto_enumTypes[0]
+}
+
+func (x RecipeSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecipeSort.Descriptor instead.
+func (RecipeSort) EnumDescriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{0}
+}
+
+// Recipe is a recipe with its full contents. Fields the recipe does not
+// give are empty, or unset for the optional ones.
+type Recipe struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title            string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description      string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Cuisine          string                 `protobuf:"bytes,4,opt,name=cuisine,proto3" json:"cuisine,omitempty"`
+	Difficulty       string                 `protobuf:"bytes,5,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
+	DietType         string                 `protobuf:"bytes,6,opt,name=diet_type,json=dietType,proto3" json:"diet_type,omitempty"`
+	PrepTimeMinutes  *int32                 `protobuf:"varint,7,opt,name=prep_time_minutes,json=prepTimeMinutes,proto3,oneof" json:"prep_time_minutes,omitempty"`
+	CookTimeMinutes  *int32                 `protobuf:"varint,8,opt,name=cook_time_minutes,json=cookTimeMinutes,proto3,oneof" json:"cook_time_minutes,omitempty"`
+	TotalTimeMinutes *int32                 `protobuf:"varint,9,opt,name=total_time_minutes,json=totalTimeMinutes,proto3,oneof" json:"total_time_minutes,omitempty"`
+	Servings         *int32                 `protobuf:"varint,10,opt,name=servings,proto3,oneof" json:"servings,omitempty"`
+	Ingredients      []*Ingredient          `protobuf:"bytes,11,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	Steps            []string               `protobuf:"bytes,12,rep,name=steps,proto3" json:"steps,omitempty"`
+	// Nutrition facts as stored with the recipe, e.g. {"calories": 420}.
+	Nutrition *structpb.Struct `protobuf:"bytes,13,opt,name=nutrition,proto3" json:"nutrition,omitempty"`
+	Tags      []string         `protobuf:"bytes,14,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Average rating from 1 to 5, or 0 if the recipe has no ratings.
+	AverageRating float64 `protobuf:"fixed64,15,opt,name=average_rating,json=averageRating,proto3" json:"average_rating,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recipe) Reset() {
+	*x = Recipe{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recipe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recipe) ProtoMessage() {}
+
+func (x *Recipe) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recipe.ProtoReflect.Descriptor instead.
+func (*Recipe) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Recipe) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Recipe) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Recipe) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Recipe) GetCuisine() string {
+	if x != nil {
+		return x.Cuisine
+	}
+	return ""
+}
+
+func (x *Recipe) GetDifficulty() string {
+	if x != nil {
+		return x.Difficulty
+	}
+	return ""
+}
+
+func (x *Recipe) GetDietType() string {
+	if x != nil {
+		return x.DietType
+	}
+	return ""
+}
+
+func (x *Recipe) GetPrepTimeMinutes() int32 {
+	if x != nil && x.PrepTimeMinutes != nil {
+		return *x.PrepTimeMinutes
+	}
+	return 0
+}
+
+func (x *Recipe) GetCookTimeMinutes() int32 {
+	if x != nil && x.CookTimeMinutes != nil {
+		return *x.CookTimeMinutes
+	}
+	return 0
+}
+
+func (x *Recipe) GetTotalTimeMinutes() int32 {
+	if x != nil && x.TotalTimeMinutes != nil {
+		return *x.TotalTimeMinutes
+	}
+	return 0
+}
+
+func (x *Recipe) GetServings() int32 {
+	if x != nil && x.Servings != nil {
+		return *x.Servings
+	}
+	return 0
+}
+
+func (x *Recipe) GetIngredients() []*Ingredient {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *Recipe) GetSteps() []string {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Recipe) GetNutrition() *structpb.Struct {
+	if x != nil {
+		return x.Nutrition
+	}
+	return nil
+}
+
+func (x *Recipe) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Recipe) GetAverageRating() float64 {
+	if x != nil {
+		return x.AverageRating
+	}
+	return 0
+}
+
+// Ingredient is an ingredient of a recipe. Quantity is unset and unit empty
+// when the recipe does not give them.
+type Ingredient struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      *float64               `protobuf:"fixed64,2,opt,name=quantity,proto3,oneof" json:"quantity,omitempty"`
+	Unit          string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ingredient) Reset() {
+	*x = Ingredient{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ingredient) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ingredient) ProtoMessage() {}
+
+func (x *Ingredient) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ingredient.ProtoReflect.Descriptor instead.
+func (*Ingredient) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ingredient) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Ingredient) GetQuantity() float64 {
+	if x != nil && x.Quantity != nil {
+		return *x.Quantity
+	}
+	return 0
+}
+
+func (x *Ingredient) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+// RecipeFilter narrows the recipes of a listing. Values within one list are
+// alternatives (cuisine is Italian or Thai); different fields must all
+// hold. Recipes with an unknown value for a filtered field never pass.
+type RecipeFilter struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Diets also match recipes of stricter diets: a vegan recipe passes a
+	// vegetarian filter.
+	Diets               []string `protobuf:"bytes,1,rep,name=diets,proto3" json:"diets,omitempty"`
+	Difficulties        []string `protobuf:"bytes,2,rep,name=difficulties,proto3" json:"difficulties,omitempty"`
+	Cuisines            []string `protobuf:"bytes,3,rep,name=cuisines,proto3" json:"cuisines,omitempty"`
+	Tags                []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	MaxTotalTimeMinutes *int32   `protobuf:"varint,5,opt,name=max_total_time_minutes,json=maxTotalTimeMinutes,proto3,oneof" json:"max_total_time_minutes,omitempty"`
+	MaxPrepTimeMinutes  *int32   `protobuf:"varint,6,opt,name=max_prep_time_minutes,json=maxPrepTimeMinutes,proto3,oneof" json:"max_prep_time_minutes,omitempty"`
+	MaxCookTimeMinutes  *int32   `protobuf:"varint,7,opt,name=max_cook_time_minutes,json=maxCookTimeMinutes,proto3,oneof" json:"max_cook_time_minutes,omitempty"`
+	MinServings         *int32   `protobuf:"varint,8,opt,name=min_servings,json=minServings,proto3,oneof" json:"min_servings,omitempty"`
+	MaxServings         *int32   `protobuf:"varint,9,opt,name=max_servings,json=maxServings,proto3,oneof" json:"max_servings,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RecipeFilter) Reset() {
+	*x = RecipeFilter{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecipeFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecipeFilter) ProtoMessage() {}
+
+func (x *RecipeFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecipeFilter.ProtoReflect.Descriptor instead.
+func (*RecipeFilter) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RecipeFilter) GetDiets() []string {
+	if x != nil {
+		return x.Diets
+	}
+	return nil
+}
+
+func (x *RecipeFilter) GetDifficulties() []string {
+	if x != nil {
+		return x.Difficulties
+	}
+	return nil
+}
+
+func (x *RecipeFilter) GetCuisines() []string {
+	if x != nil {
+		return x.Cuisines
+	}
+	return nil
+}
+
+func (x *RecipeFilter) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *RecipeFilter) GetMaxTotalTimeMinutes() int32 {
+	if x != nil && x.MaxTotalTimeMinutes != nil {
+		return *x.MaxTotalTimeMinutes
+	}
+	return 0
+}
+
+func (x *RecipeFilter) GetMaxPrepTimeMinutes() int32 {
+	if x != nil && x.MaxPrepTimeMinutes != nil {
+		return *x.MaxPrepTimeMinutes
+	}
+	return 0
+}
+
+func (x *RecipeFilter) GetMaxCookTimeMinutes() int32 {
+	if x != nil && x.MaxCookTimeMinutes != nil {
+		return *x.MaxCookTimeMinutes
+	}
+	return 0
+}
+
+func (x *RecipeFilter) GetMinServings() int32 {
+	if x != nil && x.MinServings != nil {
+		return *x.MinServings
+	}
+	return 0
+}
+
+func (x *RecipeFilter) GetMaxServings() int32 {
+	if x != nil && x.MaxServings != nil {
+		return *x.MaxServings
+	}
+	return 0
+}
+
+type GetRecipeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRecipeRequest) Reset() {
+	*x = GetRecipeRequest{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRecipeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecipeRequest) ProtoMessage() {}
+
+func (x *GetRecipeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecipeRequest.ProtoReflect.Descriptor instead.
+func (*GetRecipeRequest) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetRecipeRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Paged requests take a page_size of up to 200, 50 if unset, and the
+// next_page_token of the previous response to continue a listing. A token
+// only continues the listing it came from: reusing it with a different
+// query or sort fails with INVALID_ARGUMENT.
+type ListRecipesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *RecipeFilter          `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort          RecipeSort             `protobuf:"varint,2,opt,name=sort,proto3,enum=recipes.v1.RecipeSort" json:"sort,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecipesRequest) Reset() {
+	*x = ListRecipesRequest{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecipesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecipesRequest) ProtoMessage() {}
+
+func (x *ListRecipesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecipesRequest.ProtoReflect.Descriptor instead.
+func (*ListRecipesRequest) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListRecipesRequest) GetFilter() *RecipeFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListRecipesRequest) GetSort() RecipeSort {
+	if x != nil {
+		return x.Sort
+	}
+	return RecipeSort_RECIPE_SORT_UNSPECIFIED
+}
+
+func (x *ListRecipesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListRecipesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Paged responses carry the next_page_token, empty on the last page, and
+// total_size, the number of items in every page together.
+type ListRecipesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Recipes       []*Recipe              `protobuf:"bytes,1,rep,name=recipes,proto3" json:"recipes,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32                  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecipesResponse) Reset() {
+	*x = ListRecipesResponse{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecipesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecipesResponse) ProtoMessage() {}
+
+func (x *ListRecipesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecipesResponse.ProtoReflect.Descriptor instead.
+func (*ListRecipesResponse) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListRecipesResponse) GetRecipes() []*Recipe {
+	if x != nil {
+		return x.Recipes
+	}
+	return nil
+}
+
+func (x *ListRecipesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListRecipesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+type SearchRecipesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Terms must all appear unless joined by OR; "quoted phrases" must appear
+	// in order, a trailing * matches prefixes and a leading - excludes a term.
+	Query         string        `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Filter        *RecipeFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	Sort          RecipeSort    `protobuf:"varint,3,opt,name=sort,proto3,enum=recipes.v1.RecipeSort" json:"sort,omitempty"`
+	PageSize      int32         `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string        `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRecipesRequest) Reset() {
+	*x = SearchRecipesRequest{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRecipesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRecipesRequest) ProtoMessage() {}
+
+func (x *SearchRecipesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRecipesRequest.ProtoReflect.Descriptor instead.
+func (*SearchRecipesRequest) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchRecipesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRecipesRequest) GetFilter() *RecipeFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *SearchRecipesRequest) GetSort() RecipeSort {
+	if x != nil {
+		return x.Sort
+	}
+	return RecipeSort_RECIPE_SORT_UNSPECIFIED
+}
+
+func (x *SearchRecipesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchRecipesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SearchRecipesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32                  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRecipesResponse) Reset() {
+	*x = SearchRecipesResponse{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRecipesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRecipesResponse) ProtoMessage() {}
+
+func (x *SearchRecipesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRecipesResponse.ProtoReflect.Descriptor instead.
+func (*SearchRecipesResponse) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SearchRecipesResponse) GetResults() []*SearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchRecipesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *SearchRecipesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+// SearchResult is a recipe matching a search. Rank and snippet are only set
+// when the query has terms; the snippet marks them with <mark> tags.
+type SearchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Recipe        *Recipe                `protobuf:"bytes,1,opt,name=recipe,proto3" json:"recipe,omitempty"`
+	Rank          float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	Snippet       string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResult) ProtoMessage() {}
+
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchResult) GetRecipe() *Recipe {
+	if x != nil {
+		return x.Recipe
+	}
+	return nil
+}
+
+func (x *SearchResult) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+type MatchRecipesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Ingredient names, such as those DetectIngredients returns.
+	Ingredients   []string      `protobuf:"bytes,1,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	Filter        *RecipeFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	PageSize      int32         `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string        `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchRecipesRequest) Reset() {
+	*x = MatchRecipesRequest{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchRecipesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchRecipesRequest) ProtoMessage() {}
+
+func (x *MatchRecipesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchRecipesRequest.ProtoReflect.Descriptor instead.
+func (*MatchRecipesRequest) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MatchRecipesRequest) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *MatchRecipesRequest) GetFilter() *RecipeFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *MatchRecipesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *MatchRecipesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type MatchRecipesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Matches       []*RecipeMatch         `protobuf:"bytes,1,rep,name=matches,proto3" json:"matches,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	TotalSize     int32                  `protobuf:"varint,3,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchRecipesResponse) Reset() {
+	*x = MatchRecipesResponse{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchRecipesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchRecipesResponse) ProtoMessage() {}
+
+func (x *MatchRecipesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchRecipesResponse.ProtoReflect.Descriptor instead.
+func (*MatchRecipesResponse) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *MatchRecipesResponse) GetMatches() []*RecipeMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+func (x *MatchRecipesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *MatchRecipesResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+// RecipeMatch is a recipe with its score: a point for each ingredient among
+// its tags and each ingredient in its title.
+type RecipeMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Recipe        *Recipe                `protobuf:"bytes,1,opt,name=recipe,proto3" json:"recipe,omitempty"`
+	Score         int32                  `protobuf:"varint,2,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecipeMatch) Reset() {
+	*x = RecipeMatch{}
+	mi := &file_recipes_v1_recipes_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecipeMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecipeMatch) ProtoMessage() {}
+
+func (x *RecipeMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_recipes_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecipeMatch.ProtoReflect.Descriptor instead.
+func (*RecipeMatch) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_recipes_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RecipeMatch) GetRecipe() *Recipe {
+	if x != nil {
+		return x.Recipe
+	}
+	return nil
+}
+
+func (x *RecipeMatch) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+var File_recipes_v1_recipes_proto protoreflect.FileDescriptor
+
+const file_recipes_v1_recipes_proto_rawDesc = "" +
+	"\n" +
+	"\x18recipes/v1/recipes.proto\x12\n" +
+	"recipes.v1\x1a\x1cgoogle/protobuf/struct.proto\"\xef\x04\n" +
+	"\x06Recipe\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x18\n" +
+	"\acuisine\x18\x04 \x01(\tR\acuisine\x12\x1e\n" +
+	"\n" +
+	"difficulty\x18\x05 \x01(\tR\n" +
+	"difficulty\x12\x1b\n" +
+	"\tdiet_type\x18\x06 \x01(\tR\bdietType\x12/\n" +
+	"\x11prep_time_minutes\x18\a \x01(\x05H\x00R\x0fprepTimeMinutes\x88\x01\x01\x12/\n" +
+	"\x11cook_time_minutes\x18\b \x01(\x05H\x01R\x0fcookTimeMinutes\x88\x01\x01\x121\n" +
+	"\x12total_time_minutes\x18\t \x01(\x05H\x02R\x10totalTimeMinutes\x88\x01\x01\x12\x1f\n" +
+	"\bservings\x18\n" +
+	" \x01(\x05H\x03R\bservings\x88\x01\x01\x128\n" +
+	"\vingredients\x18\v \x03(\v2\x16.recipes.v1.IngredientR\vingredients\x12\x14\n" +
+	"\x05steps\x18\f \x03(\tR\x05steps\x125\n" +
+	"\tnutrition\x18\r \x01(\v2\x17.google.protobuf.StructR\tnutrition\x12\x12\n" +
+	"\x04tags\x18\x0e \x03(\tR\x04tags\x12%\n" +
+	"\x0eaverage_rating\x18\x0f \x01(\x01R\raverageRatingB\x14\n" +
+	"\x12_prep_time_minutesB\x14\n" +
+	"\x12_cook_time_minutesB\x15\n" +
+	"\x13_total_time_minutesB\v\n" +
+	"\t_servings\"b\n" +
+	"\n" +
+	"Ingredient\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\bquantity\x18\x02 \x01(\x01H\x00R\bquantity\x88\x01\x01\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unitB\v\n" +
+	"\t_quantity\"\xe3\x03\n" +
+	"\fRecipeFilter\x12\x14\n" +
+	"\x05diets\x18\x01 \x03(\tR\x05diets\x12\"\n" +
+	"\fdifficulties\x18\x02 \x03(\tR\fdifficulties\x12\x1a\n" +
+	"\bcuisines\x18\x03 \x03(\tR\bcuisines\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x128\n" +
+	"\x16max_total_time_minutes\x18\x05 \x01(\x05H\x00R\x13maxTotalTimeMinutes\x88\x01\x01\x126\n" +
+	"\x15max_prep_time_minutes\x18\x06 \x01(\x05H\x01R\x12maxPrepTimeMinutes\x88\x01\x01\x126\n" +
+	"\x15max_cook_time_minutes\x18\a \x01(\x05H\x02R\x12maxCookTimeMinutes\x88\x01\x01\x12&\n" +
+	"\fmin_servings\x18\b \x01(\x05H\x03R\vminServings\x88\x01\x01\x12&\n" +
+	"\fmax_servings\x18\t \x01(\x05H\x04R\vmaxServings\x88\x01\x01B\x19\n" +
+	"\x17_max_total_time_minutesB\x18\n" +
+	"\x16_max_prep_time_minutesB\x18\n" +
+	"\x16_max_cook_time_minutesB\x0f\n" +
+	"\r_min_servingsB\x0f\n" +
+	"\r_max_servings\"\"\n" +
+	"\x10GetRecipeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\xae\x01\n" +
+	"\x12ListRecipesRequest\x120\n" +
+	"\x06filter\x18\x01 \x01(\v2\x18.recipes.v1.RecipeFilterR\x06filter\x12*\n" +
+	"\x04sort\x18\x02 \x01(\x0e2\x16.recipes.v1.RecipeSortR\x04sort\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\x8a\x01\n" +
+	"\x13ListRecipesResponse\x12,\n" +
+	"\arecipes\x18\x01 \x03(\v2\x12.recipes.v1.RecipeR\arecipes\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"\xc6\x01\n" +
+	"\x14SearchRecipesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x120\n" +
+	"\x06filter\x18\x02 \x01(\v2\x18.recipes.v1.RecipeFilterR\x06filter\x12*\n" +
+	"\x04sort\x18\x03 \x01(\x0e2\x16.recipes.v1.RecipeSortR\x04sort\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"\x92\x01\n" +
+	"\x15SearchRecipesResponse\x122\n" +
+	"\aresults\x18\x01 \x03(\v2\x18.recipes.v1.SearchResultR\aresults\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"h\n" +
+	"\fSearchResult\x12*\n" +
+	"\x06recipe\x18\x01 \x01(\v2\x12.recipes.v1.RecipeR\x06recipe\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\"\xa5\x01\n" +
+	"\x13MatchRecipesRequest\x12 \n" +
+	"\vingredients\x18\x01 \x03(\tR\vingredients\x120\n" +
+	"\x06filter\x18\x02 \x01(\v2\x18.recipes.v1.RecipeFilterR\x06filter\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\x90\x01\n" +
+	"\x14MatchRecipesResponse\x121\n" +
+	"\amatches\x18\x01 \x03(\v2\x17.recipes.v1.RecipeMatchR\amatches\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x03 \x01(\x05R\ttotalSize\"O\n" +
+	"\vRecipeMatch\x12*\n" +
+	"\x06recipe\x18\x01 \x01(\v2\x12.recipes.v1.RecipeR\x06recipe\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x05R\x05score*\x8c\x01\n" +
+	"\n" +
+	"RecipeSort\x12\x1b\n" +
+	"\x17RECIPE_SORT_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12RECIPE_SORT_RATING\x10\x01\x12\x16\n" +
+	"\x12RECIPE_SORT_NEWEST\x10\x02\x12\x18\n" +
+	"\x14RECIPE_SORT_QUICKEST\x10\x03\x12\x17\n" +
+	"\x13RECIPE_SORT_POPULAR\x10\x042\xc7\x02\n" +
+	"\rRecipeService\x12=\n" +
+	"\tGetRecipe\x12\x1c.recipes.v1.GetRecipeRequest\x1a\x12.recipes.v1.Recipe\x12N\n" +
+	"\vListRecipes\x12\x1e.recipes.v1.ListRecipesRequest\x1a\x1f.recipes.v1.ListRecipesResponse\x12T\n" +
+	"\rSearchRecipes\x12 .recipes.v1.SearchRecipesRequest\x1a!.recipes.v1.SearchRecipesResponse\x12Q\n" +
+	"\fMatchRecipes\x12\x1f.recipes.v1.MatchRecipesRequest\x1a .recipes.v1.MatchRecipesResponseBNZLgithub.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1;recipesv1b\x06proto3"
+
+var (
+	file_recipes_v1_recipes_proto_rawDescOnce sync.Once
+	file_recipes_v1_recipes_proto_rawDescData []byte
+)
+
+func file_recipes_v1_recipes_proto_rawDescGZIP() []byte {
+	file_recipes_v1_recipes_proto_rawDescOnce.Do(func() {
+		file_recipes_v1_recipes_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_recipes_v1_recipes_proto_rawDesc), len(file_recipes_v1_recipes_proto_rawDesc)))
+	})
+	return file_recipes_v1_recipes_proto_rawDescData
+}
+
+var file_recipes_v1_recipes_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_recipes_v1_recipes_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_recipes_v1_recipes_proto_goTypes = []any{
+	(RecipeSort)(0),               // 0: recipes.v1.RecipeSort
+	(*Recipe)(nil),                // 1: recipes.v1.Recipe
+	(*Ingredient)(nil),            // 2: recipes.v1.Ingredient
+	(*RecipeFilter)(nil),          // 3: recipes.v1.RecipeFilter
+	(*GetRecipeRequest)(nil),      // 4: recipes.v1.GetRecipeRequest
+	(*ListRecipesRequest)(nil),    // 5: recipes.v1.ListRecipesRequest
+	(*ListRecipesResponse)(nil),   // 6: recipes.v1.ListRecipesResponse
+	(*SearchRecipesRequest)(nil),  // 7: recipes.v1.SearchRecipesRequest
+	(*SearchRecipesResponse)(nil), // 8: recipes.v1.SearchRecipesResponse
+	(*SearchResult)(nil),          // 9: recipes.v1.SearchResult
+	(*MatchRecipesRequest)(nil),   // 10: recipes.v1.MatchRecipesRequest
+	(*MatchRecipesResponse)(nil),  // 11: recipes.v1.MatchRecipesResponse
+	(*RecipeMatch)(nil),           // 12: recipes.v1.RecipeMatch
+	(*structpb.Struct)(nil),       // 13: google.protobuf.Struct
+}
+var file_recipes_v1_recipes_proto_depIdxs = []int32{
+	2,  // 0: recipes.v1.Recipe.ingredients:type_name -> recipes.v1.Ingredient
+	13, // 1: recipes.v1.Recipe.nutrition:type_name -> google.protobuf.Struct
+	3,  // 2: recipes.v1.ListRecipesRequest.filter:type_name -> recipes.v1.RecipeFilter
+	0,  // 3: recipes.v1.ListRecipesRequest.sort:type_name -> recipes.v1.RecipeSort
+	1,  // 4: recipes.v1.ListRecipesResponse.recipes:type_name -> recipes.v1.Recipe
+	3,  // 5: recipes.v1.SearchRecipesRequest.filter:type_name -> recipes.v1.RecipeFilter
+	0,  // 6: recipes.v1.SearchRecipesRequest.sort:type_name -> recipes.v1.RecipeSort
+	9,  // 7: recipes.v1.SearchRecipesResponse.results:type_name -> recipes.v1.SearchResult
+	1,  // 8: recipes.v1.SearchResult.recipe:type_name -> recipes.v1.Recipe
+	3,  // 9: recipes.v1.MatchRecipesRequest.filter:type_name -> recipes.v1.RecipeFilter
+	12, // 10: recipes.v1.MatchRecipesResponse.matches:type_name -> recipes.v1.RecipeMatch
+	1,  // 11: recipes.v1.RecipeMatch.recipe:type_name -> recipes.v1.Recipe
+	4,  // 12: recipes.v1.RecipeService.GetRecipe:input_type -> recipes.v1.GetRecipeRequest
+	5,  // 13: recipes.v1.RecipeService.ListRecipes:input_type -> recipes.v1.ListRecipesRequest
+	7,  // 14: recipes.v1.RecipeService.SearchRecipes:input_type -> recipes.v1.SearchRecipesRequest
+	10, // 15: recipes.v1.RecipeService.MatchRecipes:input_type -> recipes.v1.MatchRecipesRequest
+	1,  // 16: recipes.v1.RecipeService.GetRecipe:output_type -> recipes.v1.Recipe
+	6,  // 17: recipes.v1.RecipeService.ListRecipes:output_type -> recipes.v1.ListRecipesResponse
+	8,  // 18: recipes.v1.RecipeService.SearchRecipes:output_type -> recipes.v1.SearchRecipesResponse
+	11, // 19: recipes.v1.RecipeService.MatchRecipes:output_type -> recipes.v1.MatchRecipesResponse
+	16, // [16:20] is the sub-list for method output_type
+	12, // [12:16] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_recipes_v1_recipes_proto_init() }
+func file_recipes_v1_recipes_proto_init() {
+	if File_recipes_v1_recipes_proto != nil {
+		return
+	}
+	file_recipes_v1_recipes_proto_msgTypes[0].OneofWrappers = []any{}
+	file_recipes_v1_recipes_proto_msgTypes[1].OneofWrappers = []any{}
+	file_recipes_v1_recipes_proto_msgTypes[2].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_recipes_v1_recipes_proto_rawDesc), len(file_recipes_v1_recipes_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_recipes_v1_recipes_proto_goTypes,
+		DependencyIndexes: file_recipes_v1_recipes_proto_depIdxs,
+		EnumInfos:         file_recipes_v1_recipes_proto_enumTypes,
+		MessageInfos:      file_recipes_v1_recipes_proto_msgTypes,
+	}.Build()
+	File_recipes_v1_recipes_proto = out.File
+	file_recipes_v1_recipes_proto_goTypes = nil
+	file_recipes_v1_recipes_proto_depIdxs = nil
+}
@@ -0,0 +1,341 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: recipes/v1/vision.proto
+
+package recipesv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type DetectIngredientsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Chunk:
+	//
+	//	*DetectIngredientsRequest_Info
+	//	*DetectIngredientsRequest_Data
+	Chunk         isDetectIngredientsRequest_Chunk `protobuf_oneof:"chunk"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectIngredientsRequest) Reset() {
+	*x = DetectIngredientsRequest{}
+	mi := &file_recipes_v1_vision_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectIngredientsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectIngredientsRequest) ProtoMessage() {}
+
+func (x *DetectIngredientsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_vision_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectIngredientsRequest.ProtoReflect.Descriptor instead.
+func (*DetectIngredientsRequest) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_vision_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DetectIngredientsRequest) GetChunk() isDetectIngredientsRequest_Chunk {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *DetectIngredientsRequest) GetInfo() *ImageInfo {
+	if x != nil {
+		if x, ok := x.Chunk.(*DetectIngredientsRequest_Info); ok {
+			return x.Info
+		}
+	}
+	return nil
+}
+
+func (x *DetectIngredientsRequest) GetData() []byte {
+	if x != nil {
+		if x, ok := x.Chunk.(*DetectIngredientsRequest_Data); ok {
+			return x.Data
+		}
+	}
+	return nil
+}
+
+type isDetectIngredientsRequest_Chunk interface {
+	isDetectIngredientsRequest_Chunk()
+}
+
+type DetectIngredientsRequest_Info struct {
+	Info *ImageInfo `protobuf:"bytes,1,opt,name=info,proto3,oneof"`
+}
+
+type DetectIngredientsRequest_Data struct {
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3,oneof"`
+}
+
+func (*DetectIngredientsRequest_Info) isDetectIngredientsRequest_Chunk() {}
+
+func (*DetectIngredientsRequest_Data) isDetectIngredientsRequest_Chunk() {}
+
+// ImageInfo describes the image being sent.
+type ImageInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional; passed on to the AI service.
+	Filename string `protobuf:"bytes,1,opt,name=filename,proto3" json:"filename,omitempty"`
+	// One of image/jpeg, image/png, image/gif or image/webp.
+	ContentType   string `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageInfo) Reset() {
+	*x = ImageInfo{}
+	mi := &file_recipes_v1_vision_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageInfo) ProtoMessage() {}
+
+func (x *ImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_vision_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_vision_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ImageInfo) GetFilename() string {
+	if x != nil {
+		return x.Filename
+	}
+	return ""
+}
+
+func (x *ImageInfo) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+type DetectIngredientsResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Ingredients []string               `protobuf:"bytes,1,rep,name=ingredients,proto3" json:"ingredients,omitempty"`
+	// From 0 to 1.
+	Confidence float64 `protobuf:"fixed64,2,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// The model or service that analysed the image.
+	Provider string `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	// The model's description of the image.
+	Caption  string `protobuf:"bytes,4,opt,name=caption,proto3" json:"caption,omitempty"`
+	Cuisine  string `protobuf:"bytes,5,opt,name=cuisine,proto3" json:"cuisine,omitempty"`
+	DishType string `protobuf:"bytes,6,opt,name=dish_type,json=dishType,proto3" json:"dish_type,omitempty"`
+	// Further results the provider reports, such as per-ingredient scores.
+	Details       *structpb.Struct `protobuf:"bytes,7,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectIngredientsResponse) Reset() {
+	*x = DetectIngredientsResponse{}
+	mi := &file_recipes_v1_vision_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectIngredientsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectIngredientsResponse) ProtoMessage() {}
+
+func (x *DetectIngredientsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_recipes_v1_vision_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectIngredientsResponse.ProtoReflect.Descriptor instead.
+func (*DetectIngredientsResponse) Descriptor() ([]byte, []int) {
+	return file_recipes_v1_vision_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DetectIngredientsResponse) GetIngredients() []string {
+	if x != nil {
+		return x.Ingredients
+	}
+	return nil
+}
+
+func (x *DetectIngredientsResponse) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *DetectIngredientsResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *DetectIngredientsResponse) GetCaption() string {
+	if x != nil {
+		return x.Caption
+	}
+	return ""
+}
+
+func (x *DetectIngredientsResponse) GetCuisine() string {
+	if x != nil {
+		return x.Cuisine
+	}
+	return ""
+}
+
+func (x *DetectIngredientsResponse) GetDishType() string {
+	if x != nil {
+		return x.DishType
+	}
+	return ""
+}
+
+func (x *DetectIngredientsResponse) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+var File_recipes_v1_vision_proto protoreflect.FileDescriptor
+
+const file_recipes_v1_vision_proto_rawDesc = "" +
+	"\n" +
+	"\x17recipes/v1/vision.proto\x12\n" +
+	"recipes.v1\x1a\x1cgoogle/protobuf/struct.proto\"f\n" +
+	"\x18DetectIngredientsRequest\x12+\n" +
+	"\x04info\x18\x01 \x01(\v2\x15.recipes.v1.ImageInfoH\x00R\x04info\x12\x14\n" +
+	"\x04data\x18\x02 \x01(\fH\x00R\x04dataB\a\n" +
+	"\x05chunk\"J\n" +
+	"\tImageInfo\x12\x1a\n" +
+	"\bfilename\x18\x01 \x01(\tR\bfilename\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\"\xfd\x01\n" +
+	"\x19DetectIngredientsResponse\x12 \n" +
+	"\vingredients\x18\x01 \x03(\tR\vingredients\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x02 \x01(\x01R\n" +
+	"confidence\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12\x18\n" +
+	"\acaption\x18\x04 \x01(\tR\acaption\x12\x18\n" +
+	"\acuisine\x18\x05 \x01(\tR\acuisine\x12\x1b\n" +
+	"\tdish_type\x18\x06 \x01(\tR\bdishType\x121\n" +
+	"\adetails\x18\a \x01(\v2\x17.google.protobuf.StructR\adetails2s\n" +
+	"\rVisionService\x12b\n" +
+	"\x11DetectIngredients\x12$.recipes.v1.DetectIngredientsRequest\x1a%.recipes.v1.DetectIngredientsResponse(\x01BNZLgithub.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1;recipesv1b\x06proto3"
+
+var (
+	file_recipes_v1_vision_proto_rawDescOnce sync.Once
+	file_recipes_v1_vision_proto_rawDescData []byte
+)
+
+func file_recipes_v1_vision_proto_rawDescGZIP() []byte {
+	file_recipes_v1_vision_proto_rawDescOnce.Do(func() {
+		file_recipes_v1_vision_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_recipes_v1_vision_proto_rawDesc), len(file_recipes_v1_vision_proto_rawDesc)))
+	})
+	return file_recipes_v1_vision_proto_rawDescData
+}
+
+var file_recipes_v1_vision_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_recipes_v1_vision_proto_goTypes = []any{
+	(*DetectIngredientsRequest)(nil),  // 0: recipes.v1.DetectIngredientsRequest
+	(*ImageInfo)(nil),                 // 1: recipes.v1.ImageInfo
+	(*DetectIngredientsResponse)(nil), // 2: recipes.v1.DetectIngredientsResponse
+	(*structpb.Struct)(nil),           // 3: google.protobuf.Struct
+}
+var file_recipes_v1_vision_proto_depIdxs = []int32{
+	1, // 0: recipes.v1.DetectIngredientsRequest.info:type_name -> recipes.v1.ImageInfo
+	3, // 1: recipes.v1.DetectIngredientsResponse.details:type_name -> google.protobuf.Struct
+	0, // 2: recipes.v1.VisionService.DetectIngredients:input_type -> recipes.v1.DetectIngredientsRequest
+	2, // 3: recipes.v1.VisionService.DetectIngredients:output_type -> recipes.v1.DetectIngredientsResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_recipes_v1_vision_proto_init() }
+func file_recipes_v1_vision_proto_init() {
+	if File_recipes_v1_vision_proto != nil {
+		return
+	}
+	file_recipes_v1_vision_proto_msgTypes[0].OneofWrappers = []any{
+		(*DetectIngredientsRequest_Info)(nil),
+		(*DetectIngredientsRequest_Data)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_recipes_v1_vision_proto_rawDesc), len(file_recipes_v1_vision_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_recipes_v1_vision_proto_goTypes,
+		DependencyIndexes: file_recipes_v1_vision_proto_depIdxs,
+		MessageInfos:      file_recipes_v1_vision_proto_msgTypes,
+	}.Build()
+	File_recipes_v1_vision_proto = out.File
+	file_recipes_v1_vision_proto_goTypes = nil
+	file_recipes_v1_vision_proto_depIdxs = nil
+}
@@ -0,0 +1,130 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: recipes/v1/vision.proto
+
+package recipesv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	VisionService_DetectIngredients_FullMethodName = "/recipes.v1.VisionService/DetectIngredients"
+)
+
+// VisionServiceClient is the client API for VisionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// VisionService detects ingredients in photos, as POST /detect-ingredients
+// does over REST. It fails with UNAVAILABLE when the server has no AI
+// service configured.
+type VisionServiceClient interface {
+	// DetectIngredients takes an image as a stream of chunks: the first
+	// carries its info, the rest its bytes, in order. The image may be up to
+	// MAX_IMAGE_SIZE_MB; larger ones fail with RESOURCE_EXHAUSTED. Fails with
+	// UNAVAILABLE if the AI service could not analyse the image.
+	DetectIngredients(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DetectIngredientsRequest, DetectIngredientsResponse], error)
+}
+
+type visionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVisionServiceClient(cc grpc.ClientConnInterface) VisionServiceClient {
+	return &visionServiceClient{cc}
+}
+
+func (c *visionServiceClient) DetectIngredients(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[DetectIngredientsRequest, DetectIngredientsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &VisionService_ServiceDesc.Streams[0], VisionService_DetectIngredients_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DetectIngredientsRequest, DetectIngredientsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VisionService_DetectIngredientsClient = grpc.ClientStreamingClient[DetectIngredientsRequest, DetectIngredientsResponse]
+
+// VisionServiceServer is the server API for VisionService service.
+// All implementations must embed UnimplementedVisionServiceServer
+// for forward compatibility.
+//
+// VisionService detects ingredients in photos, as POST /detect-ingredients
+// does over REST. It fails with UNAVAILABLE when the server has no AI
+// service configured.
+type VisionServiceServer interface {
+	// DetectIngredients takes an image as a stream of chunks: the first
+	// carries its info, the rest its bytes, in order. The image may be up to
+	// MAX_IMAGE_SIZE_MB; larger ones fail with RESOURCE_EXHAUSTED. Fails with
+	// UNAVAILABLE if the AI service could not analyse the image.
+	DetectIngredients(grpc.ClientStreamingServer[DetectIngredientsRequest, DetectIngredientsResponse]) error
+	mustEmbedUnimplementedVisionServiceServer()
+}
+
+// UnimplementedVisionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVisionServiceServer struct{}
+
+func (UnimplementedVisionServiceServer) DetectIngredients(grpc.ClientStreamingServer[DetectIngredientsRequest, DetectIngredientsResponse]) error {
+	return status.Error(codes.Unimplemented, "method DetectIngredients not implemented")
+}
+func (UnimplementedVisionServiceServer) mustEmbedUnimplementedVisionServiceServer() {}
+func (UnimplementedVisionServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeVisionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VisionServiceServer will
+// result in compilation errors.
+type UnsafeVisionServiceServer interface {
+	mustEmbedUnimplementedVisionServiceServer()
+}
+
+func RegisterVisionServiceServer(s grpc.ServiceRegistrar, srv VisionServiceServer) {
+	// If the following call panics, it indicates UnimplementedVisionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VisionService_ServiceDesc, srv)
+}
+
+func _VisionService_DetectIngredients_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VisionServiceServer).DetectIngredients(&grpc.GenericServerStream[DetectIngredientsRequest, DetectIngredientsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type VisionService_DetectIngredientsServer = grpc.ClientStreamingServer[DetectIngredientsRequest, DetectIngredientsResponse]
+
+// VisionService_ServiceDesc is the grpc.ServiceDesc for VisionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VisionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "recipes.v1.VisionService",
+	HandlerType: (*VisionServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DetectIngredients",
+			Handler:       _VisionService_DetectIngredients_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "recipes/v1/vision.proto",
+}
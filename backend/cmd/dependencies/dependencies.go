@@ -3,46 +3,127 @@
 package app
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
-	"strings"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	_ "github.com/lib/pq"
 
+	"github.com/varnit-ta/smart-recipe-generator/backend/api"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/buildinfo"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/config"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/graph"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/grpcserver"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/handlers"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/health"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/lockout"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/mail"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/oidc"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/openapi"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/ratelimit"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/tracing"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/vision"
+	"github.com/varnit-ta/smart-recipe-generator/backend/migrations"
 )
 
 // App encapsulates the application dependencies and configuration.
 type App struct {
-	Config config.Config
-	DB     *sql.DB
-	Router *chi.Mux
+	Config  config.Config
+	DB      *sql.DB
+	Router  *chi.Mux
+	Service *service.Service
+	Keys    *auth.KeyRing
+	Limiter *ratelimit.Limiter
+	Metrics *metrics.Metrics
+	Tracer  *tracing.Tracer
+	Health  *health.Checker
+	// GRPC serves the gRPC API, and Gateway serves it as JSON; each is nil
+	// when its port is off.
+	GRPC    *grpcserver.Server
+	Gateway *grpcserver.Gateway
+
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
 }
 
 // New creates and initializes a new App instance with all dependencies.
 // It performs database connection, service setup, and router configuration.
 func New(cfg config.Config) (*App, error) {
 	app := &App{
-		Config: cfg,
+		Config:  cfg,
+		Metrics: metrics.New(),
 	}
 
+	if err := app.initTracing(); err != nil {
+		return nil, err
+	}
 	if err := app.initDatabase(); err != nil {
 		return nil, err
 	}
 
-	app.initRouter()
+	app.initService()
+	if err := app.initKeys(); err != nil {
+		app.DB.Close()
+		return nil, err
+	}
+	if err := app.initOIDC(); err != nil {
+		app.DB.Close()
+		return nil, err
+	}
+	if err := app.Service.LoadIngredientLexicon(context.Background()); err != nil {
+		slog.Warn("ingredient lexicon not loaded, using built-in names only", slog.Any("error", err))
+	}
+
+	visionService := app.setupVisionService()
+	if err := app.initRouter(visionService); err != nil {
+		app.DB.Close()
+		return nil, err
+	}
+	if err := app.initGRPC(visionService); err != nil {
+		app.DB.Close()
+		return nil, err
+	}
+	app.startWorkers()
 
 	return app, nil
 }
 
+// initTracing sets up the tracer for OTEL_TRACES_EXPORTER: spans go to an
+// OpenTelemetry collector over OTLP/HTTP, to stdout, or nowhere.
+func (app *App) initTracing() error {
+	cfg := app.Config
+	var exporter tracing.Exporter
+	switch cfg.TracesExporter {
+	case config.TraceExporterNone:
+		return nil
+	case config.TraceExporterOTLP:
+		exporter = &tracing.OTLPExporter{Endpoint: cfg.OTLPEndpoint, Headers: cfg.OTLPHeaders, ServiceName: cfg.ServiceName}
+		slog.Info("exporting traces over OTLP", slog.String("endpoint", cfg.OTLPEndpoint))
+	case config.TraceExporterConsole:
+		exporter = &tracing.StdoutExporter{W: os.Stdout}
+		slog.Info("writing traces to stdout")
+	default:
+		return fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q", cfg.TracesExporter)
+	}
+	app.Tracer = tracing.NewTracer(tracing.Config{Exporter: exporter, SampleRatio: cfg.TraceSampleRatio})
+	return nil
+}
+
 // initDatabase establishes database connection with retry logic and configures connection pooling.
 func (app *App) initDatabase() error {
 	db, err := sql.Open("postgres", app.Config.DatabaseURL)
@@ -58,6 +139,7 @@ func (app *App) initDatabase() error {
 	}
 
 	app.DB = db
+	app.Metrics.ObserveDB(db)
 	return nil
 }
 
@@ -77,6 +159,15 @@ func (app *App) configureConnectionPool(db *sql.DB) {
 	}
 }
 
+// dbConn returns the connection queries run on, which records a span per
+// query when tracing is on.
+func (app *App) dbConn() tracing.Conn {
+	if app.Tracer == nil {
+		return app.DB
+	}
+	return tracing.DB{Conn: app.DB}
+}
+
 // connectWithRetry attempts to establish a database connection with exponential backoff.
 func (app *App) connectWithRetry(db *sql.DB) error {
 	backoff := app.Config.DBRetryBackoff
@@ -92,107 +183,719 @@ func (app *App) connectWithRetry(db *sql.DB) error {
 	for i := 0; i < attempts; i++ {
 		pingErr = db.Ping()
 		if pingErr == nil {
-			log.Printf("connected to db")
+			slog.Info("connected to db")
 			return nil
 		}
 		wait := backoff << i
 		if wait > 5*time.Second {
 			wait = 5 * time.Second
 		}
-		log.Printf("db ping failed (attempt %d/%d): %v; retrying in %s", i+1, attempts, pingErr, wait)
+		slog.Warn("db ping failed", slog.Int("attempt", i+1), slog.Int("attempts", attempts), slog.Any("error", pingErr), slog.Duration("retry_in", wait))
 		time.Sleep(wait)
 	}
 
 	return pingErr
 }
 
-// initRouter sets up the HTTP router with all middleware and routes.
-func (app *App) initRouter() {
-	visionService := app.setupVisionService()
-	svc := service.NewService(app.DB)
-	h := handlers.New(svc, visionService, app.Config.MaxImageSizeMB)
-	authH := &handlers.AuthHandler{
-		Service:   svc,
-		JWTSecret: app.Config.JWTSecret,
-		JWTExpiry: app.Config.JWTExpiryHours,
+// initService creates the business logic service with its token settings.
+// The signing key ring is attached by initKeys.
+func (app *App) initService() {
+	svc := service.NewService(app.dbConn())
+	svc.Tokens = service.TokenSettings{
+		AccessTTL:  app.Config.AccessTokenTTL,
+		RefreshTTL: app.Config.RefreshTokenTTL,
+	}
+	svc.Mail = service.MailSettings{
+		Mailer:    app.setupMailer(),
+		AppURL:    app.Config.AppURL,
+		VerifyTTL: app.Config.EmailVerifyTTL,
+		ResetTTL:  app.Config.PasswordResetTTL,
+	}
+	svc.Lockout = app.setupLockout()
+	svc.Metrics = app.Metrics
+	if app.Config.ViewerSecret != "" {
+		svc.ViewerKey = []byte(app.Config.ViewerSecret)
 	}
+	app.Service = svc
+	app.Limiter = app.setupRateLimiter()
+}
 
-	r := chi.NewRouter()
+// setupLockout builds the failed-login guard. Attempts are kept in the
+// login_attempts table so every instance shares them, or in memory with
+// LOGIN_ATTEMPT_STORE=memory.
+func (app *App) setupLockout() *lockout.Guard {
+	cfg := app.Config
+	var store lockout.Store
+	if cfg.LoginAttemptStore == config.AttemptStoreMemory {
+		store = lockout.NewMemoryStore()
+	} else {
+		store = lockout.NewPostgresStore(app.dbConn())
+	}
+	return &lockout.Guard{
+		Store: store,
+		Account: lockout.Policy{
+			MaxFailures: cfg.LoginMaxFailures,
+			BaseDelay:   cfg.LoginLockoutBase,
+			MaxDelay:    cfg.LoginLockoutMax,
+			Window:      cfg.LoginFailureWindow,
+		},
+		Client: lockout.Policy{
+			MaxFailures: cfg.LoginIPMaxFailures,
+			BaseDelay:   cfg.LoginLockoutBase,
+			MaxDelay:    cfg.LoginLockoutMax,
+			Window:      cfg.LoginFailureWindow,
+		},
+	}
+}
 
-	r.Use(app.corsMiddleware())
-	r.Use(middleware.Logging)
+// setupRateLimiter builds the request rate limiter from the RATE_LIMITS
+// policies. Buckets are kept in memory, or in the rate_limit_buckets table
+// with RATE_LIMIT_STORE=db so that every instance shares them.
+func (app *App) setupRateLimiter() *ratelimit.Limiter {
+	cfg := app.Config
+	var store ratelimit.Store
+	if cfg.RateLimitStore == config.RateLimitStoreDB {
+		store = ratelimit.NewPostgresStore(app.dbConn())
+	} else {
+		store = ratelimit.NewMemoryStore()
+	}
+	policies := make(map[string]ratelimit.Policy, len(cfg.RateLimits))
+	for name, l := range cfg.RateLimits {
+		policies[name] = ratelimit.Policy{Limit: l.Limit, Period: l.Period}
+	}
+	return &ratelimit.Limiter{Store: store, Policies: policies}
+}
 
-	app.setupRoutes(r, h, authH)
+// setupMailer picks the mailer for account email: SMTP when SMTP_ADDR is
+// set, .eml files in MAIL_DIR, or the log as a fallback, which config
+// validation only allows in development.
+func (app *App) setupMailer() mail.Mailer {
+	switch {
+	case app.Config.SMTPAddr != "":
+		slog.Info("sending mail through SMTP server", slog.String("addr", app.Config.SMTPAddr))
+		return &mail.SMTPMailer{
+			Addr:     app.Config.SMTPAddr,
+			Username: app.Config.SMTPUsername,
+			Password: app.Config.SMTPPassword,
+			From:     app.Config.MailFrom,
+		}
+	case app.Config.MailDir != "":
+		slog.Info("writing mail to directory", slog.String("dir", app.Config.MailDir))
+		return &mail.FileMailer{Dir: app.Config.MailDir, From: app.Config.MailFrom}
+	}
+	slog.Warn("no mail transport configured, emails are written to the log; set SMTP_ADDR or MAIL_DIR to deliver verification and reset emails")
+	return &mail.LogMailer{From: app.Config.MailFrom}
+}
 
+// initKeys loads the JWT signing key ring from the configured source:
+//   - db: keys in the signing_keys table, generating the first one if needed
+//   - file: PEM files in JWT_KEYS_DIR
+//   - hmac: the legacy JWT_SECRET shared secret
+//
+// The default JWT_SECRET is refused outside the development environment.
+func (app *App) initKeys() error {
+	ctx := context.Background()
+	cfg := app.Config
+
+	var (
+		keys *auth.KeyRing
+		err  error
+	)
+	switch cfg.JWTKeySource {
+	case config.KeySourceHMAC:
+		if cfg.JWTSecret == config.DefaultJWTSecret && !cfg.IsDev() {
+			return fmt.Errorf("JWT_SECRET must be set when APP_ENV is %q", cfg.Env)
+		}
+		keys = auth.NewHMACKeyRing(cfg.JWTSecret)
+	case config.KeySourceFile:
+		if cfg.JWTKeysDir == "" {
+			return fmt.Errorf("JWT_KEYS_DIR must be set when JWT_KEY_SOURCE is %q", cfg.JWTKeySource)
+		}
+		keys, err = auth.Load(ctx, app.keySource())
+	case config.KeySourceDB:
+		if _, err := app.Service.EnsureSigningKey(ctx, cfg.JWTSigningAlg, cfg.JWTKeyRotation); err != nil {
+			return err
+		}
+		keys, err = auth.Load(ctx, app.keySource())
+	default:
+		return fmt.Errorf("unknown JWT_KEY_SOURCE %q", cfg.JWTKeySource)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("jwt signing keys loaded", slog.String("source", cfg.JWTKeySource), slog.String("active_key", keys.ActiveKeyID()))
+	app.Keys = keys
+	app.Service.Tokens.Keys = keys
+	return nil
+}
+
+// initOIDC registers the OpenID Connect providers named in OIDC_PROVIDERS.
+// Each needs an issuer and client ID; discovery is fetched on first use, so
+// a provider that is down does not stop the server from starting.
+func (app *App) initOIDC() error {
+	var providers []*oidc.Provider
+	for _, p := range app.Config.OIDCProviders {
+		if p.Issuer == "" || p.ClientID == "" {
+			return fmt.Errorf("OIDC provider %q needs an issuer and client ID", p.Name)
+		}
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			DisplayName:  p.DisplayName,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}, nil))
+		slog.Info("oidc provider configured", slog.String("provider", p.Name), slog.String("issuer", p.Issuer))
+	}
+	app.Service.OIDC = service.OIDCSettings{
+		Providers: providers,
+		StateTTL:  app.Config.OIDCStateTTL,
+	}
+	return nil
+}
+
+// keySource returns the configured source of rotating signing keys, or nil
+// for the static HMAC secret.
+func (app *App) keySource() auth.KeySource {
+	switch app.Config.JWTKeySource {
+	case config.KeySourceFile:
+		return auth.FileKeySource{Dir: app.Config.JWTKeysDir, ActiveID: app.Config.JWTActiveKeyID}
+	case config.KeySourceDB:
+		return auth.KeySourceFunc(app.Service.LoadSigningKeys)
+	}
+	return nil
+}
+
+// initRouter sets up the HTTP router with all middleware and routes. Routes
+// disagreeing with the OpenAPI document are caught by the tests; one that
+// slips through only leaves the docs stale, so it is logged rather than
+// keeping the server from starting.
+func (app *App) initRouter(visionService vision.VisionService) error {
+	r, doc, err := app.newRouter(visionService)
+	if err != nil {
+		return err
+	}
+	if err := doc.CheckRoutes(r); err != nil {
+		slog.Warn("routes and OpenAPI document disagree", slog.Any("error", err))
+	}
 	app.Router = r
+	return nil
+}
+
+// newRouter returns the HTTP router with all middleware and routes, and the
+// OpenAPI document it validates requests against.
+func (app *App) newRouter(visionService vision.VisionService) (*chi.Mux, *openapi.Document, error) {
+	doc, err := openapi.Load(api.OpenAPI)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	svc := app.Service
+	h := handlers.New(svc, visionService, app.Config.MaxImageSizeMB)
+	authH := &handlers.AuthHandler{Service: svc}
+	adminH := &handlers.AdminHandler{Service: svc}
+	graphH, err := graph.New(svc)
+	if err != nil {
+		return nil, nil, err
+	}
+	app.Health = app.setupHealth(visionService)
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.RealIP(app.Config.TrustedProxies))
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Tracing(app.Tracer))
+	r.Use(middleware.Logging)
+	r.Use(middleware.Metrics(app.Metrics))
+	r.Use(app.corsMiddleware())
+	r.Use(middleware.Secure(middleware.SecurityHeaders{
+		StrictTransportSecurity: app.Config.HSTS,
+		ContentSecurityPolicy:   app.Config.ContentSecurityPolicy,
+		ContentTypeOptions:      app.Config.ContentTypeOptions,
+		ReferrerPolicy:          app.Config.ReferrerPolicy,
+		FrameOptions:            app.Config.FrameOptions,
+	}))
+	r.Use(doc.Validator(app.Config.OpenAPIValidateResponses))
+
+	docs, err := doc.Docs()
+	if err != nil {
+		return nil, nil, err
+	}
+	r.Method(http.MethodGet, "/openapi.json", doc.Handler())
+	r.Method(http.MethodGet, "/docs", docs)
+	app.setupRoutes(r, h, authH, adminH, graphH)
+	return r, doc, nil
 }
 
 // setupVisionService initializes the AI vision service for ingredient detection.
 func (app *App) setupVisionService() vision.VisionService {
 	if app.Config.AIServiceURL != "" {
-		log.Printf("Local AI service configured at: %s", app.Config.AIServiceURL)
-		return vision.NewLocalAIService(app.Config.AIServiceURL)
+		slog.Info("local AI service configured", slog.String("url", app.Config.AIServiceURL))
+		return vision.Instrumented{Service: vision.NewLocalAIService(app.Config.AIServiceURL), Metrics: app.Metrics}
 	}
 
-	log.Printf("WARNING: No AI service configured - ingredient detection disabled")
-	log.Printf("Set AI_SERVICE_URL env var to use local AI service")
-	log.Printf("Start local AI service with: docker-compose up ai-service")
+	slog.Warn("no AI service configured, ingredient detection disabled; set AI_SERVICE_URL and start it with: docker-compose up ai-service")
 	return nil
 }
 
-// corsMiddleware configures CORS settings for the application.
-func (app *App) corsMiddleware() func(http.Handler) http.Handler {
-	allowedOrigins := strings.Split(app.Config.AllowedOrigins, ",")
-	for i := range allowedOrigins {
-		allowedOrigins[i] = strings.TrimSpace(allowedOrigins[i])
+// setupHealth builds the readiness checks. The database and its schema are
+// critical; the AI service is not, as only ingredient detection needs it, and
+// its probe is cached for AI_HEALTH_CACHE_TTL to spare the model server.
+func (app *App) setupHealth(visionService vision.VisionService) *health.Checker {
+	timeout := app.Config.HealthCheckTimeout
+	visionProbe := func(context.Context) (map[string]any, error) {
+		return nil, errors.New("AI_SERVICE_URL not set, ingredient detection disabled")
+	}
+	if hc, ok := visionService.(vision.HealthChecker); ok {
+		visionProbe = hc.Health
 	}
+	return health.NewChecker(
+		health.Check{Name: "database", Critical: true, Timeout: timeout, Probe: health.Ping(app.DB)},
+		health.Check{Name: "migrations", Critical: true, Timeout: timeout, Probe: health.Migrations(app.DB, migrations.Latest())},
+		health.Check{Name: "vision", Timeout: timeout, CacheTTL: app.Config.AIHealthCacheTTL, Probe: visionProbe},
+	)
+}
 
+// corsMiddleware configures CORS for the origins in ALLOWED_ORIGINS, which
+// may use a wildcard for subdomains or ports. The configuration has been
+// validated, so "*" never comes with credentials.
+func (app *App) corsMiddleware() func(http.Handler) http.Handler {
 	return cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:5173", "http://localhost:8080", "*"},
+		AllowedOrigins:   app.Config.AllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Requested-With"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
-		MaxAge:           300,
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-CSRF-Token", "X-Request-ID", "X-Requested-With", "traceparent", "tracestate"},
+		ExposedHeaders:   []string{"Content-Disposition", "Link", "RateLimit-Limit", "RateLimit-Policy", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "X-Request-ID"},
+		AllowCredentials: app.Config.CORSAllowCredentials,
+		MaxAge:           int(app.Config.CORSMaxAge.Seconds()),
 	})
 }
 
+// authenticators returns the credential checks callers can use: access
+// tokens first, then API keys. Outcomes are counted per kind of
+// credentials.
+func (app *App) authenticators() []middleware.Authenticator {
+	bearer := middleware.CountedAuthenticator{
+		Name:          "jwt",
+		Authenticator: middleware.BearerJWT{Verifier: app.Keys, Denylist: app.Service},
+		Metrics:       app.Metrics,
+	}
+	apiKey := middleware.CountedAuthenticator{
+		Name:          "api_key",
+		Authenticator: middleware.APIKey{Store: app.Service},
+		Metrics:       app.Metrics,
+	}
+	return []middleware.Authenticator{bearer, apiKey}
+}
+
+// initGRPC sets up the gRPC server for GRPC_PORT and, for
+// GRPC_GATEWAY_PORT, the gateway serving it as JSON. Both are left out when
+// their port is off.
+func (app *App) initGRPC(visionService vision.VisionService) error {
+	if app.Config.GRPCPort == "" {
+		return nil
+	}
+	trusted := app.Config.TrustedProxies
+	if app.Config.GRPCGatewayPort != "" {
+		// The gateway calls over loopback, passing the client's address on
+		// in x-forwarded-for.
+		trusted = append(slices.Clone(trusted), netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128"))
+	}
+	app.GRPC = grpcserver.New(grpcserver.Options{
+		Service:        app.Service,
+		Vision:         visionService,
+		MaxImageBytes:  int64(app.Config.MaxImageSizeMB) * 1024 * 1024,
+		Authenticators: app.authenticators(),
+		Limiter:        app.Limiter,
+		TrustedProxies: trusted,
+	})
+	if app.Config.GRPCGatewayPort == "" {
+		return nil
+	}
+	gw, err := grpcserver.NewGateway("localhost:" + app.Config.GRPCPort)
+	if err != nil {
+		return err
+	}
+	app.Gateway = gw
+	return nil
+}
+
 // setupRoutes registers all HTTP endpoints for the application.
-func (app *App) setupRoutes(r *chi.Mux, h *handlers.Handler, authH *handlers.AuthHandler) {
-	r.Get("/health", healthCheck)
+func (app *App) setupRoutes(r *chi.Mux, h *handlers.Handler, authH *handlers.AuthHandler, adminH *handlers.AdminHandler, graphH *graph.Handler) {
+	r.Get("/livez", health.Live)
+	r.Method(http.MethodGet, "/readyz", app.Health.Handler())
+	r.Method(http.MethodGet, "/health", app.Health.Handler())
+	r.Method(http.MethodGet, "/version", buildinfo.Handler())
+	r.Get("/.well-known/jwks.json", authH.JWKS)
 
 	r.Get("/recipes", h.ListRecipes)
-	r.Get("/recipes/{id}", h.GetRecipe)
-	r.Post("/match", h.Match)
-	r.Post("/detect-ingredients", h.DetectIngredients)
+	r.Get("/recipes/trending", h.ListTrending)
+	r.Get("/recipes/popular", h.ListPopular)
+
+	// Session and account management need an access token; the routes
+	// scripts use also accept API keys, limited by scope.
+	authenticators := app.authenticators()
+	jwtAuth := middleware.Authenticate(authenticators[0])
+	keyAuth := middleware.Authenticate(authenticators...)
+	limit := func(policy string) func(http.Handler) http.Handler {
+		return middleware.RateLimit(app.Limiter, policy)
+	}
+
+	identify := middleware.Identify(authenticators...)
+
+	// Views count once per signed-in user or client IP, so viewers are
+	// identified.
+	r.With(identify).Get("/recipes/{id}", h.GetRecipe)
+
+	// Matching and detection are expensive, so they are rate limited even
+	// though they are public. Signed-in callers are identified to get a
+	// bucket of their own rather than sharing their IP address's.
+	r.With(identify, limit(config.RateLimitMatch)).Post("/match", h.Match)
+	r.With(identify, limit(config.RateLimitDetect)).Post("/detect-ingredients", h.DetectIngredients)
+
+	// GraphQL checks credentials and scopes per field, so anonymous callers
+	// can query the public ones.
+	r.With(identify, limit(config.RateLimitGraphQL)).Method(http.MethodGet, "/graphql", graphH)
+	r.With(identify, limit(config.RateLimitGraphQL)).Method(http.MethodPost, "/graphql", graphH)
+
+	authLimited := r.With(limit(config.RateLimitAuth))
+	authLimited.Post("/auth/register", authH.Register)
+	authLimited.Post("/auth/login", authH.Login)
+	authLimited.Post("/auth/refresh", authH.Refresh)
+	authLimited.Post("/auth/forgot-password", authH.ForgotPassword)
+	authLimited.Post("/auth/reset-password", authH.ResetPassword)
+	authLimited.Post("/auth/verify-email", authH.VerifyEmail)
+	authLimited.Post("/auth/confirm-email", authH.ConfirmEmail)
+	r.Get("/auth/oidc/providers", authH.ListOIDCProviders)
+	authLimited.Get("/auth/oidc/{provider}/login", authH.OIDCLogin)
+	authLimited.Get("/auth/oidc/{provider}/callback", authH.OIDCCallback)
+	scoped := func(scope auth.Scope) chi.Router {
+		return r.With(keyAuth, middleware.RequireScope(scope))
+	}
+
+	r.With(jwtAuth).Post("/auth/logout", authH.Logout)
+	r.With(jwtAuth).Get("/me", authH.GetProfile)
+	r.With(jwtAuth).Patch("/me", authH.UpdateProfile)
+	r.With(jwtAuth).Delete("/me", authH.DeleteAccount)
+	r.With(jwtAuth).Put("/me/email", authH.ChangeEmail)
+	r.With(jwtAuth).Get("/me/export", authH.ExportAccount)
+	r.With(jwtAuth).Get("/me/sessions", authH.ListSessions)
+	r.With(jwtAuth).Delete("/me/sessions", authH.RevokeOtherSessions)
+	r.With(jwtAuth).Delete("/me/sessions/{id}", authH.RevokeSession)
+	r.With(jwtAuth).Put("/me/password", authH.ChangePassword)
+	r.With(jwtAuth).Post("/me/verify-email", authH.ResendVerification)
+	r.With(jwtAuth).Get("/me/api-keys", authH.ListAPIKeys)
+	r.With(jwtAuth).Post("/me/api-keys", authH.CreateAPIKey)
+	r.With(jwtAuth).Delete("/me/api-keys/{id}", authH.RevokeAPIKey)
+	r.With(jwtAuth).Get("/me/identities", authH.ListIdentities)
+
+	scoped(auth.ScopeRatingsWrite).Post("/ratings", h.PostRating)
+	scoped(auth.ScopeFavoritesWrite).Post("/favorites/{id}", h.AddFavorite)
+	scoped(auth.ScopeFavoritesWrite).Delete("/favorites/{id}", h.RemoveFavorite)
+	scoped(auth.ScopeFavoritesRead).Get("/favorites", h.ListFavorites)
+	scoped(auth.ScopeFavoritesRead).Get("/favorites/{id}", h.IsFavorite)
+	scoped(auth.ScopeFavoritesRead).Get("/suggestions", h.GetSuggestions)
+
+	scoped(auth.ScopeRecipesWrite).Post("/recipes", h.CreateRecipe)
+	scoped(auth.ScopeRecipesWrite).Put("/recipes/{id}", h.UpdateRecipe)
+	scoped(auth.ScopeRecipesWrite).Delete("/recipes/{id}", h.DeleteRecipe)
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(jwtAuth)
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(auth.RoleEditor))
+			r.Get("/ingredients", adminH.ListIngredientLexicon)
+			r.Put("/ingredients/{variant}", adminH.SetIngredientVariant)
+			r.Delete("/ingredients/{variant}", adminH.DeleteIngredientVariant)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(auth.RoleModerator))
+			r.Get("/reviews", adminH.ListReviews)
+			r.Delete("/reviews/{id}", adminH.DeleteReview)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireRole(auth.RoleAdmin))
+			r.Get("/users", adminH.ListUsers)
+			r.Put("/users/{id}/role", adminH.SetUserRole)
+			r.Put("/recipes/{id}/author", adminH.SetRecipeAuthor)
+			r.Post("/login-locks/unlock", adminH.UnlockLogin)
+			r.Get("/audit-events", adminH.ListAuditEvents)
+		})
+	})
+}
+
+// Run serves HTTP on the configured port, and gRPC and its gateway on
+// theirs when enabled, until ctx is cancelled, e.g. on SIGTERM, and then
+// shuts the servers down gracefully:
+//  1. readiness fails, on /readyz and the gRPC health service, so load
+//     balancers stop routing new requests here
+//  2. after SHUTDOWN_DRAIN_DELAY, for them to notice, the listeners close
+//  3. in-flight requests and calls get up to SHUTDOWN_TIMEOUT to finish;
+//     gRPC stops last, as the gateway calls it
+//
+// Background workers and the database are left to Close.
+//
+// Returns nil after a graceful shutdown, or the error that stopped a
+// server, once the others are closed.
+func (app *App) Run(ctx context.Context) error {
+	cfg := app.Config
+	servers := []*http.Server{app.httpServer(cfg.Port, app.Router)}
+	if app.Gateway != nil {
+		servers = append(servers, app.httpServer(cfg.GRPCGatewayPort, app.Gateway))
+	}
+	if cfg.MetricsPort != "" {
+		servers = append(servers, app.httpServer(cfg.MetricsPort, app.metricsHandler()))
+	}
+	var grpcListener net.Listener
+	if app.GRPC != nil {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			return err
+		}
+		grpcListener = lis
+	}
+
+	serveErr := make(chan error, len(servers)+1)
+	slog.Info("starting server", slog.String("addr", servers[0].Addr), slog.String("version", buildinfo.Version))
+	if cfg.MetricsPort != "" {
+		slog.Info("serving metrics", slog.String("addr", ":"+cfg.MetricsPort))
+	}
+	for _, srv := range servers {
+		go func() {
+			serveErr <- srv.ListenAndServe()
+		}()
+	}
+	if grpcListener != nil {
+		slog.Info("starting gRPC server", slog.String("addr", grpcListener.Addr().String()))
+		if app.Gateway != nil {
+			slog.Info("starting gRPC gateway", slog.String("addr", ":"+cfg.GRPCGatewayPort))
+		}
+		go func() {
+			serveErr <- app.GRPC.Serve(grpcListener)
+		}()
+	}
+	// closeAll stops every server at once, when one of them has failed.
+	closeAll := func(err error) error {
+		for _, srv := range servers {
+			srv.Close()
+		}
+		if app.GRPC != nil {
+			app.GRPC.Stop()
+		}
+		return err
+	}
+
+	select {
+	case err := <-serveErr:
+		return closeAll(err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down, draining requests", slog.Duration("drain_delay", cfg.ShutdownDrainDelay))
+	if app.Health != nil {
+		app.Health.Drain()
+	}
+	if app.GRPC != nil {
+		app.GRPC.Drain()
+	}
+	// Clients reconnect, to another instance, after their next request
+	// rather than reusing a connection to this one.
+	for _, srv := range servers {
+		srv.SetKeepAlivesEnabled(false)
+	}
+	select {
+	case <-time.After(cfg.ShutdownDrainDelay):
+	case err := <-serveErr:
+		return closeAll(err)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	var err error
+	for _, srv := range servers {
+		if serr := srv.Shutdown(shutdownCtx); serr != nil {
+			slog.Warn("requests still in flight at shutdown timeout, closing connections", slog.String("addr", srv.Addr), slog.Any("error", serr))
+			err = errors.Join(err, srv.Close())
+		}
+	}
+	if app.GRPC != nil {
+		stopped := make(chan struct{})
+		go func() {
+			app.GRPC.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			slog.Warn("gRPC calls still in flight at shutdown timeout, closing connections")
+			app.GRPC.Stop()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
 
-	r.Post("/auth/register", authH.Register)
-	r.Post("/auth/login", authH.Login)
+// metricsHandler serves GET /metrics on the metrics port, to scrapers with
+// METRICS_TOKEN when it is set.
+func (app *App) metricsHandler() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.MetricsAccess(app.Config.MetricsToken))
+	r.Method(http.MethodGet, "/metrics", app.Metrics.Registry.Handler())
+	return r
+}
 
-	jwtAuth := middleware.JWTAuth(app.Config.JWTSecret)
-	r.With(jwtAuth).Post("/ratings", h.PostRating)
-	r.With(jwtAuth).Post("/favorites/{id}", h.AddFavorite)
-	r.With(jwtAuth).Delete("/favorites/{id}", h.RemoveFavorite)
-	r.With(jwtAuth).Get("/favorites", h.ListFavorites)
-	r.With(jwtAuth).Get("/favorites/{id}", h.IsFavorite)
-	r.With(jwtAuth).Get("/suggestions", h.GetSuggestions)
+// httpServer returns an HTTP server for handler on port, with the
+// configured timeouts.
+func (app *App) httpServer(port string, handler http.Handler) *http.Server {
+	cfg := app.Config
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+		MaxHeaderBytes:    cfg.HTTPMaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
 }
 
-// healthCheck is a simple endpoint that returns 200 OK to indicate server health.
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte("ok"))
+// startWorkers launches background jobs that run for the lifetime of the App.
+// Workers are stopped and awaited by Close.
+func (app *App) startWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopWorkers = cancel
+
+	if app.Config.PopularityRefreshInterval > 0 {
+		app.workers.Add(1)
+		go func() {
+			defer app.workers.Done()
+			app.refreshPopularityLoop(ctx, app.Config.PopularityRefreshInterval)
+		}()
+	}
+
+	if app.Config.SessionPruneInterval > 0 {
+		app.workers.Add(1)
+		go func() {
+			defer app.workers.Done()
+			app.pruneSessionsLoop(ctx, app.Config.SessionPruneInterval)
+		}()
+	}
+
+	if app.keySource() != nil && app.Config.JWTKeyReload > 0 {
+		app.workers.Add(1)
+		go func() {
+			defer app.workers.Done()
+			app.signingKeysLoop(ctx, app.Config.JWTKeyReload)
+		}()
+	}
 }
 
-// Run starts the HTTP server on the configured port.
-func (app *App) Run() error {
-	addr := ":" + app.Config.Port
-	log.Printf("starting server on %s", addr)
-	return http.ListenAndServe(addr, app.Router)
+// refreshPopularityLoop re-materialises the trending and popular recipe scores
+// once at startup and then on every tick until ctx is cancelled.
+func (app *App) refreshPopularityLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := app.Service.RefreshPopularity(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("popularity refresh failed", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
-// Close cleans up application resources.
+// pruneSessionsLoop deletes expired sessions, denylist entries, account
+// tokens, stale failed-login records, abandoned OIDC logins and idle rate
+// limit buckets on every tick until ctx is cancelled.
+func (app *App) pruneSessionsLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := app.Service.PruneSessions(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("session prune failed", slog.Any("error", err))
+		}
+		if err := app.Service.PruneAccountTokens(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("account token prune failed", slog.Any("error", err))
+		}
+		if err := app.Service.PruneLoginAttempts(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("login attempt prune failed", slog.Any("error", err))
+		}
+		if err := app.Service.PruneOIDCLoginStates(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("oidc login state prune failed", slog.Any("error", err))
+		}
+		if err := app.Limiter.Prune(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Error("rate limit bucket prune failed", slog.Any("error", err))
+		}
+	}
+}
+
+// signingKeysLoop keeps the key ring in step with its source on every tick
+// until ctx is cancelled. With the db source it also rotates the signing key
+// once it is older than JWT_KEY_ROTATION_INTERVAL; other instances pick the
+// new key up on their next reload.
+func (app *App) signingKeysLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	src := app.keySource()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if app.Config.JWTKeySource == config.KeySourceDB {
+			rotated, err := app.Service.EnsureSigningKey(ctx, app.Config.JWTSigningAlg, app.Config.JWTKeyRotation)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("signing key rotation failed", slog.Any("error", err))
+			} else if rotated {
+				slog.Info("rotated jwt signing key")
+			}
+		}
+		if err := app.Keys.Reload(ctx, src); err != nil && ctx.Err() == nil {
+			slog.Error("signing key reload failed", slog.Any("error", err))
+		}
+	}
+}
+
+// Close cleans up application resources once the servers have stopped:
+// background workers are stopped and awaited along with emails still being
+// sent, the gateway's connection is closed, queued spans are exported and
+// the database pool is closed.
 func (app *App) Close() error {
+	if app.stopWorkers != nil {
+		app.stopWorkers()
+		app.workers.Wait()
+	}
+	if app.Service != nil {
+		app.Service.Wait()
+	}
+	if app.Gateway != nil {
+		app.Gateway.Close()
+	}
+	if app.Tracer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := app.Tracer.Shutdown(ctx); err != nil {
+			slog.Warn("trace export not flushed", slog.Any("error", err))
+		}
+		cancel()
+	}
 	if app.DB != nil {
 		return app.DB.Close()
 	}
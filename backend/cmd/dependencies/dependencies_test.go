@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/config"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// TestRoutesMatchOpenAPI builds the router as the server does and checks
+// that every route is documented and every documented operation routed.
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	cfg, err := config.Load(config.Options{})
+	if err != nil {
+		t.Fatalf("loading default config: %v", err)
+	}
+	app := &App{Config: cfg, Service: service.NewService(nil), Metrics: metrics.New()}
+
+	r, doc, err := app.newRouter(nil)
+	if err != nil {
+		t.Fatalf("building router: %v", err)
+	}
+	if err := doc.CheckRoutes(r); err != nil {
+		t.Error(err)
+	}
+}
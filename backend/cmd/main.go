@@ -2,28 +2,88 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/joho/godotenv"
 
 	app "github.com/varnit-ta/smart-recipe-generator/backend/cmd/dependencies"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/config"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
 )
 
+// usage describes the command line.
+const usage = `usage:
+  server [--config FILE] [--SETTING VALUE ...]   run the server
+  server config print [--config FILE] [...]      print the configuration, secrets redacted
+
+Every setting can be given as a flag named after its environment variable,
+e.g. --port 8081 or --db-max-open-conns=30; run "server config print" to list them.
+`
+
 // main is the application entry point.
-// It loads configuration and starts the application.
+// It loads configuration, sets up logging and runs the application until
+// SIGINT or SIGTERM, then shuts it down gracefully. A second signal stops
+// the process at once.
+//
+// "config print" prints the configuration instead of running the server.
 func main() {
 	_ = godotenv.Load()
 
-	cfg := config.Load()
+	args := os.Args[1:]
+	printConfig := len(args) >= 2 && args[0] == "config" && args[1] == "print"
+	if printConfig {
+		args = args[2:]
+	}
+
+	cfg, err := config.Load(config.Options{Args: args})
+	if errors.Is(err, config.ErrHelp) {
+		fmt.Fprint(os.Stderr, usage)
+		return
+	}
+	if printConfig {
+		if perr := cfg.Print(os.Stdout); perr != nil {
+			log.Fatalf("failed to print configuration: %v", perr)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if printConfig {
+		return
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		log.Fatalf("failed to initialize logging: %v", err)
+	}
+	slog.SetDefault(logger)
 
 	application, err := app.New(cfg)
 	if err != nil {
-		log.Fatalf("failed to initialize application: %v", err)
+		slog.Error("failed to initialize application", slog.Any("error", err))
+		os.Exit(1)
 	}
-	defer application.Close()
 
-	if err := application.Run(); err != nil {
-		log.Fatalf("server error: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	runErr := application.Run(ctx)
+	if err := application.Close(); err != nil {
+		slog.Error("failed to close application", slog.Any("error", err))
+	}
+	if runErr != nil {
+		slog.Error("server error", slog.Any("error", runErr))
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,45 @@
+// Command mockoidc runs a local OpenID provider that signs everyone in
+// without a password, for trying the OIDC login flow in development.
+//
+// Register it with the backend as:
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9400
+//	OIDC_MOCK_CLIENT_ID=recipes
+//	OIDC_MOCK_CLIENT_SECRET=secret
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/oidc/oidctest"
+)
+
+// main starts the provider on MOCK_OIDC_ADDR (default localhost:9400).
+func main() {
+	addr := os.Getenv("MOCK_OIDC_ADDR")
+	if addr == "" {
+		addr = "localhost:9400"
+	}
+	issuer := os.Getenv("MOCK_OIDC_ISSUER")
+	if issuer == "" {
+		issuer = "http://" + addr
+	}
+
+	p, err := oidctest.New(issuer, envOr("MOCK_OIDC_CLIENT_ID", "recipes"), envOr("MOCK_OIDC_CLIENT_SECRET", "secret"))
+	if err != nil {
+		log.Fatalf("failed to create provider: %v", err)
+	}
+
+	log.Printf("mock oidc provider %s listening on %s", issuer, addr)
+	log.Fatal(http.ListenAndServe(addr, p))
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,48 @@
+// Package apierror defines the error response shared by every HTTP handler
+// and middleware.
+//
+// Every error body has the same shape:
+//
+//	{"code": "conflict", "message": "email already registered",
+//	 "fields": [{"field": "email", "message": "is already registered"}]}
+//
+// code is derived from the HTTP status; fields is only present when the
+// error concerns specific input fields.
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// Response is the JSON body of an error response.
+type Response struct {
+	Code    string                `json:"code"`
+	Message string                `json:"message"`
+	Fields  []validate.FieldError `json:"fields,omitempty"`
+}
+
+// Write sends an error response with the given status and message.
+func Write(w http.ResponseWriter, status int, message string) {
+	WriteFields(w, status, message, nil)
+}
+
+// WriteFields sends an error response that lists per-field problems.
+func WriteFields(w http.ResponseWriter, status int, message string, fields []validate.FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Response{Code: Code(status), Message: message, Fields: fields})
+}
+
+// Code returns the machine-readable code for an HTTP status, e.g.
+// "not_found" for 404.
+func Code(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"slices"
+)
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise
+// in logs and secret scanners.
+const APIKeyPrefix = "srg_"
+
+// apiKeyDisplayLength is how much of a key is kept in clear as its prefix.
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// ErrInvalidAPIKey is returned for an unknown or expired API key.
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+// Scope is a permission granted to an API key. User sessions are not
+// scoped: they may do everything the user's role allows.
+type Scope string
+
+// Scopes an API key can be granted.
+const (
+	ScopeRecipesWrite   Scope = "recipes:write"
+	ScopeRatingsWrite   Scope = "ratings:write"
+	ScopeFavoritesRead  Scope = "favorites:read"
+	ScopeFavoritesWrite Scope = "favorites:write"
+)
+
+// Scopes lists every scope, in the order they are documented.
+var Scopes = []Scope{ScopeRecipesWrite, ScopeRatingsWrite, ScopeFavoritesRead, ScopeFavoritesWrite}
+
+// ParseScope returns the scope named s, or false if there is none.
+func ParseScope(s string) (Scope, bool) {
+	scope := Scope(s)
+	return scope, slices.Contains(Scopes, scope)
+}
+
+// Principal is the authenticated caller of a request: a user signed in with
+// an access token, or a script using one of the user's API keys.
+type Principal struct {
+	UserID int
+	Role   Role
+	// APIKeyID is the key the request was made with; 0 for access tokens.
+	APIKeyID int
+	// Scopes limits what an API key may do. It is ignored for access tokens.
+	Scopes []Scope
+	// Claims are the access token's claims; nil for API keys.
+	Claims *Claims
+}
+
+// HasScope reports whether the principal may act within scope. Access
+// tokens have every scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	if p.APIKeyID == 0 {
+		return true
+	}
+	return slices.Contains(p.Scopes, scope)
+}
+
+// NewAPIKey generates a random API key with 256 bits of randomness.
+//
+// Returns the key to hand to the user once, and its display prefix.
+func NewAPIKey() (key, prefix string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], nil
+}
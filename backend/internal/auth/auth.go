@@ -5,7 +5,9 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -15,8 +17,15 @@ import (
 
 // Claims represents the JWT token payload containing user identification
 // and standard JWT claims (expiration, issued at, etc.).
+//
+// The token ID (jti, RegisteredClaims.ID) lets a token be revoked before it
+// expires. SessionID links an access token to the refresh-token session that
+// issued it; it is 0 for tokens issued outside a session. Role is the user's
+// role when the token was issued; tokens without one act as RoleUser.
 type Claims struct {
-	UserID int `json:"userId"`
+	UserID    int  `json:"userId"`
+	SessionID int  `json:"sid,omitempty"`
+	Role      Role `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -48,23 +57,45 @@ func VerifyPassword(hash, password string) error {
 //
 // Returns the signed token string or an error if signing fails.
 func GenerateJWT(secret string, userID int, expiryHours int) (string, error) {
-	now := time.Now()
-	expiresAt := now.Add(time.Duration(expiryHours) * time.Hour)
+	claims, err := NewClaims(userID, time.Duration(expiryHours)*time.Hour)
+	if err != nil {
+		return "", err
+	}
+	return SignJWT(secret, claims)
+}
 
-	claims := Claims{
+// NewClaims builds access-token claims for userID that expire after ttl,
+// with a fresh random token ID.
+//
+// Returns the claims or an error if random generation fails.
+func NewClaims(userID int, ttl time.Duration) (Claims, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Claims{}, err
+	}
+	now := time.Now()
+	return Claims{
 		UserID: userID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			ID:        hex.EncodeToString(b),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-	}
+	}, nil
+}
 
+// SignJWT signs claims using HMAC-SHA256.
+//
+// Returns the signed token string or an error if signing fails.
+func SignJWT(secret string, claims Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
-// ParseJWT validates and parses a JWT token string.
+// ParseJWT validates and parses an HS256 JWT token string.
 // Verifies the signature, expiration, and extracts the claims.
+// Tokens signed with any other algorithm are rejected; use a KeyRing to
+// verify asymmetric tokens.
 //
 // Parameters:
 //   - secret: Secret key used to verify the token signature
@@ -74,11 +105,11 @@ func GenerateJWT(secret string, userID int, expiryHours int) (string, error) {
 // Common errors include expired tokens, invalid signatures, or malformed tokens.
 func ParseJWT(secret, tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		if t.Method != jwt.SigningMethodHS256 {
 			return nil, errors.New("unexpected signing method")
 		}
 		return []byte(secret), nil
-	})
+	}, jwt.WithValidMethods([]string{AlgHS256}))
 	if err != nil {
 		return nil, err
 	}
@@ -100,3 +131,11 @@ func RandomSecret() (string, error) {
 	}
 	return base64.StdEncoding.EncodeToString(b), nil
 }
+
+// HashToken returns the hex-encoded SHA-256 digest of an opaque token.
+// Refresh tokens carry 256 bits of randomness, so a fast hash is enough to
+// keep stored values useless to anyone who reads the database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,431 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+	AlgHS256 = "HS256"
+)
+
+// ErrUnknownKey is returned when a token names a key ID the ring does not hold.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// SigningKey is one key in a KeyRing.
+//
+// Keys without a private half are verify-only: they were rotated out but
+// still validate tokens issued before the rotation. HS256 keys hold the
+// shared secret in both halves and are never published.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.PrivateKey
+	Public    crypto.PublicKey
+}
+
+// CanSign reports whether the key has a private half.
+func (k SigningKey) CanSign() bool {
+	return k.Private != nil
+}
+
+// KeySource loads the keys of a KeyRing and names the one to sign with.
+type KeySource interface {
+	LoadKeys(ctx context.Context) (keys []SigningKey, activeID string, err error)
+}
+
+// KeySourceFunc adapts a function to KeySource.
+type KeySourceFunc func(ctx context.Context) ([]SigningKey, string, error)
+
+// LoadKeys calls f.
+func (f KeySourceFunc) LoadKeys(ctx context.Context) ([]SigningKey, string, error) {
+	return f(ctx)
+}
+
+// KeyRing signs tokens with its active key and verifies tokens against any
+// key it holds, selected by the token's kid header.
+//
+// Rotating means loading a new active key while keeping the previous ones:
+// tokens signed before the rotation stay valid until they expire.
+// A KeyRing is safe for concurrent use.
+type KeyRing struct {
+	mu     sync.RWMutex
+	keys   map[string]SigningKey
+	active string
+}
+
+// NewKeyRing builds a ring from keys, signing with activeID.
+//
+// Returns an error if activeID is missing or cannot sign.
+func NewKeyRing(keys []SigningKey, activeID string) (*KeyRing, error) {
+	k := &KeyRing{}
+	if err := k.Replace(keys, activeID); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// NewHMACKeyRing builds a ring holding a single HS256 shared secret.
+// Tokens are signed without a kid, as GenerateJWT does.
+func NewHMACKeyRing(secret string) *KeyRing {
+	k, _ := NewKeyRing([]SigningKey{{Algorithm: AlgHS256, Private: []byte(secret), Public: []byte(secret)}}, "")
+	return k
+}
+
+// Load builds a ring from a KeySource.
+func Load(ctx context.Context, src KeySource) (*KeyRing, error) {
+	keys, active, err := src.LoadKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyRing(keys, active)
+}
+
+// Reload replaces the ring's keys with the current contents of src.
+// On error the ring keeps its previous keys.
+func (k *KeyRing) Reload(ctx context.Context, src KeySource) error {
+	keys, active, err := src.LoadKeys(ctx)
+	if err != nil {
+		return err
+	}
+	return k.Replace(keys, active)
+}
+
+// Replace swaps the ring's keys, signing with activeID from now on.
+func (k *KeyRing) Replace(keys []SigningKey, activeID string) error {
+	m := make(map[string]SigningKey, len(keys))
+	for _, key := range keys {
+		switch key.Algorithm {
+		case AlgRS256, AlgEdDSA, AlgHS256:
+		default:
+			return fmt.Errorf("key %q: unsupported algorithm %q", key.ID, key.Algorithm)
+		}
+		m[key.ID] = key
+	}
+	active, ok := m[activeID]
+	if !ok {
+		return fmt.Errorf("active signing key %q not found", activeID)
+	}
+	if !active.CanSign() {
+		return fmt.Errorf("active signing key %q has no private key", activeID)
+	}
+
+	k.mu.Lock()
+	k.keys, k.active = m, activeID
+	k.mu.Unlock()
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key new tokens are signed with.
+func (k *KeyRing) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// Sign signs claims with the active key and records its ID in the kid header.
+//
+// Returns the signed token string or an error if signing fails.
+func (k *KeyRing) Sign(claims Claims) (string, error) {
+	k.mu.RLock()
+	key := k.keys[k.active]
+	k.mu.RUnlock()
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.Private)
+}
+
+// Parse validates a token against the key named by its kid header and
+// returns its claims. The token's algorithm must match the key's, so a
+// public key can never be used as an HMAC secret.
+func (k *KeyRing) Parse(tokenStr string) (*Claims, error) {
+	k.mu.RLock()
+	keys := k.keys
+	k.mu.RUnlock()
+
+	var algs []string
+	seen := map[string]bool{}
+	for _, key := range keys {
+		if !seen[key.Algorithm] {
+			seen[key.Algorithm] = true
+			algs = append(algs, key.Algorithm)
+		}
+	}
+
+	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := keys[kid]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+		if t.Method.Alg() != key.Algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods(algs))
+	if err != nil {
+		return nil, err
+	}
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 and ECDSA
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// PublicKey decodes the key into an *rsa.PublicKey, *ecdsa.PublicKey or
+// ed25519.PublicKey, e.g. to verify tokens signed by another issuer.
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch j.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwk %q: bad modulus: %w", j.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("jwk %q: bad exponent", j.KeyID)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", j.KeyID, j.Curve)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(j.X)
+		y, errY := base64.RawURLEncoding.DecodeString(j.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("jwk %q: bad coordinates", j.KeyID)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(key.X, key.Y) {
+			return nil, fmt.Errorf("jwk %q: point is not on the curve", j.KeyID)
+		}
+		return key, nil
+	case "OKP":
+		if j.Curve != "Ed25519" {
+			return nil, fmt.Errorf("jwk %q: unsupported curve %q", j.KeyID, j.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("jwk %q: bad key", j.KeyID)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("jwk %q: unsupported key type %q", j.KeyID, j.KeyType)
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public halves of every asymmetric key in the ring,
+// including verify-only keys, sorted by key ID. Shared HMAC secrets are
+// never included.
+func (k *KeyRing) JWKS() JWKSet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range k.keys {
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// GenerateSigningKey creates a new RS256 (2048-bit) or EdDSA (Ed25519) key.
+// Key IDs start with the creation time, so they sort in rotation order.
+func GenerateSigningKey(alg string) (SigningKey, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return SigningKey{}, err
+	}
+	id := time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+
+	switch alg {
+	case AlgEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		return SigningKey{ID: id, Algorithm: AlgEdDSA, Private: priv, Public: pub}, nil
+	case AlgRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return SigningKey{}, err
+		}
+		return SigningKey{ID: id, Algorithm: AlgRS256, Private: priv, Public: &priv.PublicKey}, nil
+	}
+	return SigningKey{}, fmt.Errorf("unsupported signing algorithm %q", alg)
+}
+
+// EncodePrivateKeyPEM encodes a key's private half as a PKCS #8 PEM block.
+func EncodePrivateKeyPEM(k SigningKey) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(k.Private)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+// EncodePublicKeyPEM encodes a key's public half as a PKIX PEM block.
+func EncodePublicKeyPEM(k SigningKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})), nil
+}
+
+// ParseKeyPEM decodes a PKCS #8 private key or PKIX public key PEM block
+// into a SigningKey with the given ID. The algorithm follows from the key
+// type: RSA keys sign RS256 and Ed25519 keys sign EdDSA.
+func ParseKeyPEM(id string, data []byte) (SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return SigningKey{}, fmt.Errorf("key %q: no PEM block found", id)
+	}
+
+	key := SigningKey{ID: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("key %q: %w", id, err)
+		}
+		key.Private = priv
+		if signer, ok := priv.(crypto.Signer); ok {
+			key.Public = signer.Public()
+		}
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return SigningKey{}, fmt.Errorf("key %q: %w", id, err)
+		}
+		key.Public = pub
+	default:
+		return SigningKey{}, fmt.Errorf("key %q: unsupported PEM block %q", id, block.Type)
+	}
+
+	switch key.Public.(type) {
+	case *rsa.PublicKey:
+		key.Algorithm = AlgRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgEdDSA
+	default:
+		return SigningKey{}, fmt.Errorf("key %q: unsupported key type %T", id, key.Public)
+	}
+	return key, nil
+}
+
+// FileKeySource loads keys from the *.pem files in a directory. Each file
+// name (without extension) is the key ID; private key files can sign, public
+// key files only verify.
+//
+// Rotate by adding a new private key file and, once tokens signed with the
+// old key have expired, replacing the old file with its public key or
+// removing it.
+type FileKeySource struct {
+	Dir string
+	// ActiveID selects the signing key. When empty, the private key with the
+	// greatest ID is used.
+	ActiveID string
+}
+
+// LoadKeys reads every key file in the directory.
+func (f FileKeySource) LoadKeys(ctx context.Context) ([]SigningKey, string, error) {
+	paths, err := filepath.Glob(filepath.Join(f.Dir, "*.pem"))
+	if err != nil {
+		return nil, "", err
+	}
+	sort.Strings(paths)
+
+	var keys []SigningKey
+	active := f.ActiveID
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, "", err
+		}
+		key, err := ParseKeyPEM(strings.TrimSuffix(filepath.Base(p), ".pem"), data)
+		if err != nil {
+			return nil, "", err
+		}
+		keys = append(keys, key)
+		if f.ActiveID == "" && key.CanSign() {
+			active = key.ID
+		}
+	}
+	if len(keys) == 0 {
+		return nil, "", fmt.Errorf("no *.pem signing keys found in %s", f.Dir)
+	}
+	return keys, active, nil
+}
@@ -0,0 +1,43 @@
+package auth
+
+// Role is a user's authorization level, stored in users.role and carried in
+// access tokens.
+//
+// Roles are ordered: each one may do everything the roles before it may.
+//   - user: manage their own favorites, ratings and recipes
+//   - editor: edit any recipe and the ingredient lexicon
+//   - moderator: remove reviews (ratings)
+//   - admin: manage users and their roles, login lockouts and the audit log
+type Role string
+
+// Roles, lowest first.
+const (
+	RoleUser      Role = "user"
+	RoleEditor    Role = "editor"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleUser:      1,
+	RoleEditor:    2,
+	RoleModerator: 3,
+	RoleAdmin:     4,
+}
+
+// ParseRole returns the role named s, or false if there is none.
+func ParseRole(s string) (Role, bool) {
+	r := Role(s)
+	_, ok := roleRanks[r]
+	return r, ok
+}
+
+// AtLeast reports whether r grants everything min does. An empty role counts
+// as RoleUser; unknown roles grant nothing.
+func (r Role) AtLeast(min Role) bool {
+	if r == "" {
+		r = RoleUser
+	}
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[min]
+}
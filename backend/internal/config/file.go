@@ -0,0 +1,328 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readFile reads a config file into settings keyed like the environment
+// variables. The format follows the extension: YAML for .yaml and .yml,
+// TOML for .toml.
+//
+// Nesting joins names with underscores, so these all set DB_MAX_OPEN_CONNS:
+//
+//	db_max_open_conns: 20        # YAML
+//	db:
+//	  max_open_conns: 20
+//
+//	[db]                         # TOML
+//	max_open_conns = 20
+//
+// Lists are joined with commas, as the comma separated variables such as
+// OIDC_PROVIDERS expect. Only this subset of each format is supported;
+// anything else is reported as an error with its line number.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		values, err = parseYAML(string(data))
+	case ".toml":
+		values, err = parseTOML(string(data))
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// settingKey turns a path of file keys into the variable name, e.g.
+// ["db", "max-open-conns"] into DB_MAX_OPEN_CONNS.
+func settingKey(path ...string) string {
+	key := strings.Join(path, "_")
+	return strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// parseYAML parses block mappings of scalars, with block ("- item") and
+// flow ("[a, b]") lists of scalars.
+func parseYAML(data string) (map[string]string, error) {
+	type level struct {
+		indent int
+		path   []string
+	}
+	values := map[string]string{}
+	stack := []level{{indent: -1}}
+	var (
+		// pending is the path of a key without a value, whose nested
+		// mapping or list starts on the next line.
+		pending       []string
+		pendingIndent int
+		// list collects the items of the block list of listPath.
+		listPath   []string
+		listIndent int
+		list       []string
+	)
+	flushList := func() {
+		if listPath != nil {
+			values[settingKey(listPath...)] = strings.Join(list, ",")
+			listPath, list = nil, nil
+		}
+	}
+
+	for n, raw := range strings.Split(data, "\n") {
+		lineNo := n + 1
+		line := strings.TrimRight(stripYAMLComment(raw), " \r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", lineNo)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if item, ok := strings.CutPrefix(trimmed, "-"); ok && (item == "" || item[0] == ' ') {
+			switch {
+			case listPath != nil && indent == listIndent:
+			case pending != nil && indent >= pendingIndent:
+				listPath, listIndent, pending = pending, indent, nil
+			default:
+				return nil, fmt.Errorf("line %d: unexpected list item", lineNo)
+			}
+			v, err := yamlScalar(item)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			list = append(list, v)
+			continue
+		}
+		flushList()
+
+		if pending != nil {
+			if indent > pendingIndent {
+				stack = append(stack, level{indent: indent, path: pending})
+			} else {
+				values[settingKey(pending...)] = ""
+			}
+			pending = nil
+		}
+		for len(stack) > 1 && indent < stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		top := &stack[len(stack)-1]
+		if top.indent == -1 {
+			top.indent = indent
+		}
+		if indent != top.indent {
+			return nil, fmt.Errorf("line %d: inconsistent indentation", lineNo)
+		}
+
+		name, value, ok := strings.Cut(trimmed, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, `"'{}[]`) {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNo)
+		}
+		path := append(append([]string{}, top.path...), name)
+		value = strings.TrimSpace(value)
+		if value == "" {
+			pending, pendingIndent = path, indent
+			continue
+		}
+		v, err := yamlScalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[settingKey(path...)] = v
+	}
+	flushList()
+	if pending != nil {
+		values[settingKey(pending...)] = ""
+	}
+	return values, nil
+}
+
+// yamlScalar parses a quoted or plain scalar, or a flow list of them.
+func yamlScalar(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	switch {
+	case v == "" || v == "~" || v == "null":
+		return "", nil
+	case strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(v, "{"):
+		return "", fmt.Errorf("flow mappings are not supported")
+	case strings.HasPrefix(v, "["):
+		return flowList(v, yamlScalar)
+	case strings.HasPrefix(v, `"`):
+		return strconv.Unquote(v)
+	case strings.HasPrefix(v, "'"):
+		if len(v) < 2 || !strings.HasSuffix(v, "'") {
+			return "", fmt.Errorf("unterminated string %s", v)
+		}
+		return strings.ReplaceAll(v[1:len(v)-1], "''", "'"), nil
+	}
+	return v, nil
+}
+
+// parseTOML parses tables ("[db]", "[oidc.google]") of "key = value" pairs
+// with strings, numbers, booleans and single-line arrays of them.
+func parseTOML(data string) (map[string]string, error) {
+	values := map[string]string{}
+	var table []string
+	for n, raw := range strings.Split(data, "\n") {
+		lineNo := n + 1
+		line := strings.TrimSpace(stripComment(raw))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[[") {
+			return nil, fmt.Errorf("line %d: arrays of tables are not supported", lineNo)
+		}
+		if name, ok := strings.CutPrefix(line, "["); ok {
+			name, ok = strings.CutSuffix(name, "]")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("line %d: malformed table header", lineNo)
+			}
+			table = tomlKey(name)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", lineNo)
+		}
+		v, err := tomlValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		values[settingKey(append(append([]string{}, table...), tomlKey(name)...)...)] = v
+	}
+	return values, nil
+}
+
+// tomlKey splits a dotted key into its parts, unquoting quoted ones.
+func tomlKey(key string) []string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return parts
+}
+
+// tomlValue parses a string, number, boolean or array value.
+func tomlValue(v string) (string, error) {
+	switch {
+	case v == "":
+		return "", fmt.Errorf("missing value")
+	case strings.HasPrefix(v, `"""`) || strings.HasPrefix(v, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(v, "{"):
+		return "", fmt.Errorf("inline tables are not supported")
+	case strings.HasPrefix(v, "["):
+		return flowList(v, tomlValue)
+	case strings.HasPrefix(v, `"`):
+		return strconv.Unquote(v)
+	case strings.HasPrefix(v, "'"):
+		if len(v) < 2 || !strings.HasSuffix(v, "'") {
+			return "", fmt.Errorf("unterminated string %s", v)
+		}
+		return v[1 : len(v)-1], nil
+	}
+	// Numbers may use underscores as separators; booleans and dates are
+	// passed on as written.
+	return strings.ReplaceAll(v, "_", ""), nil
+}
+
+// flowList parses "[a, b]" with parse for each item and joins the items
+// with commas.
+func flowList(v string, parse func(string) (string, error)) (string, error) {
+	inner, ok := strings.CutSuffix(strings.TrimPrefix(v, "["), "]")
+	if !ok {
+		return "", fmt.Errorf("lists must be on one line")
+	}
+	var items []string
+	for _, item := range splitOutsideQuotes(inner, ',') {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		s, err := parse(item)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, s)
+	}
+	return strings.Join(items, ","), nil
+}
+
+// stripComment removes a TOML "#" comment that is not inside quotes.
+func stripComment(line string) string {
+	return splitOutsideQuotes(line, '#')[0]
+}
+
+// stripYAMLComment removes a YAML comment: a "#" at the start of the line
+// or after whitespace that is not inside quotes. Any other "#" belongs to
+// the value, as in "abc#def" or "https://app.example.com/#/home". Quotes
+// only open a string at the start of a value, so "it's" is a plain scalar.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		afterSpace := i == 0 || line[i-1] == ' ' || line[i-1] == '\t'
+		switch {
+		case quote == '"':
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case quote == '\'':
+			if c == quote && i+1 < len(line) && line[i+1] == quote {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if afterSpace || line[i-1] == '[' || line[i-1] == ',' {
+				quote = c
+			}
+		case c == '#' && afterSpace:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitOutsideQuotes splits s at every sep that is not inside a single or
+// double quoted string.
+func splitOutsideQuotes(s string, sep byte) []string {
+	var (
+		parts []string
+		quote byte
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
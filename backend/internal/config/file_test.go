@@ -0,0 +1,177 @@
+package config
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+	}{
+		{
+			name: "scalars",
+			data: "port: 8081\napp_env: production\n",
+			want: map[string]string{"PORT": "8081", "APP_ENV": "production"},
+		},
+		{
+			name: "nested mappings",
+			data: "db:\n  max-open-conns: 20\n  retry:\n    max: 3\nport: 1\n",
+			want: map[string]string{"DB_MAX_OPEN_CONNS": "20", "DB_RETRY_MAX": "3", "PORT": "1"},
+		},
+		{
+			name: "comments",
+			data: "# settings\nport: 8081 # the API\n  # indented\n",
+			want: map[string]string{"PORT": "8081"},
+		},
+		{
+			name: "hash inside a value",
+			data: "jwt_secret: abc#def-long-secret\napp_url: https://app.example.com/#/home # frontend\n",
+			want: map[string]string{"JWT_SECRET": "abc#def-long-secret", "APP_URL": "https://app.example.com/#/home"},
+		},
+		{
+			name: "quoted strings",
+			data: "a: \"x # y\"\nb: 'it''s # here'\nc: \"tab\\there\" # comment\n",
+			want: map[string]string{"A": "x # y", "B": "it's # here", "C": "tab\there"},
+		},
+		{
+			name: "apostrophe in a plain scalar",
+			data: "mail_from: it's me # comment\n",
+			want: map[string]string{"MAIL_FROM": "it's me"},
+		},
+		{
+			name: "block list",
+			data: "oidc_providers:\n  - google\n  - \"mock\"\nport: 1\n",
+			want: map[string]string{"OIDC_PROVIDERS": "google,mock", "PORT": "1"},
+		},
+		{
+			name: "block list at the key's indent",
+			data: "cors_origins:\n- a\n- b\n",
+			want: map[string]string{"CORS_ORIGINS": "a,b"},
+		},
+		{
+			name: "flow list",
+			data: "oidc_providers: [google, 'mock', \"a,b\"]\n",
+			want: map[string]string{"OIDC_PROVIDERS": "google,mock,a,b"},
+		},
+		{
+			name: "empty values",
+			data: "a:\nb: ~\nc: null\nd:\n",
+			want: map[string]string{"A": "", "B": "", "C": "", "D": ""},
+		},
+		{
+			name: "document marker and CRLF",
+			data: "---\r\nport: 8081\r\n",
+			want: map[string]string{"PORT": "8081"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"tab indent", "db:\n\tmax: 1\n", "line 2: indent with spaces"},
+		{"inconsistent indent", "db:\n    a: 1\n  b: 2\n", "line 3: inconsistent indentation"},
+		{"not a mapping", "port\n", "line 1: expected \"key: value\""},
+		{"stray list item", "- a\n", "line 1: unexpected list item"},
+		{"multi-line string", "a: |\n  x\n", "line 1: multi-line strings"},
+		{"flow mapping", "a: {b: 1}\n", "line 1: flow mappings"},
+		{"unterminated string", "a: 'x\n", "line 1: unterminated string"},
+		{"multi-line flow list", "a: [x,\n  y]\n", "line 1: lists must be on one line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+	}{
+		{
+			name: "values",
+			data: "port = \"8081\"\ndb_max_open_conns = 1_000\ndebug = true\n",
+			want: map[string]string{"PORT": "8081", "DB_MAX_OPEN_CONNS": "1000", "DEBUG": "true"},
+		},
+		{
+			name: "tables and dotted keys",
+			data: "[db]\nmax-open-conns = 20\n\n[oidc.google]\nclient_id = 'abc'\n\"display.name\" = \"G\"\n",
+			want: map[string]string{"DB_MAX_OPEN_CONNS": "20", "OIDC_GOOGLE_CLIENT_ID": "abc", "OIDC_GOOGLE_DISPLAY_NAME": "G"},
+		},
+		{
+			name: "comments",
+			data: "# settings\nport = \"8081\" # the API\nsecret = \"a#b\"\nraw = 'c#d' # comment\n",
+			want: map[string]string{"PORT": "8081", "SECRET": "a#b", "RAW": "c#d"},
+		},
+		{
+			name: "arrays",
+			data: "oidc_providers = [\"google\", 'mock', \"a,b\"]\nempty = []\n",
+			want: map[string]string{"OIDC_PROVIDERS": "google,mock,a,b", "EMPTY": ""},
+		},
+		{
+			name: "escapes",
+			data: "a = \"x\\\"y\"\nb = 'x\\y'\n",
+			want: map[string]string{"A": "x\"y", "B": `x\y`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"array of tables", "[[servers]]\n", "line 1: arrays of tables"},
+		{"malformed header", "[db\n", "line 1: malformed table header"},
+		{"empty header", "[ ]\n", "line 1: malformed table header"},
+		{"not a pair", "port\n", "line 1: expected \"key = value\""},
+		{"missing value", "a = 1\nport =\n", "line 2: missing value"},
+		{"multi-line string", "a = \"\"\"x\n", "line 1: multi-line strings"},
+		{"inline table", "a = {b = 1}\n", "line 1: inline tables"},
+		{"unterminated string", "a = 'x\n", "line 1: unterminated string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
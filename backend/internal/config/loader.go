@@ -0,0 +1,327 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrHelp is returned by Load when the arguments ask for help.
+var ErrHelp = flag.ErrHelp
+
+// Options selects the layers Load reads on top of the defaults and the
+// environment.
+type Options struct {
+	// File is a YAML or TOML config file. CONFIG_FILE names one when empty;
+	// with neither, no file is read.
+	File string
+	// Args are command-line flags overriding every other layer, one per
+	// setting: --port=8081 or --port 8081 sets PORT, --db-max-open-conns 30
+	// sets DB_MAX_OPEN_CONNS. --config FILE names the config file.
+	Args []string
+}
+
+// Sources of a setting's value, as reported by Settings.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceEnvFile = "env file"
+	SourceFlag    = "flag"
+)
+
+// Setting is one configuration value and the layer it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	// Secret values are redacted when printed.
+	Secret bool
+}
+
+// Errors lists every problem found while loading the configuration, so they
+// can all be fixed in one go. A non-empty Errors is an error.
+type Errors []error
+
+// Error lists the problems one per line.
+func (e Errors) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid configuration:")
+	for _, err := range e {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Add records a problem.
+func (e *Errors) Add(format string, args ...any) {
+	*e = append(*e, fmt.Errorf(format, args...))
+}
+
+// Err returns e as an error, or nil when there are no problems.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// loader looks settings up in the layers, later ones winning: defaults,
+// the config file, the environment (with KEY_FILE for secrets) and flags.
+// It records every setting read, with its source, and every problem found.
+type loader struct {
+	file  map[string]string
+	flags map[string]string
+
+	settings []Setting
+	read     map[string]bool
+	errs     Errors
+}
+
+// newLoader parses the flags and reads the config file of opts.
+func newLoader(opts Options) (*loader, error) {
+	l := &loader{read: map[string]bool{}}
+	file, flags, err := parseArgs(opts.Args)
+	if err != nil {
+		return nil, err
+	}
+	l.flags = flags
+	if file == "" {
+		file = opts.File
+	}
+	if file == "" {
+		file = os.Getenv("CONFIG_FILE")
+	}
+	if file != "" {
+		values, err := readFile(file)
+		if err != nil {
+			l.errs = append(l.errs, err)
+		}
+		l.file = values
+	}
+	return l, nil
+}
+
+// parseArgs splits command-line flags into the config file and settings.
+func parseArgs(args []string) (file string, values map[string]string, err error) {
+	values = map[string]string{}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, ok := strings.CutPrefix(arg, "--")
+		if !ok {
+			name, ok = strings.CutPrefix(arg, "-")
+		}
+		if !ok || name == "" {
+			return "", nil, fmt.Errorf("unexpected argument %q", arg)
+		}
+		if name == "h" || name == "help" {
+			return "", nil, ErrHelp
+		}
+		name, value, hasValue := strings.Cut(name, "=")
+		if !hasValue {
+			if i+1 == len(args) {
+				return "", nil, fmt.Errorf("flag --%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == "config" {
+			file = value
+			continue
+		}
+		values[settingKey(name)] = value
+	}
+	return file, values, nil
+}
+
+// lookup returns the value of key from the highest layer that sets it.
+// Empty environment variables count as unset, as they always have.
+func (l *loader) lookup(key string, secret bool) (value, source string, ok bool) {
+	if v, ok := l.flags[key]; ok {
+		return v, SourceFlag, true
+	}
+	env := os.Getenv(key)
+	if secret {
+		// Secrets can be mounted as files, e.g. Docker or Kubernetes
+		// secrets, and named by KEY_FILE.
+		if path := os.Getenv(key + "_FILE"); path != "" {
+			if env != "" {
+				l.errs.Add("%s: set either %s or %s_FILE, not both", key, key, key)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				l.errs.Add("%s_FILE: %v", key, err)
+				return "", SourceEnvFile, true
+			}
+			return strings.TrimRight(string(data), "\r\n"), SourceEnvFile, true
+		}
+	}
+	if env != "" {
+		return env, SourceEnv, true
+	}
+	if v, ok := l.file[key]; ok {
+		return v, SourceFile, true
+	}
+	return "", "", false
+}
+
+// get returns the value of key, or def, and records the setting.
+func (l *loader) get(key, def string, secret bool) (string, bool) {
+	v, source, ok := l.lookup(key, secret)
+	if !ok {
+		v, source = def, SourceDefault
+	}
+	if !l.read[key] {
+		l.read[key] = true
+		l.settings = append(l.settings, Setting{Key: key, Value: v, Source: source, Secret: secret})
+	}
+	return v, ok
+}
+
+// str reads a string setting.
+func (l *loader) str(key, def string) string {
+	v, _ := l.get(key, def, false)
+	return v
+}
+
+// secret reads a string setting that is redacted when printed and can be
+// read from the file named by KEY_FILE.
+func (l *loader) secret(key, def string) string {
+	v, _ := l.get(key, def, true)
+	return v
+}
+
+// int reads an integer setting; a malformed value is reported and def used.
+func (l *loader) int(key string, def int) int {
+	v, ok := l.get(key, strconv.Itoa(def), false)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		l.errs.Add("%s: %q is not an integer", key, v)
+		return def
+	}
+	return n
+}
+
+// bool reads a boolean setting ("true", "false", "1", "0", ...); a
+// malformed value is reported and def used.
+func (l *loader) bool(key string, def bool) bool {
+	v, ok := l.get(key, strconv.FormatBool(def), false)
+	if !ok {
+		return def
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		l.errs.Add("%s: %q is not true or false", key, v)
+		return def
+	}
+	return b
+}
+
+// list reads a comma separated setting, dropping empty entries.
+func (l *loader) list(key, def string) []string {
+	var items []string
+	for _, item := range strings.Split(l.str(key, def), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// prefixes reads a comma separated list of IP addresses and CIDR ranges;
+// malformed entries are reported and left out.
+func (l *loader) prefixes(key, def string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, item := range l.list(key, def) {
+		if addr, err := netip.ParseAddr(item); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(item)
+		if err != nil {
+			l.errs.Add("%s: %q is not an IP address or CIDR range", key, item)
+			continue
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+	return prefixes
+}
+
+// header reads the value of a response header; "off" leaves it out.
+func (l *loader) header(key, def string) string {
+	v := l.str(key, def)
+	if strings.EqualFold(strings.TrimSpace(v), "off") {
+		return ""
+	}
+	return v
+}
+
+// port reads the port of an optional listener; "off" leaves it out.
+func (l *loader) port(key, def string) string {
+	v := strings.TrimSpace(l.str(key, def))
+	if strings.EqualFold(v, "off") {
+		return ""
+	}
+	return v
+}
+
+// float reads a floating point setting; a malformed value is reported and
+// def used.
+func (l *loader) float(key string, def float64) float64 {
+	v, ok := l.get(key, strconv.FormatFloat(def, 'g', -1, 64), false)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		l.errs.Add("%s: %q is not a number", key, v)
+		return def
+	}
+	return f
+}
+
+// duration reads a duration setting in Go syntax (e.g. "30s", "5m", "2h");
+// a malformed value is reported and def used.
+func (l *loader) duration(key string, def time.Duration) time.Duration {
+	v, ok := l.get(key, def.String(), false)
+	if !ok {
+		return def
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil {
+		l.errs.Add("%s: %q is not a duration such as 30s, 5m or 2h", key, v)
+		return def
+	}
+	return d
+}
+
+// checkUnknown reports flags and file entries that name no setting, which
+// are most likely typos.
+func (l *loader) checkUnknown() {
+	var unknown []string
+	for key := range l.flags {
+		if !l.read[key] {
+			unknown = append(unknown, "unknown flag --"+strings.ToLower(strings.ReplaceAll(key, "_", "-")))
+		}
+	}
+	for key := range l.file {
+		if !l.read[key] {
+			unknown = append(unknown, "unknown setting "+key+" in config file")
+		}
+	}
+	sort.Strings(unknown)
+	for _, msg := range unknown {
+		l.errs = append(l.errs, errors.New(msg))
+	}
+}
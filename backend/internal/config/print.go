@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"text/tabwriter"
+)
+
+// redacted replaces secret values when printed.
+const redacted = "<redacted>"
+
+// Print writes every setting as KEY=value after its source, in the order
+// Load read them. Secrets are redacted; of a URL with a password, such as
+// DATABASE_URL, only the password is.
+func (c Config) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, s := range c.settings {
+		if _, err := fmt.Fprintf(tw, "%s\t%s=%s\n", s.Source, s.Key, s.redacted()); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// redacted returns the value of s safe to print.
+func (s Setting) redacted() string {
+	if !s.Secret || s.Value == "" {
+		return s.Value
+	}
+	if u, err := url.Parse(s.Value); err == nil && u.Scheme != "" && u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			return u.Redacted()
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minJWTSecretLength is the shortest HS256 secret accepted outside
+// development: 256 bits, the size of the hash.
+const minJWTSecretLength = 32
+
+// durationSetting names a duration for validate's range checks.
+type durationSetting struct {
+	key   string
+	value time.Duration
+}
+
+// validate checks the settings against each other and the profile.
+// Returns every problem found.
+func (c Config) validate() Errors {
+	var errs Errors
+
+	// Each listener needs a port of its own.
+	checkPort(&errs, "PORT", c.Port)
+	used := map[string]string{c.Port: "PORT"}
+	for _, l := range []struct{ key, port string }{
+		{"GRPC_PORT", c.GRPCPort},
+		{"GRPC_GATEWAY_PORT", c.GRPCGatewayPort},
+		{"METRICS_PORT", c.MetricsPort},
+	} {
+		if l.port == "" {
+			continue
+		}
+		checkPort(&errs, l.key, l.port)
+		if other, ok := used[l.port]; ok {
+			errs.Add("%s: %s is already used by %s", l.key, l.port, other)
+			continue
+		}
+		used[l.port] = l.key
+	}
+	if c.GRPCGatewayPort != "" && c.GRPCPort == "" {
+		errs.Add("GRPC_GATEWAY_PORT needs the gRPC server, which GRPC_PORT=off turns off")
+	}
+	if c.DatabaseURL == "" {
+		errs.Add("DATABASE_URL must be set when APP_ENV is %q", c.Env)
+	} else if _, err := url.Parse(c.DatabaseURL); err != nil {
+		// The error would quote the URL, password included.
+		errs.Add("DATABASE_URL is not a valid URL")
+	}
+	if c.AppURL == "" {
+		errs.Add("APP_URL must be set when APP_ENV is %q, as account emails and sign-in redirects link to it", c.Env)
+	} else {
+		checkURL(&errs, "APP_URL", c.AppURL)
+	}
+	if c.SMTPAddr == "" && c.MailDir == "" && !c.IsDev() {
+		// The log fallback would write live reset and verification tokens
+		// to the production logs.
+		errs.Add("SMTP_ADDR or MAIL_DIR must be set when APP_ENV is %q, as the log fallback would expose account tokens", c.Env)
+	}
+	if c.AIServiceURL != "" {
+		checkURL(&errs, "AI_SERVICE_URL", c.AIServiceURL)
+	}
+
+	// JWT signing
+	switch c.JWTKeySource {
+	case KeySourceHMAC:
+		switch {
+		case c.IsDev():
+		case c.JWTSecret == DefaultJWTSecret:
+			errs.Add("JWT_SECRET must be set when APP_ENV is %q", c.Env)
+		case len(c.JWTSecret) < minJWTSecretLength:
+			errs.Add("JWT_SECRET must be at least %d bytes", minJWTSecretLength)
+		}
+	case KeySourceFile:
+		if c.JWTKeysDir == "" {
+			errs.Add("JWT_KEYS_DIR must be set when JWT_KEY_SOURCE is %q", c.JWTKeySource)
+		}
+	case KeySourceDB:
+	default:
+		errs.Add("JWT_KEY_SOURCE: %q is not one of db, file or hmac", c.JWTKeySource)
+	}
+	// Keys are only generated for the db source, in one of these algorithms.
+	if !slices.Contains([]string{"EdDSA", "RS256"}, c.JWTSigningAlg) {
+		errs.Add("JWT_SIGNING_ALG: %q is not EdDSA or RS256", c.JWTSigningAlg)
+	}
+	if c.AccessTokenTTL >= c.RefreshTokenTTL {
+		errs.Add("ACCESS_TOKEN_TTL (%s) must be shorter than REFRESH_TOKEN_TTL (%s)", c.AccessTokenTTL, c.RefreshTokenTTL)
+	}
+
+	// Durations that must be positive; intervals of background jobs may be
+	// zero to turn the job off.
+	for _, s := range []durationSetting{
+		{"ACCESS_TOKEN_TTL", c.AccessTokenTTL},
+		{"REFRESH_TOKEN_TTL", c.RefreshTokenTTL},
+		{"EMAIL_VERIFY_TTL", c.EmailVerifyTTL},
+		{"PASSWORD_RESET_TTL", c.PasswordResetTTL},
+		{"OIDC_STATE_TTL", c.OIDCStateTTL},
+		{"LOGIN_LOCKOUT_BASE", c.LoginLockoutBase},
+		{"LOGIN_LOCKOUT_MAX", c.LoginLockoutMax},
+		{"LOGIN_FAILURE_WINDOW", c.LoginFailureWindow},
+		{"HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout},
+		{"SHUTDOWN_TIMEOUT", c.ShutdownTimeout},
+	} {
+		if s.value <= 0 {
+			errs.Add("%s must be positive", s.key)
+		}
+	}
+	for _, s := range []durationSetting{
+		{"POPULARITY_REFRESH_INTERVAL", c.PopularityRefreshInterval},
+		{"SESSION_PRUNE_INTERVAL", c.SessionPruneInterval},
+		{"JWT_KEY_ROTATION_INTERVAL", c.JWTKeyRotation},
+		{"JWT_KEY_RELOAD_INTERVAL", c.JWTKeyReload},
+		{"AI_HEALTH_CACHE_TTL", c.AIHealthCacheTTL},
+		{"SHUTDOWN_DRAIN_DELAY", c.ShutdownDrainDelay},
+	} {
+		if s.value < 0 {
+			errs.Add("%s must not be negative", s.key)
+		}
+	}
+
+	if c.MaxImageSizeMB <= 0 {
+		errs.Add("MAX_IMAGE_SIZE_MB must be positive")
+	}
+	if c.HTTPMaxHeaderBytes <= 0 {
+		errs.Add("HTTP_MAX_HEADER_BYTES must be positive")
+	}
+	if c.LoginMaxFailures <= 0 || c.LoginIPMaxFailures <= 0 {
+		errs.Add("LOGIN_MAX_FAILURES and LOGIN_IP_MAX_FAILURES must be positive")
+	}
+
+	if !slices.Contains([]string{AttemptStoreDB, AttemptStoreMemory}, c.LoginAttemptStore) {
+		errs.Add("LOGIN_ATTEMPT_STORE: %q is not db or memory", c.LoginAttemptStore)
+	}
+	if !slices.Contains([]string{RateLimitStoreDB, RateLimitStoreMemory}, c.RateLimitStore) {
+		errs.Add("RATE_LIMIT_STORE: %q is not db or memory", c.RateLimitStore)
+	}
+
+	// Browsers refuse credentialed responses that allow any origin, and
+	// reflecting every origin instead would let any site act as the user.
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			if c.CORSAllowCredentials {
+				errs.Add("ALLOWED_ORIGINS: \"*\" cannot be combined with CORS_ALLOW_CREDENTIALS=true; list the origins or turn credentials off")
+			}
+			continue
+		}
+		if err := checkOrigin(origin); err != nil {
+			errs.Add("ALLOWED_ORIGINS: %q %v", origin, err)
+		}
+	}
+	if c.CORSMaxAge < 0 {
+		errs.Add("CORS_MAX_AGE must not be negative")
+	}
+
+	for _, p := range c.OIDCProviders {
+		if p.Issuer == "" || p.ClientID == "" {
+			errs.Add("OIDC provider %q needs OIDC_%s_ISSUER and OIDC_%s_CLIENT_ID", p.Name, settingKey(p.Name), settingKey(p.Name))
+		}
+	}
+
+	// Logging and tracing
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		errs.Add("LOG_LEVEL: %q is not debug, info, warn or error", c.LogLevel)
+	}
+	if c.LogFormat != LogFormatJSON && c.LogFormat != LogFormatText {
+		errs.Add("LOG_FORMAT: %q is not json or text", c.LogFormat)
+	}
+	switch c.TracesExporter {
+	case TraceExporterNone, TraceExporterConsole:
+	case TraceExporterOTLP:
+		checkURL(&errs, "OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint)
+	default:
+		errs.Add("OTEL_TRACES_EXPORTER: %q is not otlp, console or none", c.TracesExporter)
+	}
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		errs.Add("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1")
+	}
+
+	return errs
+}
+
+// checkPort reports v unless it is a port number.
+func checkPort(errs *Errors, key, v string) {
+	if port, err := strconv.Atoi(v); err != nil || port < 1 || port > 65535 {
+		errs.Add("%s: %q is not a port number", key, v)
+	}
+}
+
+// checkURL reports v unless it is an absolute http or https URL.
+func checkURL(errs *Errors, key, v string) {
+	u, err := url.Parse(v)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.Add("%s: %q is not an http or https URL", key, v)
+	}
+}
+
+// checkOrigin accepts an origin, scheme://host[:port], in which one
+// wildcard may stand for the subdomains (https://*.example.com) or the port
+// (http://localhost:*).
+func checkOrigin(origin string) error {
+	scheme, hostport, ok := strings.Cut(origin, "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return fmt.Errorf("must start with http:// or https://")
+	}
+	if hostport == "" || strings.ContainsAny(hostport, "/?#@") {
+		return fmt.Errorf("must be scheme://host[:port], with no path")
+	}
+	switch strings.Count(hostport, "*") {
+	case 0:
+		return nil
+	case 1:
+		host, port, hasPort := strings.Cut(hostport, ":")
+		// The wildcard must leave a registrable domain, so that
+		// https://*.com cannot allow every site.
+		if domain, ok := strings.CutPrefix(host, "*."); ok && !strings.Contains(domain, "*") && strings.Contains(domain, ".") {
+			return nil
+		}
+		if hasPort && port == "*" {
+			return nil
+		}
+	}
+	return fmt.Errorf("may only use one wildcard, for the subdomains (https://*.example.com) or the port (http://localhost:*)")
+}
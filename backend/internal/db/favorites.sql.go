@@ -8,6 +8,8 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
 const addFavorite = `-- name: AddFavorite :one
@@ -52,6 +54,40 @@ func (q *Queries) IsFavorite(ctx context.Context, arg IsFavoriteParams) (bool, e
 	return is_favorite, err
 }
 
+const listFavoritedRecipeIDs = `-- name: ListFavoritedRecipeIDs :many
+SELECT recipe_id FROM favorites
+WHERE user_id = $1 AND recipe_id = ANY($2::int[])
+`
+
+type ListFavoritedRecipeIDsParams struct {
+	UserID    sql.NullInt32 `json:"user_id"`
+	RecipeIds []int32       `json:"recipe_ids"`
+}
+
+// Which of the given recipes the user has favorited
+func (q *Queries) ListFavoritedRecipeIDs(ctx context.Context, arg ListFavoritedRecipeIDsParams) ([]sql.NullInt32, error) {
+	rows, err := q.db.QueryContext(ctx, listFavoritedRecipeIDs, arg.UserID, pq.Array(arg.RecipeIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullInt32
+	for rows.Next() {
+		var recipe_id sql.NullInt32
+		if err := rows.Scan(&recipe_id); err != nil {
+			return nil, err
+		}
+		items = append(items, recipe_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFavoritesByUser = `-- name: ListFavoritesByUser :many
 SELECT f.id as favorite_id, f.user_id, f.recipe_id, f.created_at, 
   r.title, r.description, r.cuisine, r.difficulty, r.diet_type, 
@@ -118,6 +154,84 @@ func (q *Queries) ListFavoritesByUser(ctx context.Context, userID sql.NullInt32)
 	return items, nil
 }
 
+const listFavoritesByUserPage = `-- name: ListFavoritesByUserPage :many
+SELECT f.id as favorite_id, f.user_id, f.recipe_id, f.created_at, 
+  r.title, r.description, r.cuisine, r.difficulty, r.diet_type, 
+  r.prep_time_minutes, r.cook_time_minutes, r.total_time_minutes, r.servings,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1) FROM ratings WHERE recipe_id = r.id)::text, '0') as average_rating,
+  (SELECT COUNT(*) FROM favorites c WHERE c.user_id = $1)::integer AS total_count
+FROM favorites f
+JOIN recipes r ON r.id = f.recipe_id
+WHERE f.user_id = $1
+  AND ($2::int = 0 OR f.id < $2::int)
+ORDER BY f.id DESC
+LIMIT $3
+`
+
+type ListFavoritesByUserPageParams struct {
+	UserID   sql.NullInt32 `json:"user_id"`
+	CursorID int32         `json:"cursor_id"`
+	Limit    int32         `json:"limit"`
+}
+
+type ListFavoritesByUserPageRow struct {
+	FavoriteID       int32          `json:"favorite_id"`
+	UserID           sql.NullInt32  `json:"user_id"`
+	RecipeID         sql.NullInt32  `json:"recipe_id"`
+	CreatedAt        sql.NullTime   `json:"created_at"`
+	Title            string         `json:"title"`
+	Description      sql.NullString `json:"description"`
+	Cuisine          sql.NullString `json:"cuisine"`
+	Difficulty       sql.NullString `json:"difficulty"`
+	DietType         sql.NullString `json:"diet_type"`
+	PrepTimeMinutes  sql.NullInt32  `json:"prep_time_minutes"`
+	CookTimeMinutes  sql.NullInt32  `json:"cook_time_minutes"`
+	TotalTimeMinutes sql.NullInt32  `json:"total_time_minutes"`
+	Servings         sql.NullInt32  `json:"servings"`
+	AverageRating    interface{}    `json:"average_rating"`
+	TotalCount       int32          `json:"total_count"`
+}
+
+// Keyset page of a user's favorites, newest first; cursor_id 0 starts at the beginning
+func (q *Queries) ListFavoritesByUserPage(ctx context.Context, arg ListFavoritesByUserPageParams) ([]ListFavoritesByUserPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFavoritesByUserPage, arg.UserID, arg.CursorID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFavoritesByUserPageRow
+	for rows.Next() {
+		var i ListFavoritesByUserPageRow
+		if err := rows.Scan(
+			&i.FavoriteID,
+			&i.UserID,
+			&i.RecipeID,
+			&i.CreatedAt,
+			&i.Title,
+			&i.Description,
+			&i.Cuisine,
+			&i.Difficulty,
+			&i.DietType,
+			&i.PrepTimeMinutes,
+			&i.CookTimeMinutes,
+			&i.TotalTimeMinutes,
+			&i.Servings,
+			&i.AverageRating,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const removeFavorite = `-- name: RemoveFavorite :exec
 DELETE FROM favorites WHERE user_id = $1 AND recipe_id = $2
 `
@@ -6,10 +6,43 @@ package db
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/sqlc-dev/pqtype"
 )
 
+type AccountToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	Purpose   string       `json:"purpose"`
+	TokenHash string       `json:"token_hash"`
+	CreatedAt time.Time    `json:"created_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+}
+
+type ApiKey struct {
+	ID         int32        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"key_hash"`
+	Scopes     []string     `json:"scopes"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+}
+
+type AuditEvent struct {
+	ID        int32                 `json:"id"`
+	Event     string                `json:"event"`
+	UserID    sql.NullInt32         `json:"user_id"`
+	Subject   sql.NullString        `json:"subject"`
+	IpAddress sql.NullString        `json:"ip_address"`
+	Details   pqtype.NullRawMessage `json:"details"`
+	CreatedAt time.Time             `json:"created_at"`
+}
+
 type Favorite struct {
 	ID        int32         `json:"id"`
 	UserID    sql.NullInt32 `json:"user_id"`
@@ -17,6 +50,34 @@ type Favorite struct {
 	CreatedAt sql.NullTime  `json:"created_at"`
 }
 
+type IngredientLexicon struct {
+	Variant   string    `json:"variant"`
+	Canonical string    `json:"canonical"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LoginAttempt struct {
+	Key           string       `json:"key"`
+	Failures      int32        `json:"failures"`
+	LastFailureAt time.Time    `json:"last_failure_at"`
+	LockedUntil   sql.NullTime `json:"locked_until"`
+}
+
+type OidcLoginState struct {
+	StateHash    string    `json:"state_hash"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Allowed   bool      `json:"allowed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 type Rating struct {
 	ID        int32         `json:"id"`
 	UserID    sql.NullInt32 `json:"user_id"`
@@ -42,12 +103,77 @@ type Recipe struct {
 	DietType         sql.NullString        `json:"diet_type"`
 	PrepTimeMinutes  sql.NullInt32         `json:"prep_time_minutes"`
 	TotalTimeMinutes sql.NullInt32         `json:"total_time_minutes"`
+	SearchVector     interface{}           `json:"search_vector"`
+	AuthorID         sql.NullInt32         `json:"author_id"`
+}
+
+type RecipePopularity struct {
+	RecipeID       int32     `json:"recipe_id"`
+	TimeWindow     string    `json:"time_window"`
+	FavoritesCount int32     `json:"favorites_count"`
+	RatingsCount   int32     `json:"ratings_count"`
+	ViewsCount     int32     `json:"views_count"`
+	PopularScore   float64   `json:"popular_score"`
+	TrendingScore  float64   `json:"trending_score"`
+	RefreshedAt    time.Time `json:"refreshed_at"`
+}
+
+type RecipeView struct {
+	ID       int32          `json:"id"`
+	RecipeID sql.NullInt32  `json:"recipe_id"`
+	UserID   sql.NullInt32  `json:"user_id"`
+	ViewedAt sql.NullTime   `json:"viewed_at"`
+	Viewer   sql.NullString `json:"viewer"`
+}
+
+type RevokedToken struct {
+	Jti       string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type Session struct {
+	ID                int32          `json:"id"`
+	UserID            int32          `json:"user_id"`
+	RefreshTokenHash  string         `json:"refresh_token_hash"`
+	PreviousTokenHash sql.NullString `json:"previous_token_hash"`
+	AccessJti         string         `json:"access_jti"`
+	AccessExpiresAt   time.Time      `json:"access_expires_at"`
+	UserAgent         sql.NullString `json:"user_agent"`
+	IpAddress         sql.NullString `json:"ip_address"`
+	CreatedAt         time.Time      `json:"created_at"`
+	LastUsedAt        time.Time      `json:"last_used_at"`
+	ExpiresAt         time.Time      `json:"expires_at"`
+	RevokedAt         sql.NullTime   `json:"revoked_at"`
+}
+
+type SigningKey struct {
+	Kid           string         `json:"kid"`
+	Algorithm     string         `json:"algorithm"`
+	PrivateKeyPem sql.NullString `json:"private_key_pem"`
+	PublicKeyPem  string         `json:"public_key_pem"`
+	CreatedAt     time.Time      `json:"created_at"`
+	RetiredAt     sql.NullTime   `json:"retired_at"`
 }
 
 type User struct {
-	ID           int32          `json:"id"`
-	Username     sql.NullString `json:"username"`
-	CreatedAt    sql.NullTime   `json:"created_at"`
-	Email        sql.NullString `json:"email"`
-	PasswordHash sql.NullString `json:"password_hash"`
+	ID              int32          `json:"id"`
+	Username        sql.NullString `json:"username"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	Email           sql.NullString `json:"email"`
+	PasswordHash    sql.NullString `json:"password_hash"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+	Role            string         `json:"role"`
+	DisplayName     sql.NullString `json:"display_name"`
+	AvatarUrl       sql.NullString `json:"avatar_url"`
+	PendingEmail    sql.NullString `json:"pending_email"`
+}
+
+type UserIdentity struct {
+	ID          int32          `json:"id"`
+	UserID      int32          `json:"user_id"`
+	Provider    string         `json:"provider"`
+	Subject     string         `json:"subject"`
+	Email       sql.NullString `json:"email"`
+	CreatedAt   time.Time      `json:"created_at"`
+	LastLoginAt time.Time      `json:"last_login_at"`
 }
@@ -13,9 +13,156 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+const countRecipeFacets = `-- name: CountRecipeFacets :many
+WITH matched AS (
+  SELECT recipes.id, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.tags,
+    recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.servings,
+    COALESCE(NULLIF(recipes.total_time_minutes, 0), COALESCE(recipes.prep_time_minutes, 0) + recipes.cook_time_minutes, recipes.prep_time_minutes) AS total_time
+  FROM recipes
+  WHERE CASE
+    WHEN $1::text <> '' THEN recipes.search_vector @@ to_tsquery('english', $1::text)
+    ELSE recipes.title ILIKE '%' || $2::text || '%' OR $2::text = ANY(recipes.tags)
+  END
+), checked AS (
+  SELECT m.*,
+    COALESCE(cardinality($3::text[]) = 0
+      OR lower(trim(m.diet_type)) = ANY($3::text[])
+      OR EXISTS (SELECT 1 FROM unnest(m.tags) t WHERE lower(trim(t)) = ANY($3::text[])), false) AS diet_ok,
+    COALESCE(cardinality($4::text[]) = 0 OR lower(trim(m.difficulty)) = ANY($4::text[]), false) AS difficulty_ok,
+    COALESCE(cardinality($5::text[]) = 0 OR lower(trim(m.cuisine)) = ANY($5::text[]), false) AS cuisine_ok,
+    COALESCE(cardinality($6::text[]) = 0
+      OR EXISTS (SELECT 1 FROM unnest(m.tags) t WHERE lower(trim(t)) = ANY($6::text[])), false) AS tag_ok,
+    COALESCE($7::int IS NULL OR m.total_time <= $7::int, false) AS total_ok,
+    COALESCE($8::int IS NULL OR m.cook_time_minutes <= $8::int, false) AS cook_ok,
+    COALESCE(($9::int IS NULL OR m.prep_time_minutes <= $9::int)
+      AND ($10::int IS NULL OR m.servings >= $10::int)
+      AND ($11::int IS NULL OR m.servings <= $11::int), false) AS rest_ok
+  FROM matched m
+), diet_seeds AS (
+  SELECT c.id, lower(trim(c.diet_type)) AS diet
+  FROM checked c
+  WHERE c.rest_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_ok AND trim(c.diet_type) <> ''
+  UNION
+  SELECT c.id, lower(trim(t))
+  FROM checked c, unnest(c.tags) t
+  WHERE c.rest_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_ok
+    AND lower(trim(t)) = ANY($12::text[])
+)
+SELECT 'cuisine'::text AS facet, MIN(trim(cuisine))::text AS value, COUNT(*) AS count
+FROM checked
+WHERE rest_ok AND diet_ok AND difficulty_ok AND tag_ok AND total_ok AND cook_ok AND trim(cuisine) <> ''
+GROUP BY lower(trim(cuisine))
+UNION ALL
+SELECT 'difficulty', MIN(trim(difficulty))::text, COUNT(*)
+FROM checked
+WHERE rest_ok AND diet_ok AND cuisine_ok AND tag_ok AND total_ok AND cook_ok AND trim(difficulty) <> ''
+GROUP BY lower(trim(difficulty))
+UNION ALL
+SELECT 'diet_type', d.diet, COUNT(DISTINCT d.id)
+FROM (
+  SELECT id, diet FROM diet_seeds
+  UNION
+  SELECT s.id, i.implied
+  FROM diet_seeds s
+  JOIN unnest($13::text[], $14::text[]) AS i(diet, implied) ON i.diet = s.diet
+) d
+GROUP BY d.diet
+UNION ALL
+SELECT 'tag', MIN(trim(t))::text, COUNT(DISTINCT c.id)
+FROM checked c, unnest(c.tags) t
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.total_ok AND c.cook_ok AND trim(t) <> ''
+GROUP BY lower(trim(t))
+UNION ALL
+SELECT 'cook_time', b.bucket, COUNT(*)
+FROM checked c, LATERAL (SELECT CASE
+    WHEN c.cook_time_minutes < 15 THEN '<15'
+    WHEN c.cook_time_minutes < 30 THEN '15-30'
+    WHEN c.cook_time_minutes < 60 THEN '30-60'
+    ELSE '60+'
+  END::text AS bucket) b
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_time_minutes IS NOT NULL
+GROUP BY b.bucket
+UNION ALL
+SELECT 'total_time', b.bucket, COUNT(*)
+FROM checked c, LATERAL (SELECT CASE
+    WHEN c.total_time < 15 THEN '<15'
+    WHEN c.total_time < 30 THEN '15-30'
+    WHEN c.total_time < 60 THEN '30-60'
+    ELSE '60+'
+  END::text AS bucket) b
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.cook_ok AND c.total_time IS NOT NULL
+GROUP BY b.bucket
+`
+
+type CountRecipeFacetsParams struct {
+	Tsquery      string        `json:"tsquery"`
+	Query        string        `json:"query"`
+	Diets        []string      `json:"diets"`
+	Difficulties []string      `json:"difficulties"`
+	Cuisines     []string      `json:"cuisines"`
+	Tags         []string      `json:"tags"`
+	MaxTotalTime sql.NullInt32 `json:"max_total_time"`
+	MaxCookTime  sql.NullInt32 `json:"max_cook_time"`
+	MaxPrepTime  sql.NullInt32 `json:"max_prep_time"`
+	MinServings  sql.NullInt32 `json:"min_servings"`
+	MaxServings  sql.NullInt32 `json:"max_servings"`
+	KnownDiets   []string      `json:"known_diets"`
+	ImpliedFrom  []string      `json:"implied_from"`
+	ImpliedTo    []string      `json:"implied_to"`
+}
+
+type CountRecipeFacetsRow struct {
+	Facet string `json:"facet"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Facet counts of the recipes matching a search, as (facet, value, count)
+// rows. Each facet counts the recipes passing every filter but its own,
+// grouped case-insensitively. Diets count under the diet_type, the tags
+// naming one of known_diets and every diet those imply (implied_from[i]
+// implies implied_to[i]). The time buckets are those of timeBuckets.
+func (q *Queries) CountRecipeFacets(ctx context.Context, arg CountRecipeFacetsParams) ([]CountRecipeFacetsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countRecipeFacets,
+		arg.Tsquery,
+		arg.Query,
+		pq.Array(arg.Diets),
+		pq.Array(arg.Difficulties),
+		pq.Array(arg.Cuisines),
+		pq.Array(arg.Tags),
+		arg.MaxTotalTime,
+		arg.MaxCookTime,
+		arg.MaxPrepTime,
+		arg.MinServings,
+		arg.MaxServings,
+		pq.Array(arg.KnownDiets),
+		pq.Array(arg.ImpliedFrom),
+		pq.Array(arg.ImpliedTo),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRecipeFacetsRow
+	for rows.Next() {
+		var i CountRecipeFacetsRow
+		if err := rows.Scan(&i.Facet, &i.Value, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createRecipe = `-- name: CreateRecipe :one
-INSERT INTO recipes (title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, tags, ingredients, steps, nutrition)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+INSERT INTO recipes (title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, tags, ingredients, steps, nutrition, author_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id
 `
 
@@ -33,6 +180,7 @@ type CreateRecipeParams struct {
 	Ingredients      pqtype.NullRawMessage `json:"ingredients"`
 	Steps            pqtype.NullRawMessage `json:"steps"`
 	Nutrition        pqtype.NullRawMessage `json:"nutrition"`
+	AuthorID         sql.NullInt32         `json:"author_id"`
 }
 
 func (q *Queries) CreateRecipe(ctx context.Context, arg CreateRecipeParams) (int32, error) {
@@ -50,12 +198,206 @@ func (q *Queries) CreateRecipe(ctx context.Context, arg CreateRecipeParams) (int
 		arg.Ingredients,
 		arg.Steps,
 		arg.Nutrition,
+		arg.AuthorID,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
+const deleteRating = `-- name: DeleteRating :execrows
+DELETE FROM ratings WHERE id = $1
+`
+
+func (q *Queries) DeleteRating(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRating, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRecipe = `-- name: DeleteRecipe :execrows
+DELETE FROM recipes WHERE id = $1
+`
+
+func (q *Queries) DeleteRecipe(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRecipe, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const filterRecipes = `-- name: FilterRecipes :many
+WITH filtered AS (
+  SELECT recipes.id, recipes.title, recipes.description, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.total_time_minutes, recipes.servings, recipes.ingredients, recipes.steps, recipes.nutrition, recipes.tags, recipes.search_vector,
+    (CASE
+      WHEN $1::text = 'rating' THEN COALESCE((SELECT AVG(rating)::float8 FROM ratings r WHERE r.recipe_id = recipes.id), 0)
+      WHEN $1::text = 'newest' THEN COALESCE(EXTRACT(EPOCH FROM recipes.created_at)::float8, 0)
+      WHEN $1::text = 'quickest' THEN -COALESCE(NULLIF(recipes.total_time_minutes, 0), recipes.prep_time_minutes + recipes.cook_time_minutes, recipes.cook_time_minutes, recipes.prep_time_minutes, 1000000)::float8
+      WHEN $1::text = 'popular' THEN COALESCE((SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all'), 0)
+      WHEN $1::text = 'match' THEN (
+        (SELECT COUNT(*) FROM unnest(recipes.tags) t WHERE lower(t) = ANY($2::text[]))
+        + (SELECT COUNT(*) FROM unnest($2::text[]) m WHERE strpos(lower(recipes.title), m) > 0))::float8
+      WHEN $1::text = 'suggestions' THEN COALESCE((SELECT SUM(w.weight) FROM unnest(recipes.tags) t
+        JOIN unnest($3::text[], $4::int[]) AS w(tag, weight) ON lower(t) = w.tag), 0)::float8
+      WHEN $5::text <> '' THEN ts_rank(recipes.search_vector, to_tsquery('english', $5::text), 32)::float8
+      ELSE -recipes.id::float8
+    END)::float8 AS sort_key
+  FROM recipes
+  WHERE (CASE
+      WHEN $5::text <> '' THEN recipes.search_vector @@ to_tsquery('english', $5::text)
+      ELSE recipes.title ILIKE '%' || $6::text || '%' OR $6::text = ANY(recipes.tags)
+    END)
+    AND (cardinality($7::text[]) = 0
+      OR lower(trim(recipes.diet_type)) = ANY($7::text[])
+      OR EXISTS (SELECT 1 FROM unnest(recipes.tags) t WHERE lower(trim(t)) = ANY($7::text[])))
+    AND (cardinality($8::text[]) = 0 OR lower(trim(recipes.difficulty)) = ANY($8::text[]))
+    AND (cardinality($9::text[]) = 0 OR lower(trim(recipes.cuisine)) = ANY($9::text[]))
+    AND (cardinality($10::text[]) = 0
+      OR EXISTS (SELECT 1 FROM unnest(recipes.tags) t WHERE lower(trim(t)) = ANY($10::text[])))
+    AND ($11::int IS NULL
+      OR COALESCE(NULLIF(recipes.total_time_minutes, 0), COALESCE(recipes.prep_time_minutes, 0) + recipes.cook_time_minutes, recipes.prep_time_minutes) <= $11::int)
+    AND ($12::int IS NULL OR recipes.prep_time_minutes <= $12::int)
+    AND ($13::int IS NULL OR recipes.cook_time_minutes <= $13::int)
+    AND ($14::int IS NULL OR recipes.servings >= $14::int)
+    AND ($15::int IS NULL OR recipes.servings <= $15::int)
+), scored AS (
+  SELECT * FROM filtered
+  WHERE $16::float8 IS NULL OR filtered.sort_key >= $16::float8
+)
+SELECT f.id, f.title, f.description, f.cuisine, f.difficulty, f.diet_type, f.prep_time_minutes, f.cook_time_minutes, f.total_time_minutes, f.servings, f.ingredients, f.steps, f.nutrition, f.tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = f.id), '0') as average_rating,
+  f.sort_key,
+  (CASE WHEN $5::text <> ''
+    THEN ts_rank(f.search_vector, to_tsquery('english', $5::text), 32)::float8
+    ELSE 0 END)::float8 AS rank,
+  (CASE WHEN $5::text <> ''
+    THEN ts_headline('english',
+      concat_ws(' … ', f.description, recipe_ingredient_names(f.ingredients), recipe_steps_text(f.steps)),
+      to_tsquery('english', $5::text),
+      'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "')
+    ELSE '' END)::text AS snippet,
+  (SELECT COUNT(*) FROM scored)::integer AS total_count
+FROM scored f
+WHERE $17::float8 IS NULL
+  OR f.sort_key < $17::float8
+  OR (f.sort_key = $17::float8 AND f.id > $18::int)
+ORDER BY f.sort_key DESC, f.id
+LIMIT $19
+`
+
+type FilterRecipesParams struct {
+	Sort         string          `json:"sort"`
+	MatchTerms   []string        `json:"match_terms"`
+	ScoreTags    []string        `json:"score_tags"`
+	ScoreWeights []int32         `json:"score_weights"`
+	Tsquery      string          `json:"tsquery"`
+	Query        string          `json:"query"`
+	Diets        []string        `json:"diets"`
+	Difficulties []string        `json:"difficulties"`
+	Cuisines     []string        `json:"cuisines"`
+	Tags         []string        `json:"tags"`
+	MaxTotalTime sql.NullInt32   `json:"max_total_time"`
+	MaxPrepTime  sql.NullInt32   `json:"max_prep_time"`
+	MaxCookTime  sql.NullInt32   `json:"max_cook_time"`
+	MinServings  sql.NullInt32   `json:"min_servings"`
+	MaxServings  sql.NullInt32   `json:"max_servings"`
+	MinScore     sql.NullFloat64 `json:"min_score"`
+	CursorKey    sql.NullFloat64 `json:"cursor_key"`
+	CursorID     int32           `json:"cursor_id"`
+	Limit        int32           `json:"limit"`
+}
+
+type FilterRecipesRow struct {
+	ID               int32                 `json:"id"`
+	Title            string                `json:"title"`
+	Description      sql.NullString        `json:"description"`
+	Cuisine          sql.NullString        `json:"cuisine"`
+	Difficulty       sql.NullString        `json:"difficulty"`
+	DietType         sql.NullString        `json:"diet_type"`
+	PrepTimeMinutes  sql.NullInt32         `json:"prep_time_minutes"`
+	CookTimeMinutes  sql.NullInt32         `json:"cook_time_minutes"`
+	TotalTimeMinutes sql.NullInt32         `json:"total_time_minutes"`
+	Servings         sql.NullInt32         `json:"servings"`
+	Ingredients      pqtype.NullRawMessage `json:"ingredients"`
+	Steps            pqtype.NullRawMessage `json:"steps"`
+	Nutrition        pqtype.NullRawMessage `json:"nutrition"`
+	Tags             []string              `json:"tags"`
+	AverageRating    interface{}           `json:"average_rating"`
+	SortKey          float64               `json:"sort_key"`
+	Rank             float64               `json:"rank"`
+	Snippet          string                `json:"snippet"`
+	TotalCount       int32                 `json:"total_count"`
+}
+
+// Search, filter and keyset-paginate recipes by (sort_key DESC, id); total_count ignores the cursor.
+// The match sort scores recipes by match_terms found in their tags and title, the suggestions sort
+// by the score_weights of their score_tags; recipes scoring below min_score are left out
+func (q *Queries) FilterRecipes(ctx context.Context, arg FilterRecipesParams) ([]FilterRecipesRow, error) {
+	rows, err := q.db.QueryContext(ctx, filterRecipes,
+		arg.Sort,
+		pq.Array(arg.MatchTerms),
+		pq.Array(arg.ScoreTags),
+		pq.Array(arg.ScoreWeights),
+		arg.Tsquery,
+		arg.Query,
+		pq.Array(arg.Diets),
+		pq.Array(arg.Difficulties),
+		pq.Array(arg.Cuisines),
+		pq.Array(arg.Tags),
+		arg.MaxTotalTime,
+		arg.MaxPrepTime,
+		arg.MaxCookTime,
+		arg.MinServings,
+		arg.MaxServings,
+		arg.MinScore,
+		arg.CursorKey,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterRecipesRow
+	for rows.Next() {
+		var i FilterRecipesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Cuisine,
+			&i.Difficulty,
+			&i.DietType,
+			&i.PrepTimeMinutes,
+			&i.CookTimeMinutes,
+			&i.TotalTimeMinutes,
+			&i.Servings,
+			&i.Ingredients,
+			&i.Steps,
+			&i.Nutrition,
+			pq.Array(&i.Tags),
+			&i.AverageRating,
+			&i.SortKey,
+			&i.Rank,
+			&i.Snippet,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRatingsForRecipe = `-- name: GetRatingsForRecipe :many
 SELECT id, user_id, recipe_id, rating, created_at
 FROM ratings
@@ -91,6 +433,17 @@ func (q *Queries) GetRatingsForRecipe(ctx context.Context, recipeID sql.NullInt3
 	return items, nil
 }
 
+const getRecipeAuthor = `-- name: GetRecipeAuthor :one
+SELECT author_id FROM recipes WHERE id = $1
+`
+
+func (q *Queries) GetRecipeAuthor(ctx context.Context, id int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getRecipeAuthor, id)
+	var author_id sql.NullInt32
+	err := row.Scan(&author_id)
+	return author_id, err
+}
+
 const getRecipeByID = `-- name: GetRecipeByID :one
 SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, ingredients, steps, nutrition, tags,
   COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
@@ -139,6 +492,71 @@ func (q *Queries) GetRecipeByID(ctx context.Context, id int32) (GetRecipeByIDRow
 	return i, err
 }
 
+const getRecipesByIDs = `-- name: GetRecipesByIDs :many
+SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, ingredients, steps, nutrition, tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
+FROM recipes
+WHERE recipes.id = ANY($1::int[])
+`
+
+type GetRecipesByIDsRow struct {
+	ID               int32                 `json:"id"`
+	Title            string                `json:"title"`
+	Description      sql.NullString        `json:"description"`
+	Cuisine          sql.NullString        `json:"cuisine"`
+	Difficulty       sql.NullString        `json:"difficulty"`
+	DietType         sql.NullString        `json:"diet_type"`
+	PrepTimeMinutes  sql.NullInt32         `json:"prep_time_minutes"`
+	CookTimeMinutes  sql.NullInt32         `json:"cook_time_minutes"`
+	TotalTimeMinutes sql.NullInt32         `json:"total_time_minutes"`
+	Servings         sql.NullInt32         `json:"servings"`
+	Ingredients      pqtype.NullRawMessage `json:"ingredients"`
+	Steps            pqtype.NullRawMessage `json:"steps"`
+	Nutrition        pqtype.NullRawMessage `json:"nutrition"`
+	Tags             []string              `json:"tags"`
+	AverageRating    interface{}           `json:"average_rating"`
+}
+
+// Recipes with the given IDs, in no particular order; unknown IDs are left out
+func (q *Queries) GetRecipesByIDs(ctx context.Context, ids []int32) ([]GetRecipesByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecipesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecipesByIDsRow
+	for rows.Next() {
+		var i GetRecipesByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Cuisine,
+			&i.Difficulty,
+			&i.DietType,
+			&i.PrepTimeMinutes,
+			&i.CookTimeMinutes,
+			&i.TotalTimeMinutes,
+			&i.Servings,
+			&i.Ingredients,
+			&i.Steps,
+			&i.Nutrition,
+			pq.Array(&i.Tags),
+			&i.AverageRating,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertRating = `-- name: InsertRating :one
 INSERT INTO ratings (user_id, recipe_id, rating)
 VALUES ($1, $2, $3)
@@ -164,6 +582,148 @@ func (q *Queries) InsertRating(ctx context.Context, arg InsertRatingParams) (Rat
 	return i, err
 }
 
+const listRatingsByRecipeIDs = `-- name: ListRatingsByRecipeIDs :many
+SELECT id, user_id, recipe_id, rating, created_at
+FROM ratings
+WHERE recipe_id = ANY($1::int[])
+ORDER BY recipe_id, created_at DESC, id DESC
+`
+
+// Ratings of several recipes, newest first within each recipe
+func (q *Queries) ListRatingsByRecipeIDs(ctx context.Context, recipeIds []int32) ([]Rating, error) {
+	rows, err := q.db.QueryContext(ctx, listRatingsByRecipeIDs, pq.Array(recipeIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Rating
+	for rows.Next() {
+		var i Rating
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RecipeID,
+			&i.Rating,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRatingsByUser = `-- name: ListRatingsByUser :many
+SELECT ra.id, ra.recipe_id, r.title AS recipe_title, ra.rating, ra.created_at
+FROM ratings ra
+LEFT JOIN recipes r ON r.id = ra.recipe_id
+WHERE ra.user_id = $1
+ORDER BY ra.created_at DESC, ra.id DESC
+`
+
+type ListRatingsByUserRow struct {
+	ID          int32          `json:"id"`
+	RecipeID    sql.NullInt32  `json:"recipe_id"`
+	RecipeTitle sql.NullString `json:"recipe_title"`
+	Rating      sql.NullInt32  `json:"rating"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) ListRatingsByUser(ctx context.Context, userID sql.NullInt32) ([]ListRatingsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRatingsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRatingsByUserRow
+	for rows.Next() {
+		var i ListRatingsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecipeID,
+			&i.RecipeTitle,
+			&i.Rating,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRatingsPage = `-- name: ListRatingsPage :many
+SELECT ratings.id, ratings.user_id, users.username, ratings.recipe_id, recipes.title AS recipe_title, ratings.rating, ratings.created_at,
+  (SELECT COUNT(*) FROM ratings)::integer AS total_count
+FROM ratings
+LEFT JOIN users ON users.id = ratings.user_id
+LEFT JOIN recipes ON recipes.id = ratings.recipe_id
+WHERE $1::int = 0 OR ratings.id < $1::int
+ORDER BY ratings.id DESC
+LIMIT $2
+`
+
+type ListRatingsPageParams struct {
+	BeforeID int32 `json:"before_id"`
+	Limit    int32 `json:"limit"`
+}
+
+type ListRatingsPageRow struct {
+	ID          int32          `json:"id"`
+	UserID      sql.NullInt32  `json:"user_id"`
+	Username    sql.NullString `json:"username"`
+	RecipeID    sql.NullInt32  `json:"recipe_id"`
+	RecipeTitle sql.NullString `json:"recipe_title"`
+	Rating      sql.NullInt32  `json:"rating"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+	TotalCount  int32          `json:"total_count"`
+}
+
+// Newest-first rating (review) listing for moderation, keyset-paginated by ID
+func (q *Queries) ListRatingsPage(ctx context.Context, arg ListRatingsPageParams) ([]ListRatingsPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRatingsPage, arg.BeforeID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRatingsPageRow
+	for rows.Next() {
+		var i ListRatingsPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.RecipeID,
+			&i.RecipeTitle,
+			&i.Rating,
+			&i.CreatedAt,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listRecipes = `-- name: ListRecipes :many
 SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings,
   COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
@@ -227,19 +787,67 @@ func (q *Queries) ListRecipes(ctx context.Context, arg ListRecipesParams) ([]Lis
 	return items, nil
 }
 
+const listRecipesByAuthor = `-- name: ListRecipesByAuthor :many
+SELECT id, title, created_at, updated_at
+FROM recipes
+WHERE author_id = $1
+ORDER BY id
+`
+
+type ListRecipesByAuthorRow struct {
+	ID        int32        `json:"id"`
+	Title     string       `json:"title"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+func (q *Queries) ListRecipesByAuthor(ctx context.Context, authorID sql.NullInt32) ([]ListRecipesByAuthorRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRecipesByAuthor, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRecipesByAuthorRow
+	for rows.Next() {
+		var i ListRecipesByAuthorRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchRecipes = `-- name: SearchRecipes :many
 SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, ingredients, steps, nutrition, tags,
   COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
 FROM recipes
-WHERE recipes.title ILIKE '%' || $1 || '%' OR $1 = ANY(recipes.tags)
-ORDER BY recipes.id
-LIMIT $2 OFFSET $3
+WHERE recipes.title ILIKE '%' || $1::text || '%' OR $1::text = ANY(recipes.tags)
+ORDER BY
+  CASE WHEN $2::text = 'rating' THEN (SELECT AVG(rating) FROM ratings r WHERE r.recipe_id = recipes.id) END DESC NULLS LAST,
+  CASE WHEN $2::text = 'newest' THEN recipes.created_at END DESC NULLS LAST,
+  CASE WHEN $2::text = 'quickest' THEN COALESCE(recipes.total_time_minutes, recipes.cook_time_minutes) END ASC NULLS LAST,
+  CASE WHEN $2::text = 'popular' THEN (SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all') END DESC NULLS LAST,
+  recipes.id
+LIMIT $3 OFFSET $4
 `
 
 type SearchRecipesParams struct {
-	Column1 sql.NullString `json:"column_1"`
-	Limit   int32          `json:"limit"`
-	Offset  int32          `json:"offset"`
+	Query  string `json:"query"`
+	Sort   string `json:"sort"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
 }
 
 type SearchRecipesRow struct {
@@ -260,9 +868,14 @@ type SearchRecipesRow struct {
 	AverageRating    interface{}           `json:"average_rating"`
 }
 
-// Simple search by title or tags
+// Simple search by title or tags, ordered by the requested sort key
 func (q *Queries) SearchRecipes(ctx context.Context, arg SearchRecipesParams) ([]SearchRecipesRow, error) {
-	rows, err := q.db.QueryContext(ctx, searchRecipes, arg.Column1, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, searchRecipes,
+		arg.Query,
+		arg.Sort,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -299,3 +912,160 @@ func (q *Queries) SearchRecipes(ctx context.Context, arg SearchRecipesParams) ([
 	}
 	return items, nil
 }
+
+const searchRecipesFullText = `-- name: SearchRecipesFullText :many
+SELECT recipes.id, recipes.title, recipes.description, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.total_time_minutes, recipes.servings, recipes.ingredients, recipes.steps, recipes.nutrition, recipes.tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating,
+  ts_rank(recipes.search_vector, tsq, 32)::float8 AS rank,
+  ts_headline('english',
+    concat_ws(' … ', recipes.description, recipe_ingredient_names(recipes.ingredients), recipe_steps_text(recipes.steps)),
+    tsq,
+    'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet
+FROM recipes, to_tsquery('english', $1::text) tsq
+WHERE recipes.search_vector @@ tsq
+ORDER BY
+  CASE WHEN $2::text = 'rating' THEN (SELECT AVG(rating) FROM ratings r WHERE r.recipe_id = recipes.id) END DESC NULLS LAST,
+  CASE WHEN $2::text = 'newest' THEN recipes.created_at END DESC NULLS LAST,
+  CASE WHEN $2::text = 'quickest' THEN COALESCE(recipes.total_time_minutes, recipes.cook_time_minutes) END ASC NULLS LAST,
+  CASE WHEN $2::text = 'popular' THEN (SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all') END DESC NULLS LAST,
+  rank DESC,
+  recipes.id
+LIMIT $3 OFFSET $4
+`
+
+type SearchRecipesFullTextParams struct {
+	Query  string `json:"query"`
+	Sort   string `json:"sort"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+type SearchRecipesFullTextRow struct {
+	ID               int32                 `json:"id"`
+	Title            string                `json:"title"`
+	Description      sql.NullString        `json:"description"`
+	Cuisine          sql.NullString        `json:"cuisine"`
+	Difficulty       sql.NullString        `json:"difficulty"`
+	DietType         sql.NullString        `json:"diet_type"`
+	PrepTimeMinutes  sql.NullInt32         `json:"prep_time_minutes"`
+	CookTimeMinutes  sql.NullInt32         `json:"cook_time_minutes"`
+	TotalTimeMinutes sql.NullInt32         `json:"total_time_minutes"`
+	Servings         sql.NullInt32         `json:"servings"`
+	Ingredients      pqtype.NullRawMessage `json:"ingredients"`
+	Steps            pqtype.NullRawMessage `json:"steps"`
+	Nutrition        pqtype.NullRawMessage `json:"nutrition"`
+	Tags             []string              `json:"tags"`
+	AverageRating    interface{}           `json:"average_rating"`
+	Rank             float64               `json:"rank"`
+	Snippet          string                `json:"snippet"`
+}
+
+// Ranked full-text search with highlighted snippets; query is a to_tsquery expression
+func (q *Queries) SearchRecipesFullText(ctx context.Context, arg SearchRecipesFullTextParams) ([]SearchRecipesFullTextRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchRecipesFullText,
+		arg.Query,
+		arg.Sort,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchRecipesFullTextRow
+	for rows.Next() {
+		var i SearchRecipesFullTextRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.Cuisine,
+			&i.Difficulty,
+			&i.DietType,
+			&i.PrepTimeMinutes,
+			&i.CookTimeMinutes,
+			&i.TotalTimeMinutes,
+			&i.Servings,
+			&i.Ingredients,
+			&i.Steps,
+			&i.Nutrition,
+			pq.Array(&i.Tags),
+			&i.AverageRating,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setRecipeAuthor = `-- name: SetRecipeAuthor :execrows
+UPDATE recipes SET author_id = $1 WHERE id = $2
+`
+
+type SetRecipeAuthorParams struct {
+	AuthorID sql.NullInt32 `json:"author_id"`
+	ID       int32         `json:"id"`
+}
+
+func (q *Queries) SetRecipeAuthor(ctx context.Context, arg SetRecipeAuthorParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setRecipeAuthor, arg.AuthorID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateRecipe = `-- name: UpdateRecipe :execrows
+UPDATE recipes
+SET title = $2, description = $3, cuisine = $4, difficulty = $5, diet_type = $6, prep_time_minutes = $7, cook_time_minutes = $8, total_time_minutes = $9, servings = $10, tags = $11, ingredients = $12, steps = $13, nutrition = $14, updated_at = now()
+WHERE id = $1
+`
+
+type UpdateRecipeParams struct {
+	ID               int32                 `json:"id"`
+	Title            string                `json:"title"`
+	Description      sql.NullString        `json:"description"`
+	Cuisine          sql.NullString        `json:"cuisine"`
+	Difficulty       sql.NullString        `json:"difficulty"`
+	DietType         sql.NullString        `json:"diet_type"`
+	PrepTimeMinutes  sql.NullInt32         `json:"prep_time_minutes"`
+	CookTimeMinutes  sql.NullInt32         `json:"cook_time_minutes"`
+	TotalTimeMinutes sql.NullInt32         `json:"total_time_minutes"`
+	Servings         sql.NullInt32         `json:"servings"`
+	Tags             []string              `json:"tags"`
+	Ingredients      pqtype.NullRawMessage `json:"ingredients"`
+	Steps            pqtype.NullRawMessage `json:"steps"`
+	Nutrition        pqtype.NullRawMessage `json:"nutrition"`
+}
+
+func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateRecipe,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Cuisine,
+		arg.Difficulty,
+		arg.DietType,
+		arg.PrepTimeMinutes,
+		arg.CookTimeMinutes,
+		arg.TotalTimeMinutes,
+		arg.Servings,
+		pq.Array(arg.Tags),
+		arg.Ingredients,
+		arg.Steps,
+		arg.Nutrition,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -8,8 +8,25 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/lib/pq"
 )
 
+const confirmEmailChange = `-- name: ConfirmEmailChange :one
+UPDATE users
+SET email = pending_email, pending_email = NULL, email_verified_at = now()
+WHERE id = $1 AND pending_email IS NOT NULL
+RETURNING email
+`
+
+// Swap in the pending address; following the link sent to it verified it
+func (q *Queries) ConfirmEmailChange(ctx context.Context, id int32) (sql.NullString, error) {
+	row := q.db.QueryRowContext(ctx, confirmEmailChange, id)
+	var email sql.NullString
+	err := row.Scan(&email)
+	return email, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, email, password_hash)
 VALUES ($1, $2, $3)
@@ -41,18 +58,33 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1
+`
+
+// Favorites, ratings, views, sessions, tokens, keys and identities cascade;
+// authored recipes and audit events are kept without the user
+func (q *Queries) DeleteUser(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at
+SELECT id, username, email, password_hash, created_at, email_verified_at
 FROM users
 WHERE email = $1
 `
 
 type GetUserByEmailRow struct {
-	ID           int32          `json:"id"`
-	Username     sql.NullString `json:"username"`
-	Email        sql.NullString `json:"email"`
-	PasswordHash sql.NullString `json:"password_hash"`
-	CreatedAt    sql.NullTime   `json:"created_at"`
+	ID              int32          `json:"id"`
+	Username        sql.NullString `json:"username"`
+	Email           sql.NullString `json:"email"`
+	PasswordHash    sql.NullString `json:"password_hash"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
 }
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (GetUserByEmailRow, error) {
@@ -64,6 +96,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (Get
 		&i.Email,
 		&i.PasswordHash,
 		&i.CreatedAt,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -92,3 +125,270 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (GetUserByIDRow, er
 	)
 	return i, err
 }
+
+const getUserCredentials = `-- name: GetUserCredentials :one
+SELECT id, email, password_hash, email_verified_at
+FROM users
+WHERE id = $1
+`
+
+type GetUserCredentialsRow struct {
+	ID              int32          `json:"id"`
+	Email           sql.NullString `json:"email"`
+	PasswordHash    sql.NullString `json:"password_hash"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+}
+
+func (q *Queries) GetUserCredentials(ctx context.Context, id int32) (GetUserCredentialsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserCredentials, id)
+	var i GetUserCredentialsRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.PasswordHash,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserProfile = `-- name: GetUserProfile :one
+SELECT id, username, email, pending_email, email_verified_at, display_name, avatar_url, role, created_at,
+  (password_hash IS NOT NULL)::boolean AS has_password
+FROM users
+WHERE id = $1
+`
+
+type GetUserProfileRow struct {
+	ID              int32          `json:"id"`
+	Username        sql.NullString `json:"username"`
+	Email           sql.NullString `json:"email"`
+	PendingEmail    sql.NullString `json:"pending_email"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+	DisplayName     sql.NullString `json:"display_name"`
+	AvatarUrl       sql.NullString `json:"avatar_url"`
+	Role            string         `json:"role"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	HasPassword     bool           `json:"has_password"`
+}
+
+func (q *Queries) GetUserProfile(ctx context.Context, id int32) (GetUserProfileRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfile, id)
+	var i GetUserProfileRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PendingEmail,
+		&i.EmailVerifiedAt,
+		&i.DisplayName,
+		&i.AvatarUrl,
+		&i.Role,
+		&i.CreatedAt,
+		&i.HasPassword,
+	)
+	return i, err
+}
+
+const getUserRole = `-- name: GetUserRole :one
+SELECT role FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserRole(ctx context.Context, id int32) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserRole, id)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, username, display_name, avatar_url, role, created_at
+FROM users
+WHERE id = ANY($1::int[])
+`
+
+type GetUsersByIDsRow struct {
+	ID          int32          `json:"id"`
+	Username    sql.NullString `json:"username"`
+	DisplayName sql.NullString `json:"display_name"`
+	AvatarUrl   sql.NullString `json:"avatar_url"`
+	Role        string         `json:"role"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+// Public profiles of several users; unknown IDs are left out
+func (q *Queries) GetUsersByIDs(ctx context.Context, ids []int32) ([]GetUsersByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersByIDsRow
+	for rows.Next() {
+		var i GetUsersByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.DisplayName,
+			&i.AvatarUrl,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersPage = `-- name: ListUsersPage :many
+SELECT id, username, email, role, email_verified_at, created_at,
+  (SELECT COUNT(*) FROM users)::integer AS total_count
+FROM users
+WHERE id > $1::int
+ORDER BY id
+LIMIT $2
+`
+
+type ListUsersPageParams struct {
+	AfterID int32 `json:"after_id"`
+	Limit   int32 `json:"limit"`
+}
+
+type ListUsersPageRow struct {
+	ID              int32          `json:"id"`
+	Username        sql.NullString `json:"username"`
+	Email           sql.NullString `json:"email"`
+	Role            string         `json:"role"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	TotalCount      int32          `json:"total_count"`
+}
+
+// Keyset-paginated user listing by ID; total_count ignores the cursor
+func (q *Queries) ListUsersPage(ctx context.Context, arg ListUsersPageParams) ([]ListUsersPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersPage, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsersPageRow
+	for rows.Next() {
+		var i ListUsersPageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.Role,
+			&i.EmailVerifiedAt,
+			&i.CreatedAt,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :exec
+UPDATE users SET email_verified_at = now() WHERE id = $1 AND email_verified_at IS NULL
+`
+
+func (q *Queries) MarkEmailVerified(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markEmailVerified, id)
+	return err
+}
+
+const setPendingEmail = `-- name: SetPendingEmail :exec
+UPDATE users SET pending_email = $2 WHERE id = $1
+`
+
+type SetPendingEmailParams struct {
+	ID           int32          `json:"id"`
+	PendingEmail sql.NullString `json:"pending_email"`
+}
+
+func (q *Queries) SetPendingEmail(ctx context.Context, arg SetPendingEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setPendingEmail, arg.ID, arg.PendingEmail)
+	return err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users SET password_hash = $2 WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID           int32          `json:"id"`
+	PasswordHash sql.NullString `json:"password_hash"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :exec
+UPDATE users SET username = $2, display_name = $3, avatar_url = $4 WHERE id = $1
+`
+
+type UpdateUserProfileParams struct {
+	ID          int32          `json:"id"`
+	Username    sql.NullString `json:"username"`
+	DisplayName sql.NullString `json:"display_name"`
+	AvatarUrl   sql.NullString `json:"avatar_url"`
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserProfile,
+		arg.ID,
+		arg.Username,
+		arg.DisplayName,
+		arg.AvatarUrl,
+	)
+	return err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = $1 WHERE id = $2
+RETURNING id, username, email, role, email_verified_at, created_at
+`
+
+type UpdateUserRoleParams struct {
+	Role string `json:"role"`
+	ID   int32  `json:"id"`
+}
+
+type UpdateUserRoleRow struct {
+	ID              int32          `json:"id"`
+	Username        sql.NullString `json:"username"`
+	Email           sql.NullString `json:"email"`
+	Role            string         `json:"role"`
+	EmailVerifiedAt sql.NullTime   `json:"email_verified_at"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (UpdateUserRoleRow, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRole, arg.Role, arg.ID)
+	var i UpdateUserRoleRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Role,
+		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// Error codes, sent as the "code" extension of GraphQL errors so clients can
+// tell them apart without parsing messages.
+const (
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeBadUserInput    = "BAD_USER_INPUT"
+	CodeInternal        = "INTERNAL"
+)
+
+// Error is an error a resolver reports to the client. Any other error is
+// logged and reported as an internal error, so that database and other
+// server failures are not disclosed.
+type Error struct {
+	Code    string
+	Message string
+	// Fields lists the invalid arguments of a BAD_USER_INPUT error, in the
+	// same form as the REST API's validation errors.
+	Fields validate.Errors
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions implements gqlerrors.ExtendedError.
+func (e *Error) Extensions() map[string]any {
+	ext := map[string]any{"code": e.Code}
+	if len(e.Fields) > 0 {
+		ext["fields"] = e.Fields
+	}
+	return ext
+}
+
+var (
+	errUnauthenticated = &Error{Code: CodeUnauthenticated, Message: "unauthorized"}
+	errRecipeNotFound  = &Error{Code: CodeNotFound, Message: "recipe not found"}
+	errInternal        = &Error{Code: CodeInternal, Message: "server error"}
+)
+
+// forbidden reports a missing API key scope.
+func forbidden(message string) error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+// badInput reports invalid arguments.
+func badInput(message string, fields validate.Errors) error {
+	return &Error{Code: CodeBadUserInput, Message: message, Fields: fields}
+}
+
+// publicError maps err, returned while resolving field, to the error the
+// client sees: errors meant for it pass through, service errors with a
+// REST status get the matching code, and anything else is logged and
+// hidden behind errInternal.
+func publicError(ctx context.Context, field string, err error) error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	var invalid validate.Errors
+	switch {
+	case errors.As(err, &invalid):
+		return badInput("validation failed", invalid)
+	case errors.Is(err, service.ErrInvalidCursor):
+		return badInput("invalid cursor", validate.Errors{{Field: "after", Message: "is not a cursor of this query"}})
+	case errors.Is(err, service.ErrRecipeNotFound):
+		return errRecipeNotFound
+	case errors.Is(err, service.ErrForbidden):
+		return forbidden(err.Error())
+	}
+	logging.FromContext(ctx).Error("graphql resolver failed", slog.String("field", field), slog.Any("error", err))
+	return errInternal
+}
@@ -0,0 +1,116 @@
+// Package graph serves the GraphQL API at /graphql, a single endpoint over
+// the same service as the REST API for clients that want to fetch a recipe
+// with its ratings, or a page of matches with the caller's favorite status,
+// in one round trip.
+//
+// Callers authenticate as for REST, with an access token or an API key.
+// Public fields need no credentials; fields of the caller's own data check
+// them, and API key scopes, as they are resolved.
+package graph
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// Handler serves GraphQL requests.
+type Handler struct {
+	service *service.Service
+	schema  graphql.Schema
+}
+
+// New returns a Handler resolving through s.
+func New(s *service.Service) (*Handler, error) {
+	schema, err := newSchema(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Handler{service: s, schema: schema}, nil
+}
+
+// Request is a GraphQL request, as the body of a POST or the query
+// parameters of a GET, where variables is JSON text.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP handles GET and POST /graphql.
+//
+// Returns: 200 OK with {"data": ..., "errors": [...]}, where each error
+// has a "code" extension (see the Code constants); 400 for a request
+// without a query or with malformed variables, and 405 for a mutation sent
+// with GET, which must not change anything
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				apierror.Write(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	default:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, http.StatusBadRequest, "bad request")
+			return
+		}
+	}
+	if req.Query == "" {
+		apierror.Write(w, http.StatusBadRequest, "query is required")
+		return
+	}
+	if r.Method == http.MethodGet && operationType(req.Query, req.OperationName) == ast.OperationTypeMutation {
+		w.Header().Set("Allow", http.MethodPost)
+		apierror.Write(w, http.StatusMethodNotAllowed, "mutations must be sent with POST")
+		return
+	}
+
+	var userID int
+	if caller := principal(r.Context()); caller != nil {
+		userID = caller.UserID
+	}
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(r.Context(), newLoaders(h.service, userID)),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// operationType returns the type of the operation of query that would run,
+// or "" if query does not parse or has no such operation; execution then
+// reports the problem.
+func operationType(query, name string) string {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return ""
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" || (op.Name != nil && op.Name.Value == name) {
+			return op.Operation
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// loader batches lookups by key within one request.
+//
+// load queues a key and returns a thunk. The first thunk called fetches
+// every key queued so far in a single call, and later thunks find their
+// value already there. The executor resolves a whole level of the query,
+// such as the ratings of every recipe in a page, before calling any of the
+// thunks it returned, so that level costs one query instead of one per
+// recipe. Values are cached for the rest of the request.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	fetched map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		queued:  map[K]bool{},
+		fetched: map[K]bool{},
+		values:  map[K]V{},
+		errs:    map[K]error{},
+	}
+}
+
+// load queues key and returns a thunk yielding its value, whether fetch
+// found one, and the error of the fetch.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	if !l.fetched[key] && !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !l.fetched[key] {
+			l.dispatch(ctx)
+		}
+		v, ok := l.values[key]
+		return v, ok, l.errs[key]
+	}
+}
+
+// dispatch fetches the pending keys. The caller holds l.mu.
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(ctx, keys)
+	for _, k := range keys {
+		delete(l.queued, k)
+		l.fetched[k] = true
+		if err != nil {
+			l.errs[k] = err
+		} else if v, ok := values[k]; ok {
+			l.values[k] = v
+		}
+	}
+}
+
+// loaders are the loaders of one request.
+type loaders struct {
+	recipes *loader[int, recipe]
+	ratings *loader[int, []db.Rating]
+	users   *loader[int, db.GetUsersByIDsRow]
+	// favorites reports which recipes the caller has favorited; nil for
+	// anonymous requests.
+	favorites *loader[int, bool]
+}
+
+// newLoaders returns the loaders for a request by userID, 0 if anonymous.
+func newLoaders(s *service.Service, userID int) *loaders {
+	l := &loaders{
+		recipes: newLoader(func(ctx context.Context, ids []int) (map[int]recipe, error) {
+			rows, err := s.GetRecipesByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[int]recipe, len(rows))
+			for _, row := range rows {
+				out[int(row.ID)] = recipe(row)
+			}
+			return out, nil
+		}),
+		ratings: newLoader(func(ctx context.Context, recipeIDs []int) (map[int][]db.Rating, error) {
+			rows, err := s.ListRatingsForRecipes(ctx, recipeIDs)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[int][]db.Rating, len(recipeIDs))
+			for _, row := range rows {
+				id := int(row.RecipeID.Int32)
+				out[id] = append(out[id], row)
+			}
+			return out, nil
+		}),
+		users: newLoader(func(ctx context.Context, ids []int) (map[int]db.GetUsersByIDsRow, error) {
+			rows, err := s.GetUsersByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			out := make(map[int]db.GetUsersByIDsRow, len(rows))
+			for _, row := range rows {
+				out[int(row.ID)] = row
+			}
+			return out, nil
+		}),
+	}
+	if userID > 0 {
+		l.favorites = newLoader(func(ctx context.Context, recipeIDs []int) (map[int]bool, error) {
+			return s.FavoritedRecipeIDs(ctx, userID, recipeIDs)
+		})
+	}
+	return l
+}
+
+type loadersKey struct{}
+
+// withLoaders returns ctx carrying l.
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders of the request ctx belongs to.
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
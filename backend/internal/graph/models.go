@@ -0,0 +1,66 @@
+package graph
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/sqlc-dev/pqtype"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// recipe is the source of the Recipe type. Every recipe query returns the
+// same columns, so rows of the others convert to it.
+type recipe = db.SearchRecipesRow
+
+// user is the source of the User type. Email is only loaded for the
+// caller's own profile.
+type user struct {
+	ID          int32
+	Username    sql.NullString
+	DisplayName sql.NullString
+	AvatarURL   sql.NullString
+	Role        string
+	CreatedAt   sql.NullTime
+	Email       sql.NullString
+}
+
+// page is the source of the page types: the items of a service.Page with
+// its cursor and total.
+type page struct {
+	Items      any
+	NextCursor string
+	Total      int
+}
+
+// jsonValue decodes a JSON column for the JSON scalar, or returns nil.
+func jsonValue(raw pqtype.NullRawMessage) any {
+	var v any
+	if !raw.Valid || json.Unmarshal(raw.RawMessage, &v) != nil {
+		return nil
+	}
+	return v
+}
+
+// nullString returns the string of ns, or nil.
+func nullString(ns sql.NullString) any {
+	if !ns.Valid {
+		return nil
+	}
+	return ns.String
+}
+
+// nullInt returns the int of ni, or nil.
+func nullInt(ni sql.NullInt32) any {
+	if !ni.Valid {
+		return nil
+	}
+	return int(ni.Int32)
+}
+
+// nullTime returns the time of nt, or nil.
+func nullTime(nt sql.NullTime) any {
+	if !nt.Valid {
+		return nil
+	}
+	return nt.Time
+}
@@ -0,0 +1,366 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// resolver resolves the fields that need more than their source.
+type resolver struct {
+	svc *service.Service
+}
+
+// principal returns the caller of the request, or nil if anonymous.
+func principal(ctx context.Context) *auth.Principal {
+	p, _ := ctx.Value(middleware.PrincipalKey).(*auth.Principal)
+	return p
+}
+
+// authorize returns the caller if they are authenticated and, for API keys,
+// granted scope.
+func authorize(ctx context.Context, scope auth.Scope) (*auth.Principal, error) {
+	p := principal(ctx)
+	if p == nil {
+		return nil, errUnauthenticated
+	}
+	if !p.HasScope(scope) {
+		return nil, forbidden("API key lacks the " + string(scope) + " scope")
+	}
+	return p, nil
+}
+
+// thunk adapts a loader thunk for the executor: the loaded value converted
+// by convert, or null if there is none.
+func thunk[V any](ctx context.Context, field string, load func() (V, bool, error), convert func(V) any) func() (any, error) {
+	return func() (any, error) {
+		v, ok, err := load()
+		if err != nil {
+			return nil, publicError(ctx, field, err)
+		}
+		if !ok {
+			return nil, nil
+		}
+		return convert(v), nil
+	}
+}
+
+func itself[V any](v V) any { return v }
+
+// firstArg reads the page size argument.
+func firstArg(p graphql.ResolveParams) (int, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 1 || first > MaxPageSize {
+		return 0, badInput("validation failed", validate.Errors{{Field: "first", Message: "must be between 1 and 200"}})
+	}
+	return first, nil
+}
+
+// filterArg reads the filter argument into a FilterSpec.
+func filterArg(p graphql.ResolveParams) (service.FilterSpec, error) {
+	in, _ := p.Args["filter"].(map[string]any)
+	spec := service.FilterSpec{
+		Diets:        stringList(in["diets"]),
+		Difficulties: stringList(in["difficulties"]),
+		Cuisines:     stringList(in["cuisines"]),
+		Tags:         stringList(in["tags"]),
+	}
+	var errs validate.Errors
+	for _, f := range []struct {
+		name string
+		dst  **int
+	}{
+		{"maxTotalTime", &spec.MaxTotalTime},
+		{"maxPrepTime", &spec.MaxPrepTime},
+		{"maxCookTime", &spec.MaxCookTime},
+		{"minServings", &spec.MinServings},
+		{"maxServings", &spec.MaxServings},
+	} {
+		n, ok := in[f.name].(int)
+		if !ok {
+			continue
+		}
+		if n <= 0 {
+			errs.Add("filter."+f.name, "must be positive")
+			continue
+		}
+		*f.dst = &n
+	}
+	if len(errs) > 0 {
+		return service.FilterSpec{}, badInput("validation failed", errs)
+	}
+	return spec, nil
+}
+
+// stringList converts a list argument.
+func stringList(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return service.SplitList(out)
+}
+
+func (r *resolver) recipe(p graphql.ResolveParams) (any, error) {
+	id, _ := p.Args["id"].(int)
+	row, err := r.svc.GetRecipe(p.Context, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, publicError(p.Context, "recipe", err)
+	}
+	viewer := service.Viewer{IP: middleware.ContextClientIP(p.Context)}
+	if caller := principal(p.Context); caller != nil {
+		viewer.UserID = caller.UserID
+	}
+	if err := r.svc.RecordView(p.Context, id, viewer); err != nil {
+		logging.FromContext(p.Context).Warn("recording recipe view failed", slog.Int("recipe_id", id), slog.Any("error", err))
+	}
+	return recipe(row), nil
+}
+
+func (r *resolver) search(p graphql.ResolveParams) (any, error) {
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := filterArg(p)
+	if err != nil {
+		return nil, err
+	}
+	query, _ := p.Args["query"].(string)
+	sort, _ := p.Args["sort"].(string)
+	after, _ := p.Args["after"].(string)
+	result, err := r.svc.SearchAndFilterRecipes(p.Context, query, filters, sort, first, after)
+	if err != nil {
+		return nil, publicError(p.Context, "search", err)
+	}
+	return page{Items: result.Items, NextCursor: result.NextCursor, Total: result.Total}, nil
+}
+
+func (r *resolver) match(p graphql.ResolveParams) (any, error) {
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := filterArg(p)
+	if err != nil {
+		return nil, err
+	}
+	after, _ := p.Args["after"].(string)
+	result, err := r.svc.MatchWithFilters(p.Context, stringList(p.Args["ingredients"]), service.MatchFilters{
+		FilterSpec: filters, Limit: first, After: after,
+	})
+	if err != nil {
+		return nil, publicError(p.Context, "match", err)
+	}
+	return page{Items: result.Items, NextCursor: result.NextCursor, Total: result.Total}, nil
+}
+
+func (r *resolver) favorites(p graphql.ResolveParams) (any, error) {
+	caller, err := authorize(p.Context, auth.ScopeFavoritesRead)
+	if err != nil {
+		return nil, err
+	}
+	first, err := firstArg(p)
+	if err != nil {
+		return nil, err
+	}
+	after, _ := p.Args["after"].(string)
+	result, err := r.svc.ListFavoritesPage(p.Context, caller.UserID, first, after)
+	if err != nil {
+		return nil, publicError(p.Context, "favorites", err)
+	}
+	items := make([]db.Favorite, len(result.Items))
+	for i, row := range result.Items {
+		items[i] = db.Favorite{ID: row.FavoriteID, UserID: row.UserID, RecipeID: row.RecipeID, CreatedAt: row.CreatedAt}
+	}
+	return page{Items: items, NextCursor: result.NextCursor, Total: result.Total}, nil
+}
+
+func (r *resolver) me(p graphql.ResolveParams) (any, error) {
+	caller := principal(p.Context)
+	if caller == nil {
+		return nil, errUnauthenticated
+	}
+	profile, err := r.svc.GetProfile(p.Context, caller.UserID)
+	if err != nil {
+		return nil, publicError(p.Context, "me", err)
+	}
+	return user{
+		ID:          profile.ID,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		AvatarURL:   profile.AvatarUrl,
+		Role:        profile.Role,
+		CreatedAt:   profile.CreatedAt,
+		Email:       profile.Email,
+	}, nil
+}
+
+func (r *resolver) addFavorite(p graphql.ResolveParams) (any, error) {
+	caller, err := authorize(p.Context, auth.ScopeFavoritesWrite)
+	if err != nil {
+		return nil, err
+	}
+	recipeID, _ := p.Args["recipeId"].(int)
+	if err := r.requireRecipe(p.Context, recipeID); err != nil {
+		return nil, err
+	}
+	fav, err := r.svc.AddFavorite(p.Context, caller.UserID, recipeID)
+	if err != nil {
+		return nil, publicError(p.Context, "addFavorite", err)
+	}
+	return fav, nil
+}
+
+func (r *resolver) removeFavorite(p graphql.ResolveParams) (any, error) {
+	caller, err := authorize(p.Context, auth.ScopeFavoritesWrite)
+	if err != nil {
+		return nil, err
+	}
+	recipeID, _ := p.Args["recipeId"].(int)
+	if err := r.svc.RemoveFavorite(p.Context, caller.UserID, recipeID); err != nil {
+		return nil, publicError(p.Context, "removeFavorite", err)
+	}
+	return true, nil
+}
+
+func (r *resolver) rateRecipe(p graphql.ResolveParams) (any, error) {
+	caller, err := authorize(p.Context, auth.ScopeRatingsWrite)
+	if err != nil {
+		return nil, err
+	}
+	recipeID, _ := p.Args["recipeId"].(int)
+	rating, _ := p.Args["rating"].(int)
+	if rating < 1 || rating > 5 {
+		return nil, badInput("validation failed", validate.Errors{{Field: "rating", Message: "must be between 1 and 5"}})
+	}
+	if err := r.requireRecipe(p.Context, recipeID); err != nil {
+		return nil, err
+	}
+	uid := sql.NullInt32{Int32: int32(caller.UserID), Valid: true}
+	rt, err := r.svc.AddRating(p.Context, uid, recipeID, rating)
+	if err != nil {
+		return nil, publicError(p.Context, "rateRecipe", err)
+	}
+	return rt, nil
+}
+
+// requireRecipe returns a NOT_FOUND error if recipe id does not exist.
+func (r *resolver) requireRecipe(ctx context.Context, id int) error {
+	_, err := r.svc.GetRecipe(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errRecipeNotFound
+	}
+	if err != nil {
+		return publicError(ctx, "recipe", err)
+	}
+	return nil
+}
+
+func (r *resolver) recipeRatings(p graphql.ResolveParams) (any, error) {
+	rc := p.Source.(recipe)
+	first, limited := p.Args["first"].(int)
+	if limited && first < 0 {
+		return nil, badInput("validation failed", validate.Errors{{Field: "first", Message: "must not be negative"}})
+	}
+	load := loadersFrom(p.Context).ratings.load(p.Context, int(rc.ID))
+	return func() (any, error) {
+		// A recipe without ratings is missing from the loaded ones.
+		ratings, _, err := load()
+		if err != nil {
+			return nil, publicError(p.Context, "ratings", err)
+		}
+		if limited && len(ratings) > first {
+			ratings = ratings[:first]
+		}
+		if ratings == nil {
+			ratings = []db.Rating{}
+		}
+		return ratings, nil
+	}, nil
+}
+
+func (r *resolver) recipeIsFavorite(p graphql.ResolveParams) (any, error) {
+	if _, err := authorize(p.Context, auth.ScopeFavoritesRead); err != nil {
+		return nil, err
+	}
+	rc := p.Source.(recipe)
+	load := loadersFrom(p.Context).favorites.load(p.Context, int(rc.ID))
+	return func() (any, error) {
+		favorited, _, err := load()
+		if err != nil {
+			return nil, publicError(p.Context, "isFavorite", err)
+		}
+		return favorited, nil
+	}, nil
+}
+
+func (r *resolver) ratingRecipe(p graphql.ResolveParams) (any, error) {
+	rt := p.Source.(db.Rating)
+	if !rt.RecipeID.Valid {
+		return nil, nil
+	}
+	load := loadersFrom(p.Context).recipes.load(p.Context, int(rt.RecipeID.Int32))
+	return thunk(p.Context, "recipe", load, itself[recipe]), nil
+}
+
+func (r *resolver) ratingUser(p graphql.ResolveParams) (any, error) {
+	rt := p.Source.(db.Rating)
+	if !rt.UserID.Valid {
+		return nil, nil
+	}
+	load := loadersFrom(p.Context).users.load(p.Context, int(rt.UserID.Int32))
+	return thunk(p.Context, "user", load, func(row db.GetUsersByIDsRow) any {
+		return user{
+			ID:          row.ID,
+			Username:    row.Username,
+			DisplayName: row.DisplayName,
+			AvatarURL:   row.AvatarUrl,
+			Role:        row.Role,
+			CreatedAt:   row.CreatedAt,
+		}
+	}), nil
+}
+
+func (r *resolver) favoriteRecipe(p graphql.ResolveParams) (any, error) {
+	fav := p.Source.(db.Favorite)
+	if !fav.RecipeID.Valid {
+		return nil, nil
+	}
+	load := loadersFrom(p.Context).recipes.load(p.Context, int(fav.RecipeID.Int32))
+	return thunk(p.Context, "recipe", load, itself[recipe]), nil
+}
+
+// userEmail shows the email address of the caller's own profile only.
+func (r *resolver) userEmail(p graphql.ResolveParams) (any, error) {
+	u := p.Source.(user)
+	if caller := principal(p.Context); caller == nil || caller.UserID != int(u.ID) {
+		return nil, nil
+	}
+	return nullString(u.Email), nil
+}
+
+// userRole shows the role of the caller's own profile only.
+func (r *resolver) userRole(p graphql.ResolveParams) (any, error) {
+	u := p.Source.(user)
+	if caller := principal(p.Context); caller == nil || caller.UserID != int(u.ID) {
+		return nil, nil
+	}
+	return u.Role, nil
+}
@@ -0,0 +1,286 @@
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// MaxPageSize is the largest page the first argument allows, as for the
+// REST listings.
+const MaxPageSize = 200
+
+// jsonScalar carries free-form JSON, such as nutrition facts.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value.",
+	Serialize:   func(v any) any { return v },
+})
+
+// nonNull and listOf shorten the type expressions below.
+func nonNull(t graphql.Type) graphql.Type { return graphql.NewNonNull(t) }
+
+func listOf(t graphql.Type) graphql.Type { return nonNull(graphql.NewList(nonNull(t))) }
+
+// newSchema builds the schema, resolving through s.
+//
+// Lookups that would otherwise run once per recipe in a list, such as
+// ratings, favorite status and the recipe of a favorite, go through the
+// request's loaders (see loaders). Fields of the caller's own data check
+// authentication and API key scopes themselves, so public fields stay
+// available to anonymous callers in the same query.
+func newSchema(s *service.Service) (graphql.Schema, error) {
+	r := &resolver{svc: s}
+
+	ingredientType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Ingredient",
+		Description: "An ingredient of a recipe. Quantity and unit are null when the recipe does not give them.",
+		Fields: graphql.Fields{
+			"name":     {Type: nonNull(graphql.String), Resolve: from(func(i service.Ingredient) any { return i.Name })},
+			"quantity": {Type: graphql.Float, Resolve: from(func(i service.Ingredient) any { return optional(i.Quantity) })},
+			"unit": {Type: graphql.String, Resolve: from(func(i service.Ingredient) any {
+				if i.Unit == "" {
+					return nil
+				}
+				return i.Unit
+			})},
+		},
+	})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "A user's public profile. Email and role are only shown to the user themselves.",
+		Fields: graphql.Fields{
+			"id":          {Type: nonNull(graphql.Int), Resolve: from(func(u user) any { return int(u.ID) })},
+			"username":    {Type: graphql.String, Resolve: from(func(u user) any { return nullString(u.Username) })},
+			"displayName": {Type: graphql.String, Resolve: from(func(u user) any { return nullString(u.DisplayName) })},
+			"avatarUrl":   {Type: graphql.String, Resolve: from(func(u user) any { return nullString(u.AvatarURL) })},
+			"createdAt":   {Type: graphql.DateTime, Resolve: from(func(u user) any { return nullTime(u.CreatedAt) })},
+			"email":       {Type: graphql.String, Description: "Only set on me.", Resolve: r.userEmail},
+			"role":        {Type: graphql.String, Resolve: r.userRole},
+		},
+	})
+
+	recipeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Recipe",
+		Fields: graphql.Fields{
+			"id":               {Type: nonNull(graphql.Int), Resolve: from(func(rc recipe) any { return int(rc.ID) })},
+			"title":            {Type: nonNull(graphql.String), Resolve: from(func(rc recipe) any { return rc.Title })},
+			"description":      {Type: graphql.String, Resolve: from(func(rc recipe) any { return nullString(rc.Description) })},
+			"cuisine":          {Type: graphql.String, Resolve: from(func(rc recipe) any { return nullString(rc.Cuisine) })},
+			"difficulty":       {Type: graphql.String, Resolve: from(func(rc recipe) any { return nullString(rc.Difficulty) })},
+			"dietType":         {Type: graphql.String, Resolve: from(func(rc recipe) any { return nullString(rc.DietType) })},
+			"prepTimeMinutes":  {Type: graphql.Int, Resolve: from(func(rc recipe) any { return nullInt(rc.PrepTimeMinutes) })},
+			"cookTimeMinutes":  {Type: graphql.Int, Resolve: from(func(rc recipe) any { return nullInt(rc.CookTimeMinutes) })},
+			"totalTimeMinutes": {Type: graphql.Int, Resolve: from(func(rc recipe) any { return nullInt(rc.TotalTimeMinutes) })},
+			"servings":         {Type: graphql.Int, Resolve: from(func(rc recipe) any { return nullInt(rc.Servings) })},
+			"ingredients":      {Type: listOf(ingredientType), Resolve: from(func(rc recipe) any { return service.RecipeIngredients(rc.Ingredients) })},
+			"steps":            {Type: listOf(graphql.String), Resolve: from(func(rc recipe) any { return service.RecipeSteps(rc.Steps) })},
+			"nutrition":        {Type: jsonScalar, Resolve: from(func(rc recipe) any { return jsonValue(rc.Nutrition) })},
+			"tags": {Type: listOf(graphql.String), Resolve: from(func(rc recipe) any {
+				if rc.Tags == nil {
+					return []string{}
+				}
+				return rc.Tags
+			})},
+			"averageRating": {Type: nonNull(graphql.Float), Resolve: from(func(rc recipe) any { return service.AverageRating(rc.AverageRating) })},
+			"isFavorite": {
+				Type:        graphql.Boolean,
+				Description: "Whether the caller has favorited the recipe. Requires authentication and, for API keys, the favorites:read scope.",
+				Resolve:     r.recipeIsFavorite,
+			},
+		},
+	})
+
+	ratingType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Rating",
+		Fields: graphql.Fields{
+			"id":        {Type: nonNull(graphql.Int), Resolve: from(func(rt db.Rating) any { return int(rt.ID) })},
+			"rating":    {Type: nonNull(graphql.Int), Resolve: from(func(rt db.Rating) any { return int(rt.Rating.Int32) })},
+			"createdAt": {Type: graphql.DateTime, Resolve: from(func(rt db.Rating) any { return nullTime(rt.CreatedAt) })},
+			"recipe":    {Type: recipeType, Resolve: r.ratingRecipe},
+			"user":      {Type: userType, Description: "The author of the rating; null for anonymous ratings.", Resolve: r.ratingUser},
+		},
+	})
+	recipeType.AddFieldConfig("ratings", &graphql.Field{
+		Type:        listOf(ratingType),
+		Description: "Ratings of the recipe, newest first.",
+		Args: graphql.FieldConfigArgument{
+			"first": {Type: graphql.Int, Description: "Return at most this many ratings."},
+		},
+		Resolve: r.recipeRatings,
+	})
+
+	favoriteType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Favorite",
+		Fields: graphql.Fields{
+			"id":        {Type: nonNull(graphql.Int), Resolve: from(func(f db.Favorite) any { return int(f.ID) })},
+			"createdAt": {Type: graphql.DateTime, Resolve: from(func(f db.Favorite) any { return nullTime(f.CreatedAt) })},
+			"recipe":    {Type: recipeType, Resolve: r.favoriteRecipe},
+		},
+	})
+
+	searchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SearchResult",
+		Fields: graphql.Fields{
+			"recipe": {Type: nonNull(recipeType), Resolve: from(func(sr service.RecipeSearchResult) any { return sr.SearchRecipesRow })},
+			"rank": {Type: graphql.Float, Description: "Full-text relevance; null without a query.", Resolve: from(func(sr service.RecipeSearchResult) any {
+				if sr.Rank == 0 {
+					return nil
+				}
+				return sr.Rank
+			})},
+			"snippet": {Type: graphql.String, Description: "Matching text with matches in <mark> tags; null without a query.", Resolve: from(func(sr service.RecipeSearchResult) any {
+				if sr.Snippet == "" {
+					return nil
+				}
+				return sr.Snippet
+			})},
+		},
+	})
+
+	matchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MatchResult",
+		Fields: graphql.Fields{
+			"recipe": {Type: nonNull(recipeType), Resolve: from(func(m service.RecipeWithScore) any { return m.SearchRecipesRow })},
+			"score":  {Type: nonNull(graphql.Int), Description: "Number of the given ingredients found in the recipe's tags and title.", Resolve: from(func(m service.RecipeWithScore) any { return m.Score })},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "RecipeFilter",
+		Description: "Narrows recipes as the REST filters do: values within a list match any of them, different fields must all match.",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"diets":        {Type: graphql.NewList(nonNull(graphql.String))},
+			"difficulties": {Type: graphql.NewList(nonNull(graphql.String))},
+			"cuisines":     {Type: graphql.NewList(nonNull(graphql.String))},
+			"tags":         {Type: graphql.NewList(nonNull(graphql.String))},
+			"maxTotalTime": {Type: graphql.Int},
+			"maxPrepTime":  {Type: graphql.Int},
+			"maxCookTime":  {Type: graphql.Int},
+			"minServings":  {Type: graphql.Int},
+			"maxServings":  {Type: graphql.Int},
+		},
+	})
+
+	sortType := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "RecipeSort",
+		Description: "Order of search results; by relevance with a query and by ID without, when not given.",
+		Values: graphql.EnumValueConfigMap{
+			"RATING":   {Value: service.SortRating},
+			"NEWEST":   {Value: service.SortNewest},
+			"QUICKEST": {Value: service.SortQuickest},
+			"POPULAR":  {Value: service.SortPopular},
+		},
+	})
+
+	pageArgs := func(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args["first"] = &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 50, Description: "Page size, at most 200."}
+		args["after"] = &graphql.ArgumentConfig{Type: graphql.String, Description: "nextCursor of the previous page."}
+		return args
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"recipe": {
+				Type:        recipeType,
+				Description: "A recipe by ID, or null if there is none. Counts as a view for the trending feeds.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.Int)}},
+				Resolve:     r.recipe,
+			},
+			"search": {
+				Type:        nonNull(pageType("SearchPage", searchResultType)),
+				Description: "Recipes matching a full-text query and filters, as GET /recipes.",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"query":  {Type: graphql.String},
+					"filter": {Type: filterType},
+					"sort":   {Type: sortType},
+				}),
+				Resolve: r.search,
+			},
+			"match": {
+				Type:        nonNull(pageType("MatchPage", matchResultType)),
+				Description: "Recipes ranked by how many of the ingredients they use, as POST /match.",
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"ingredients": {Type: listOf(graphql.String)},
+					"filter":      {Type: filterType},
+				}),
+				Resolve: r.match,
+			},
+			"favorites": {
+				Type:        nonNull(pageType("FavoritePage", favoriteType)),
+				Description: "The caller's favorites, newest first. Requires the favorites:read scope for API keys.",
+				Args:        pageArgs(graphql.FieldConfigArgument{}),
+				Resolve:     r.favorites,
+			},
+			"me": {
+				Type:        nonNull(userType),
+				Description: "The caller's profile.",
+				Resolve:     r.me,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"addFavorite": {
+				Type:        nonNull(favoriteType),
+				Description: "Adds a recipe to the caller's favorites. Requires the favorites:write scope for API keys.",
+				Args:        graphql.FieldConfigArgument{"recipeId": {Type: nonNull(graphql.Int)}},
+				Resolve:     r.addFavorite,
+			},
+			"removeFavorite": {
+				Type:        nonNull(graphql.Boolean),
+				Description: "Removes a recipe from the caller's favorites. Requires the favorites:write scope for API keys.",
+				Args:        graphql.FieldConfigArgument{"recipeId": {Type: nonNull(graphql.Int)}},
+				Resolve:     r.removeFavorite,
+			},
+			"rateRecipe": {
+				Type:        nonNull(ratingType),
+				Description: "Rates a recipe from 1 to 5. Requires the ratings:write scope for API keys.",
+				Args: graphql.FieldConfigArgument{
+					"recipeId": {Type: nonNull(graphql.Int)},
+					"rating":   {Type: nonNull(graphql.Int)},
+				},
+				Resolve: r.rateRecipe,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// pageType is a page of items in the REST listings' envelope.
+func pageType(name string, item *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"items": {Type: listOf(item), Resolve: from(func(p page) any { return p.Items })},
+			"nextCursor": {Type: graphql.String, Description: "Cursor of the next page; null on the last page.", Resolve: from(func(p page) any {
+				if p.NextCursor == "" {
+					return nil
+				}
+				return p.NextCursor
+			})},
+			"total": {Type: nonNull(graphql.Int), Description: "Number of items on all pages.", Resolve: from(func(p page) any { return p.Total })},
+		},
+	})
+}
+
+// from resolves a field from its source, of type T, alone.
+func from[T any](fn func(T) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		return fn(p.Source.(T)), nil
+	}
+}
+
+// optional returns *v, or nil.
+func optional[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
@@ -0,0 +1,70 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	recipesv1 "github.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+)
+
+// Gateway serves the gRPC API as JSON over HTTP, at the routes of
+// api/recipes/v1/gateway.yaml, by calling the gRPC server. Requests carry
+// credentials in the same headers as the REST API.
+type Gateway struct {
+	http.Handler
+	conn *grpc.ClientConn
+}
+
+// NewGateway returns a Gateway calling the gRPC server at addr, which it
+// connects to on first use.
+func NewGateway(addr string) (*Gateway, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayHeader),
+		runtime.WithOutgoingHeaderMatcher(gatewayResponseHeader),
+	)
+	ctx := context.Background()
+	if err := recipesv1.RegisterRecipeServiceHandler(ctx, mux, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := recipesv1.RegisterVisionServiceHandler(ctx, mux, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Gateway{Handler: mux, conn: conn}, nil
+}
+
+// Close closes the connection to the gRPC server.
+func (g *Gateway) Close() error {
+	return g.conn.Close()
+}
+
+// gatewayHeader passes API keys on as metadata along with the headers the
+// gateway passes by default, Authorization among them.
+func gatewayHeader(key string) (string, bool) {
+	if strings.EqualFold(key, middleware.APIKeyHeader) {
+		return strings.ToLower(key), true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayResponseHeader sends the rate limit metadata back as the headers
+// the REST API uses, and other metadata with the gateway's Grpc-Metadata-
+// prefix.
+func gatewayResponseHeader(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "ratelimit-limit", "ratelimit-remaining", "ratelimit-reset", "ratelimit-policy", "retry-after":
+		return key, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
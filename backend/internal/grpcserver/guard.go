@@ -0,0 +1,222 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	recipesv1 "github.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/config"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/ratelimit"
+)
+
+// rateLimited maps the methods that are rate limited to their policy.
+var rateLimited = map[string]string{
+	recipesv1.RecipeService_MatchRecipes_FullMethodName:      config.RateLimitMatch,
+	recipesv1.VisionService_DetectIngredients_FullMethodName: config.RateLimitDetect,
+}
+
+// openServices are the services callable without credentials.
+var openServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+// identified are the read-only methods anonymous callers may call, as they
+// may the matching REST routes. Credentials are still read when sent, the
+// way middleware.Identify does.
+var identified = map[string]bool{
+	recipesv1.RecipeService_GetRecipe_FullMethodName:     true,
+	recipesv1.RecipeService_ListRecipes_FullMethodName:   true,
+	recipesv1.RecipeService_SearchRecipes_FullMethodName: true,
+}
+
+// guard is the interceptor of every call: it authenticates the caller,
+// applies rate limits, recovers from panics and logs the call.
+type guard struct {
+	authenticators []middleware.Authenticator
+	limiter        *ratelimit.Limiter
+	trusted        []netip.Prefix
+}
+
+func (g guard) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	ctx, err = g.admit(ctx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) })
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicked(ctx, p)
+		}
+		logCall(ctx, info.FullMethod, start, err)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (g guard) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	ctx, err := g.admit(ss.Context(), info.FullMethod, ss.SetHeader)
+	defer func() {
+		if p := recover(); p != nil {
+			err = panicked(ctx, p)
+		}
+		logCall(ctx, info.FullMethod, start, err)
+	}()
+	if err != nil {
+		return err
+	}
+	return handler(srv, serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// admit authenticates the caller of method and takes a token from its rate
+// limit, if any, sending the limit's state as headers with setHeader.
+// Returns the context to handle the call in, carrying the caller and its
+// IP address, and the status error of a call that is refused.
+func (g guard) admit(ctx context.Context, method string, setHeader func(metadata.MD) error) (context.Context, error) {
+	for _, prefix := range openServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	r, err := callRequest(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = middleware.WithClientIP(ctx, middleware.ResolveClientIP(r, g.trusted))
+
+	principal, err := g.authenticate(r)
+	if identified[method] {
+		// As on REST, bad credentials leave the caller anonymous.
+		if err == nil {
+			ctx = middleware.WithPrincipal(ctx, principal)
+		}
+		return ctx, nil
+	}
+	if err != nil {
+		return ctx, err
+	}
+	ctx = middleware.WithPrincipal(ctx, principal)
+
+	policy, ok := rateLimited[method]
+	if !ok || g.limiter == nil || !g.limiter.Policy(policy).Enabled() {
+		return ctx, nil
+	}
+	key := middleware.PrincipalRateLimitKey(principal)
+	res, err := g.limiter.Allow(ctx, policy, key, time.Now())
+	if err != nil {
+		// As for REST, an outage of the store lets calls through.
+		logging.FromContext(ctx).Error("rate limit check failed", slog.String("policy", policy), slog.Any("error", err))
+		return ctx, nil
+	}
+	md := metadata.Pairs(
+		"ratelimit-limit", strconv.Itoa(res.Limit),
+		"ratelimit-remaining", strconv.Itoa(res.Remaining),
+		"ratelimit-reset", middleware.HeaderSeconds(res.Reset),
+		"ratelimit-policy", g.limiter.Policy(policy).String(),
+	)
+	if !res.Allowed {
+		md.Set("retry-after", middleware.HeaderSeconds(res.RetryAfter))
+		middleware.LogRateLimited(ctx, policy, key)
+	}
+	_ = setHeader(md)
+	if !res.Allowed {
+		return ctx, status.Error(codes.ResourceExhausted, "rate limit exceeded, try again later")
+	}
+	return ctx, nil
+}
+
+// callRequest returns the call as the HTTP request the authenticators and
+// the client IP resolution read: its metadata as the headers and its peer
+// as the remote address.
+func callRequest(ctx context.Context) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "server error")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		for _, v := range values {
+			r.Header.Add(key, v)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return r, nil
+}
+
+// authenticate identifies the caller of r with the first authenticator
+// whose credentials it carries.
+func (g guard) authenticate(r *http.Request) (*auth.Principal, error) {
+	ctx := r.Context()
+	for _, a := range g.authenticators {
+		p, err := a.Authenticate(r)
+		switch {
+		case errors.Is(err, middleware.ErrNoCredentials):
+			continue
+		case errors.Is(err, middleware.ErrBadCredentials):
+			return nil, status.Error(codes.Unauthenticated, "unauthorized")
+		case err != nil:
+			logging.FromContext(ctx).Error("authentication failed", slog.Any("error", err))
+			return nil, status.Error(codes.Internal, "server error")
+		}
+		return p, nil
+	}
+	return nil, status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// panicked logs a panic of a handler and returns the error to answer with,
+// so one bad call does not take the server down.
+func panicked(ctx context.Context, p any) error {
+	logging.FromContext(ctx).Error("panic handling call", slog.Any("panic", p), slog.String("stack", string(debug.Stack())))
+	return status.Error(codes.Internal, "server error")
+}
+
+// logCall writes one log line per call with its method, status code,
+// duration and, through the context's logger, the caller. Server-side
+// failures are logged at error level, everything else at info.
+//
+// Example (text format):
+//
+//	level=INFO msg=rpc user_id=7 method=/recipes.v1.RecipeService/GetRecipe code=OK duration_ms=4.1
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		level = slog.LevelError
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, level, "rpc",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
+
+// serverStream is a stream handled in the context admit returned.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream's context.
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,232 @@
+package grpcserver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	recipesv1 "github.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// Page sizes, as for the REST listings.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// sorts maps RecipeSort values to the sort options of the service.
+var sorts = map[recipesv1.RecipeSort]string{
+	recipesv1.RecipeSort_RECIPE_SORT_UNSPECIFIED: "",
+	recipesv1.RecipeSort_RECIPE_SORT_RATING:      service.SortRating,
+	recipesv1.RecipeSort_RECIPE_SORT_NEWEST:      service.SortNewest,
+	recipesv1.RecipeSort_RECIPE_SORT_QUICKEST:    service.SortQuickest,
+	recipesv1.RecipeSort_RECIPE_SORT_POPULAR:     service.SortPopular,
+}
+
+// recipeServer implements RecipeService.
+type recipeServer struct {
+	recipesv1.UnimplementedRecipeServiceServer
+	service *service.Service
+}
+
+// GetRecipe implements RecipeService. Each lookup is recorded as a view of
+// the caller for the trending feeds, as on GET /recipes/{id}: by user when
+// signed in, by IP address otherwise.
+func (s *recipeServer) GetRecipe(ctx context.Context, req *recipesv1.GetRecipeRequest) (*recipesv1.Recipe, error) {
+	id := int(req.GetId())
+	row, err := s.service.GetRecipe(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "recipe not found")
+	}
+	if err != nil {
+		return nil, internalError(ctx, err)
+	}
+
+	uid, _ := ctx.Value(middleware.UserIDKey).(int)
+	if err := s.service.RecordView(ctx, id, service.Viewer{UserID: uid, IP: middleware.ContextClientIP(ctx)}); err != nil {
+		logging.FromContext(ctx).Warn("recording recipe view failed", slog.Int("recipe_id", id), slog.Any("error", err))
+	}
+	return toRecipe(db.SearchRecipesRow(row)), nil
+}
+
+// ListRecipes implements RecipeService.
+func (s *recipeServer) ListRecipes(ctx context.Context, req *recipesv1.ListRecipesRequest) (*recipesv1.ListRecipesResponse, error) {
+	page, err := s.search(ctx, "", req.GetFilter(), req.GetSort(), req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	resp := &recipesv1.ListRecipesResponse{
+		Recipes:       make([]*recipesv1.Recipe, len(page.Items)),
+		NextPageToken: page.NextCursor,
+		TotalSize:     int32(page.Total),
+	}
+	for i, item := range page.Items {
+		resp.Recipes[i] = toRecipe(item.SearchRecipesRow)
+	}
+	return resp, nil
+}
+
+// SearchRecipes implements RecipeService.
+func (s *recipeServer) SearchRecipes(ctx context.Context, req *recipesv1.SearchRecipesRequest) (*recipesv1.SearchRecipesResponse, error) {
+	page, err := s.search(ctx, req.GetQuery(), req.GetFilter(), req.GetSort(), req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	resp := &recipesv1.SearchRecipesResponse{
+		Results:       make([]*recipesv1.SearchResult, len(page.Items)),
+		NextPageToken: page.NextCursor,
+		TotalSize:     int32(page.Total),
+	}
+	for i, item := range page.Items {
+		resp.Results[i] = &recipesv1.SearchResult{
+			Recipe:  toRecipe(item.SearchRecipesRow),
+			Rank:    item.Rank,
+			Snippet: item.Snippet,
+		}
+	}
+	return resp, nil
+}
+
+// search returns the page of ListRecipes and SearchRecipes, which only
+// differ in the query.
+func (s *recipeServer) search(ctx context.Context, query string, filter *recipesv1.RecipeFilter, sort recipesv1.RecipeSort, pageSize int32, pageToken string) (service.Page[service.RecipeSearchResult], error) {
+	order, ok := sorts[sort]
+	if !ok {
+		return service.Page[service.RecipeSearchResult]{}, status.Error(codes.InvalidArgument, "invalid sort")
+	}
+	limit, err := pageLimit(pageSize)
+	if err != nil {
+		return service.Page[service.RecipeSearchResult]{}, err
+	}
+	page, err := s.service.SearchAndFilterRecipes(ctx, query, toFilterSpec(filter), order, limit, pageToken)
+	if err != nil {
+		return page, pageError(ctx, err)
+	}
+	return page, nil
+}
+
+// MatchRecipes implements RecipeService.
+func (s *recipeServer) MatchRecipes(ctx context.Context, req *recipesv1.MatchRecipesRequest) (*recipesv1.MatchRecipesResponse, error) {
+	limit, err := pageLimit(req.GetPageSize())
+	if err != nil {
+		return nil, err
+	}
+	page, err := s.service.MatchWithFilters(ctx, req.GetIngredients(), service.MatchFilters{
+		FilterSpec: toFilterSpec(req.GetFilter()), Limit: limit, After: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, pageError(ctx, err)
+	}
+	resp := &recipesv1.MatchRecipesResponse{
+		Matches:       make([]*recipesv1.RecipeMatch, len(page.Items)),
+		NextPageToken: page.NextCursor,
+		TotalSize:     int32(page.Total),
+	}
+	for i, item := range page.Items {
+		resp.Matches[i] = &recipesv1.RecipeMatch{Recipe: toRecipe(item.SearchRecipesRow), Score: int32(item.Score)}
+	}
+	return resp, nil
+}
+
+// pageLimit returns the number of items to list for a page_size: the
+// default when unset, at most MaxPageSize.
+func pageLimit(size int32) (int, error) {
+	switch {
+	case size < 0:
+		return 0, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case size == 0:
+		return DefaultPageSize, nil
+	case size > MaxPageSize:
+		return MaxPageSize, nil
+	}
+	return int(size), nil
+}
+
+// pageError maps an error of a paged listing to its status.
+func pageError(ctx context.Context, err error) error {
+	if errors.Is(err, service.ErrInvalidCursor) {
+		return status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	return internalError(ctx, err)
+}
+
+// internalError logs an unexpected error and hides it from the caller.
+func internalError(ctx context.Context, err error) error {
+	logging.FromContext(ctx).Error("call failed", slog.Any("error", err))
+	return status.Error(codes.Internal, "server error")
+}
+
+// toFilterSpec converts a RecipeFilter, which requests may leave out; as
+// for the REST query parameters, list entries may be comma separated and
+// limits that are not positive do not filter.
+func toFilterSpec(f *recipesv1.RecipeFilter) service.FilterSpec {
+	if f == nil {
+		return service.FilterSpec{}
+	}
+	return service.FilterSpec{
+		Diets:        service.SplitList(f.GetDiets()),
+		Difficulties: service.SplitList(f.GetDifficulties()),
+		Cuisines:     service.SplitList(f.GetCuisines()),
+		Tags:         service.SplitList(f.GetTags()),
+		MaxTotalTime: positive(f.MaxTotalTimeMinutes),
+		MaxPrepTime:  positive(f.MaxPrepTimeMinutes),
+		MaxCookTime:  positive(f.MaxCookTimeMinutes),
+		MinServings:  positive(f.MinServings),
+		MaxServings:  positive(f.MaxServings),
+	}
+}
+
+// positive returns the value of v if it is set and positive, or nil.
+func positive(v *int32) *int {
+	if v == nil || *v <= 0 {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
+
+// toRecipe converts a recipe row; every recipe query returns the same
+// columns, so rows of the others convert to db.SearchRecipesRow.
+func toRecipe(row db.SearchRecipesRow) *recipesv1.Recipe {
+	r := &recipesv1.Recipe{
+		Id:               row.ID,
+		Title:            row.Title,
+		Description:      row.Description.String,
+		Cuisine:          row.Cuisine.String,
+		Difficulty:       row.Difficulty.String,
+		DietType:         row.DietType.String,
+		PrepTimeMinutes:  optionalInt(row.PrepTimeMinutes),
+		CookTimeMinutes:  optionalInt(row.CookTimeMinutes),
+		TotalTimeMinutes: optionalInt(row.TotalTimeMinutes),
+		Servings:         optionalInt(row.Servings),
+		Steps:            service.RecipeSteps(row.Steps),
+		Tags:             row.Tags,
+		AverageRating:    service.AverageRating(row.AverageRating),
+	}
+	for _, in := range service.RecipeIngredients(row.Ingredients) {
+		r.Ingredients = append(r.Ingredients, &recipesv1.Ingredient{Name: in.Name, Quantity: in.Quantity, Unit: in.Unit})
+	}
+	var nutrition map[string]any
+	if row.Nutrition.Valid && json.Unmarshal(row.Nutrition.RawMessage, &nutrition) == nil {
+		r.Nutrition, _ = structpb.NewStruct(nutrition)
+	}
+	return r
+}
+
+// optionalInt returns a pointer to the value of ni, or nil.
+func optionalInt(ni sql.NullInt32) *int32 {
+	if !ni.Valid {
+		return nil
+	}
+	return &ni.Int32
+}
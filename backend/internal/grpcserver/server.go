@@ -0,0 +1,75 @@
+// Package grpcserver serves the gRPC API defined in api/recipes/v1, for
+// services that want typed clients: recipe lookup, search and matching, and
+// ingredient detection from an image streamed in chunks. It runs on its own
+// port, over the same service as the REST API.
+//
+// Credentials are sent as metadata the way the REST API takes them as
+// headers: "authorization: Bearer <access token>" or "x-api-key: <key>".
+// Like their REST routes, recipe lookup, listing and search are open to
+// anonymous callers, while matching and detection need credentials. The
+// standard health and reflection services are open too.
+//
+// Gateway serves the same API as JSON over HTTP under /v1 for clients
+// without gRPC.
+package grpcserver
+
+import (
+	"net/netip"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	recipesv1 "github.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/ratelimit"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/vision"
+)
+
+// Options configures a Server.
+type Options struct {
+	Service *service.Service
+	// Vision detects ingredients; nil disables detection.
+	Vision vision.VisionService
+	// MaxImageBytes bounds the images DetectIngredients accepts.
+	MaxImageBytes int64
+	// Authenticators identify callers from their metadata, tried in order.
+	Authenticators []middleware.Authenticator
+	// Limiter throttles matching and detection under the same policies as
+	// REST; nil disables rate limiting.
+	Limiter *ratelimit.Limiter
+	// TrustedProxies are the peers whose x-forwarded-for and x-real-ip
+	// metadata give the caller's IP address, as TRUSTED_PROXIES does for
+	// REST. The gateway's loopback address belongs here when it is served.
+	TrustedProxies []netip.Prefix
+}
+
+// Server is the gRPC server with the API services registered.
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// New returns a Server for opts, ready to Serve.
+func New(opts Options) *Server {
+	g := guard{authenticators: opts.Authenticators, limiter: opts.Limiter, trusted: opts.TrustedProxies}
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(g.unary),
+		grpc.ChainStreamInterceptor(g.stream),
+	)
+	recipesv1.RegisterRecipeServiceServer(srv, &recipeServer{service: opts.Service})
+	recipesv1.RegisterVisionServiceServer(srv, &visionServer{vision: opts.Vision, maxImageBytes: opts.MaxImageBytes})
+
+	h := health.NewServer()
+	healthpb.RegisterHealthServer(srv, h)
+	reflection.Register(srv)
+	return &Server{Server: srv, health: h}
+}
+
+// Drain reports the server as not serving to health checks, so that load
+// balancers stop sending it calls before it stops.
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
@@ -0,0 +1,89 @@
+package grpcserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	recipesv1 "github.com/varnit-ta/smart-recipe-generator/backend/api/recipes/v1"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/vision"
+)
+
+// visionServer implements VisionService.
+type visionServer struct {
+	recipesv1.UnimplementedVisionServiceServer
+	vision        vision.VisionService
+	maxImageBytes int64
+}
+
+// DetectIngredients implements VisionService. The chunks are gathered up to
+// maxImageBytes and the image handed to the vision service in one piece,
+// as for POST /detect-ingredients.
+func (s *visionServer) DetectIngredients(stream recipesv1.VisionService_DetectIngredientsServer) error {
+	if s.vision == nil {
+		return status.Error(codes.Unavailable, "vision service not configured")
+	}
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "no image provided")
+	}
+	if err != nil {
+		return err
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return status.Error(codes.InvalidArgument, "the first message must carry the image info")
+	}
+	if !vision.SupportedImageType(info.GetContentType()) {
+		return status.Error(codes.InvalidArgument, "invalid image format. Supported: JPEG, PNG, GIF, WebP")
+	}
+
+	var image []byte
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if req.GetInfo() != nil {
+			return status.Error(codes.InvalidArgument, "the image info must only be sent first")
+		}
+		data := req.GetData()
+		if int64(len(image)+len(data)) > s.maxImageBytes {
+			return status.Error(codes.ResourceExhausted, fmt.Sprintf("image larger than %d bytes", s.maxImageBytes))
+		}
+		image = append(image, data...)
+	}
+	if len(image) == 0 {
+		return status.Error(codes.InvalidArgument, "empty image file")
+	}
+
+	result, err := s.vision.DetectIngredients(ctx, image, info.GetFilename())
+	if err != nil {
+		logging.FromContext(ctx).Warn("ingredient detection failed", slog.String("filename", info.GetFilename()), slog.Any("error", err))
+		return status.Error(codes.Unavailable, "could not detect ingredients, try again or add them manually")
+	}
+
+	resp := &recipesv1.DetectIngredientsResponse{
+		Ingredients: result.Ingredients,
+		Confidence:  result.Confidence,
+		Provider:    result.Provider,
+		Caption:     result.RawResponse,
+	}
+	resp.Cuisine, _ = result.Metadata["cuisine"].(string)
+	resp.DishType, _ = result.Metadata["dish_type"].(string)
+	if details, ok := result.Metadata["details"].(map[string]any); ok {
+		resp.Details, _ = structpb.NewStruct(details)
+	}
+	return stream.SendAndClose(resp)
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// ForgotPasswordRequest names the account to send a reset link to.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ForgotPassword handles POST /api/auth/forgot-password.
+//
+// Request body: ForgotPasswordRequest with email
+//
+// Security:
+// - Always answers 202, whether or not the email is registered
+// - Only the latest reset link works and it expires after PASSWORD_RESET_TTL
+//
+// Returns: 202 Accepted
+func (a *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	// The email is sent in the background, so neither the response nor its
+	// timing reveals whether the address belongs to an account.
+	a.Service.RequestPasswordReset(r.Context(), req.Email)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPasswordRequest carries the emailed reset token and the new password.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ResetPassword handles POST /api/auth/reset-password.
+//
+// Request body: ResetPasswordRequest with token and password
+//
+// Security:
+// - Reset tokens are single-use and stored hashed
+// - Every session of the user is revoked
+//
+// Returns: 204 No Content, or 400 for an invalid token or weak password
+func (a *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmailRequest carries the emailed verification token.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail handles POST /api/auth/verify-email.
+//
+// Request body: VerifyEmailRequest with token
+//
+// Returns: 204 No Content, or 400 for an invalid or expired token
+func (a *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.VerifyEmail(r.Context(), req.Token); err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerification handles POST /api/me/verify-email (requires authentication).
+//
+// Sends a new verification link; earlier links stop working. Does nothing
+// if the email is already verified.
+//
+// Returns: 202 Accepted
+func (a *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := a.Service.SendVerificationEmail(r.Context(), userID); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "could not send email")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ChangePasswordRequest carries the current and the new password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+// ChangePassword handles PUT /api/me/password (requires authentication).
+//
+// Request body: ChangePasswordRequest with currentPassword and newPassword
+//
+// Security:
+// - The current password must be confirmed
+// - Every other session is signed out; the current one stays valid
+//
+// Returns: 204 No Content, 400 for a weak password, or 403 if the current
+// password is wrong
+func (a *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*auth.Claims)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	err := a.Service.ChangePassword(r.Context(), claims.UserID, claims.SessionID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeAccountError maps account flow errors to 400/403 and anything else to
+// 500.
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case writeInputError(w, err):
+	case errors.Is(err, service.ErrInvalidAccountToken):
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrWrongPassword):
+		apierror.WriteFields(w, http.StatusForbidden, err.Error(), []validate.FieldError{{Field: "currentPassword", Message: "is incorrect"}})
+	default:
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+	}
+}
@@ -0,0 +1,405 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// AdminHandler serves the /api/admin endpoints. Each route group requires a
+// minimum role, enforced by middleware.RequireRole.
+type AdminHandler struct {
+	Service *service.Service
+}
+
+// UnlockLoginRequest names the account and/or client IP to unlock.
+type UnlockLoginRequest struct {
+	Email string `json:"email"`
+	IP    string `json:"ip"`
+}
+
+// UnlockLogin handles POST /api/admin/login-locks/unlock (admin only).
+//
+// Request body: UnlockLoginRequest with email, ip or both
+//
+// Clears the failed-login counters and any lockout of the account and the
+// client IP. The unlock is written to the audit log.
+//
+// Returns: 204 No Content, or 400 if neither email nor ip is given
+func (a *AdminHandler) UnlockLogin(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req UnlockLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	if req.Email == "" && req.IP == "" {
+		apierror.WriteFields(w, http.StatusBadRequest, "nothing to unlock", []validate.FieldError{
+			{Field: "email", Message: "or ip is required"},
+		})
+		return
+	}
+
+	if err := a.Service.UnlockLogin(r.Context(), actor, req.Email, req.IP); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AuditEventResponse is an audit log entry.
+type AuditEventResponse struct {
+	ID        int             `json:"id"`
+	Event     string          `json:"event"`
+	UserID    *int            `json:"userId,omitempty"`
+	Subject   string          `json:"subject,omitempty"`
+	IPAddress string          `json:"ipAddress,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// ListAuditEvents handles GET /api/admin/audit-events (admin only).
+//
+// Query parameters:
+//   - event: event name prefix, e.g. "login." (default: all events)
+//   - limit: number of events (default 100, max 500)
+//
+// Returns: 200 OK with the newest events first
+func (a *AdminHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = min(n, 500)
+		}
+	}
+
+	rows, err := a.Service.ListAuditEvents(r.Context(), r.URL.Query().Get("event"), limit)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	out := make([]AuditEventResponse, 0, len(rows))
+	for _, row := range rows {
+		ev := AuditEventResponse{
+			ID:        int(row.ID),
+			Event:     row.Event,
+			Subject:   row.Subject.String,
+			IPAddress: row.IpAddress.String,
+			CreatedAt: row.CreatedAt,
+		}
+		if row.UserID.Valid {
+			id := int(row.UserID.Int32)
+			ev.UserID = &id
+		}
+		if row.Details.Valid {
+			ev.Details = row.Details.RawMessage
+		}
+		out = append(out, ev)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// UserResponse is a user as seen by admins.
+type UserResponse struct {
+	ID            int        `json:"id"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"emailVerified"`
+	CreatedAt     *time.Time `json:"createdAt,omitempty"`
+}
+
+// ListUsers handles GET /api/admin/users (admin only).
+//
+// Query parameters:
+//   - limit: users per page (default 50, max 200)
+//   - cursor: nextCursor from the previous page
+//
+// Returns: 200 OK with a page of users ordered by ID
+func (a *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = min(n, 200)
+		}
+	}
+
+	page, err := a.Service.ListUsers(r.Context(), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writePageError(w, r, err)
+		return
+	}
+
+	items := make([]UserResponse, 0, len(page.Items))
+	for _, row := range page.Items {
+		items = append(items, UserResponse{
+			ID:            int(row.ID),
+			Username:      nullStringValue(row.Username),
+			Email:         nullStringValue(row.Email),
+			Role:          row.Role,
+			EmailVerified: row.EmailVerifiedAt.Valid,
+			CreatedAt:     nullTimePtr(row.CreatedAt),
+		})
+	}
+	resp := newPageResponse(items, page)
+	writePage(w, r, resp, resp.NextCursor)
+}
+
+// SetRoleRequest carries the new role of a user.
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// SetUserRole handles PUT /api/admin/users/:id/role (admin only).
+//
+// Request body: SetRoleRequest with role (user, editor, moderator or admin)
+//
+// Security:
+// - Admins cannot change their own role
+// - The user's sessions are revoked so the new role applies immediately
+//
+// Returns: 200 OK with the user, 400 for an unknown role, 403 or 404
+func (a *AdminHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	user, err := a.Service.SetUserRole(r.Context(), actor, id, req.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		apierror.Write(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		writeWriteError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(UserResponse{
+		ID:            int(user.ID),
+		Username:      nullStringValue(user.Username),
+		Email:         nullStringValue(user.Email),
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		CreatedAt:     nullTimePtr(user.CreatedAt),
+	})
+}
+
+// SetAuthorRequest carries the new author of a recipe (0 for none).
+type SetAuthorRequest struct {
+	AuthorID int `json:"authorId"`
+}
+
+// SetRecipeAuthor handles PUT /api/admin/recipes/:id/author (admin only).
+//
+// Request body: SetAuthorRequest with authorId
+//
+// Hands a recipe to another user, who can then edit it without the editor
+// role. Seeded recipes start without an author.
+//
+// Returns: 204 No Content, 400 for an unknown user, or 404
+func (a *AdminHandler) SetRecipeAuthor(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	var req SetAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.AuthorID < 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.SetRecipeAuthor(r.Context(), actor, id, req.AuthorID); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReviewResponse is a recipe review (rating) as seen by moderators.
+type ReviewResponse struct {
+	ID          int        `json:"id"`
+	UserID      *int       `json:"userId,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	RecipeID    int        `json:"recipeId"`
+	RecipeTitle string     `json:"recipeTitle,omitempty"`
+	Rating      int        `json:"rating"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+// ListReviews handles GET /api/admin/reviews (moderator or admin).
+//
+// Query parameters:
+//   - limit: reviews per page (default 50, max 200)
+//   - cursor: nextCursor from the previous page
+//
+// Returns: 200 OK with a page of reviews, newest first
+func (a *AdminHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = min(n, 200)
+		}
+	}
+
+	page, err := a.Service.ListReviews(r.Context(), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writePageError(w, r, err)
+		return
+	}
+
+	items := make([]ReviewResponse, 0, len(page.Items))
+	for _, row := range page.Items {
+		review := ReviewResponse{
+			ID:          int(row.ID),
+			Username:    nullStringValue(row.Username),
+			RecipeID:    int(nullInt32Value(row.RecipeID)),
+			RecipeTitle: nullStringValue(row.RecipeTitle),
+			Rating:      int(nullInt32Value(row.Rating)),
+			CreatedAt:   nullTimePtr(row.CreatedAt),
+		}
+		if row.UserID.Valid {
+			uid := int(row.UserID.Int32)
+			review.UserID = &uid
+		}
+		items = append(items, review)
+	}
+	resp := newPageResponse(items, page)
+	writePage(w, r, resp, resp.NextCursor)
+}
+
+// DeleteReview handles DELETE /api/admin/reviews/:id (moderator or admin).
+//
+// Returns: 204 No Content, or 404
+func (a *AdminHandler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.DeleteReview(r.Context(), actor, id); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LexiconEntryResponse maps an ingredient variant to its canonical name.
+type LexiconEntryResponse struct {
+	Variant   string    `json:"variant"`
+	Canonical string    `json:"canonical"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ListIngredientLexicon handles GET /api/admin/ingredients (editor and up).
+//
+// Lists the admin-managed ingredient variants; the built-in ones are not
+// included.
+//
+// Returns: 200 OK with the entries in alphabetical order
+func (a *AdminHandler) ListIngredientLexicon(w http.ResponseWriter, r *http.Request) {
+	rows, err := a.Service.ListIngredientLexicon(r.Context())
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	out := make([]LexiconEntryResponse, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, LexiconEntryResponse{Variant: row.Variant, Canonical: row.Canonical, UpdatedAt: row.UpdatedAt})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// SetIngredientRequest carries the canonical name of a variant.
+type SetIngredientRequest struct {
+	Canonical string `json:"canonical"`
+}
+
+// SetIngredientVariant handles PUT /api/admin/ingredients/:variant (editor
+// and up).
+//
+// Request body: SetIngredientRequest with canonical
+//
+// Adds the variant to the lexicon used to parse detected ingredients, or
+// changes what it maps to. Overrides a built-in variant of the same name.
+//
+// Returns: 200 OK with the entry, or 400 with the invalid fields
+func (a *AdminHandler) SetIngredientVariant(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req SetIngredientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	row, err := a.Service.SetIngredientVariant(r.Context(), actor, chi.URLParam(r, "variant"), req.Canonical)
+	if err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(LexiconEntryResponse{Variant: row.Variant, Canonical: row.Canonical, UpdatedAt: row.UpdatedAt})
+}
+
+// DeleteIngredientVariant handles DELETE /api/admin/ingredients/:variant
+// (editor and up).
+//
+// Returns: 204 No Content, or 404 if the variant is not in the lexicon
+func (a *AdminHandler) DeleteIngredientVariant(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := a.Service.DeleteIngredientVariant(r.Context(), actor, chi.URLParam(r, "variant")); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
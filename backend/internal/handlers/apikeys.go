@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// APIKeyRequest describes an API key to create.
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional; keys without it do not expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse is an API key listed by /me/api-keys. Key is only set in
+// the response to creating the key.
+type APIKeyResponse struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+func apiKeyResponse(k db.ApiKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: nullTimePtr(k.LastUsedAt),
+		ExpiresAt:  nullTimePtr(k.ExpiresAt),
+	}
+}
+
+// CreateAPIKey handles POST /api/me/api-keys (requires authentication).
+//
+// Request body: APIKeyRequest with name, scopes and optional expires_at
+//
+// Security:
+// - Only a hash of the key is stored; the response is the only time it is shown
+// - Keys act for the user with their current role, limited to the granted scopes
+//
+// Returns: 201 Created with APIKeyResponse including key, 400 with field
+// errors for invalid input, or 409 once the user has too many keys
+func (a *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	created, err := a.Service.CreateAPIKey(r.Context(), userID, service.APIKeyInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if errors.Is(err, service.ErrTooManyAPIKeys) {
+		apierror.Write(w, http.StatusConflict, "too many api keys, revoke one first")
+		return
+	}
+	if err != nil {
+		if !writeInputError(w, err) {
+			apierror.Write(w, http.StatusInternalServerError, "could not create api key")
+		}
+		return
+	}
+
+	response := apiKeyResponse(created.ApiKey)
+	response.Key = created.Key
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// ListAPIKeys handles GET /api/me/api-keys (requires authentication).
+//
+// Returns: 200 OK with the user's API keys, newest first, without the keys
+// themselves
+func (a *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	keys, err := a.Service.ListAPIKeys(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	response := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		response[i] = apiKeyResponse(k)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// RevokeAPIKey handles DELETE /api/me/api-keys/:id (requires authentication).
+//
+// The key stops working immediately.
+//
+// Path parameters:
+//   - id: API key identifier
+//
+// Returns: 204 No Content, or 404 if the user has no such key
+func (a *AuthHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	keyID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	err = a.Service.RevokeAPIKey(r.Context(), userID, keyID)
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		apierror.Write(w, http.StatusNotFound, "api key not found")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,17 +3,59 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
 )
 
-// AuthHandler manages user authentication and registration endpoints.
+// AuthHandler manages user authentication, registration and session endpoints.
+// Token lifetimes and signing are configured on Service.Tokens.
 type AuthHandler struct {
-	Service   *service.Service
-	JWTSecret string
-	JWTExpiry int
+	Service *service.Service
+}
+
+// TokenResponse is returned by register, login and refresh.
+//
+// Token is a short-lived access token for the Authorization header;
+// RefreshToken is exchanged at /auth/refresh for a new pair and is only
+// valid once.
+type TokenResponse struct {
+	Token        string    `json:"token"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+// SessionResponse is a signed-in device listed by /me/sessions
+type SessionResponse struct {
+	ID         int32     `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+func sessionResponse(s db.ListUserSessionsRow) SessionResponse {
+	return SessionResponse{
+		ID:         s.ID,
+		UserAgent:  nullStringValue(s.UserAgent),
+		IPAddress:  nullStringValue(s.IpAddress),
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+	}
 }
 
 // RegisterRequest contains user registration information.
@@ -27,38 +69,38 @@ type RegisterRequest struct {
 //
 // Request body: RegisterRequest with username, email, and password
 //
+// Validation:
+// - username: 3-30 letters, digits, '.', '_' or '-'
+// - email: a bare, well-formed address
+// - password: 8-72 bytes mixing letters with digits or symbols
+//
 // Security:
 // - Password is hashed with bcrypt before storage
-// - Returns JWT token on successful registration
+// - Starts a session on successful registration
+// - Emails a link to verify the address; a failed send does not fail registration
 //
-// Returns: 200 OK with JWT token, or error status
+// Returns: 200 OK with TokenResponse, 400 with field errors for invalid
+// input, or 409 with field errors if the username or email is taken
 func (a *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	user, err := a.Service.CreateUser(r.Context(), req.Username, req.Email, req.Password)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "could not create user"})
+		if !writeInputError(w, err) {
+			apierror.Write(w, http.StatusInternalServerError, "could not create user")
+		}
 		return
 	}
 
-	token, err := auth.GenerateJWT(a.JWTSecret, int(user.ID), a.JWTExpiry)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "could not generate token"})
-		return
+	if err := a.Service.SendVerificationEmail(r.Context(), int(user.ID)); err != nil {
+		logging.FromContext(r.Context()).Error("verification email failed", slog.Int("user_id", int(user.ID)), slog.Any("error", err))
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+
+	a.startSession(w, r, int(user.ID))
 }
 
 // LoginRequest contains user login credentials.
@@ -74,34 +116,222 @@ type LoginRequest struct {
 // Security:
 // - Password is verified using bcrypt
 // - Returns generic error message to prevent user enumeration
-// - Issues JWT token on successful authentication
+// - Repeated failures temporarily lock out the email and the client IP
+// - Starts a session with an access and refresh token on success
 //
-// Returns: 200 OK with JWT token, or 401 Unauthorized
+// Returns: 200 OK with TokenResponse, 401 Unauthorized, or 429 Too Many
+// Requests with Retry-After while locked out
 func (a *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	user, err := a.Service.Login(r.Context(), req.Email, req.Password, sessionClient(r).IPAddress)
+	var locked *service.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		apierror.Write(w, http.StatusTooManyRequests, "too many failed login attempts, try again later")
+		return
+	case errors.Is(err, service.ErrAuthFailed):
+		apierror.Write(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	case err != nil:
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	a.startSession(w, r, int(user.ID))
+}
+
+// startSession issues the first token pair for a newly authenticated user.
+func (a *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, userID int) {
+	pair, err := a.Service.StartSession(r.Context(), userID, sessionClient(r))
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "could not generate token")
+		return
+	}
+	writeTokens(w, pair)
+}
+
+// RefreshRequest carries the refresh token to exchange.
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Refresh handles POST /api/auth/refresh to rotate a refresh token.
+//
+// Request body: RefreshRequest with refreshToken
+//
+// Security:
+// - Each refresh token works once; the response carries its replacement
+// - Reusing a rotated token revokes the whole session
+//
+// Returns: 200 OK with TokenResponse, or 401 Unauthorized
+func (a *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	pair, err := a.Service.RefreshSession(r.Context(), req.RefreshToken, sessionClient(r))
+	if errors.Is(err, service.ErrInvalidRefreshToken) {
+		apierror.Write(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "could not generate token")
+		return
+	}
+	writeTokens(w, pair)
+}
+
+// Logout handles POST /api/auth/logout (requires authentication).
+//
+// Revokes the session of the presented access token, so its refresh token
+// stops working, and denylists the access token itself.
+//
+// Returns: 204 No Content
+func (a *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*auth.Claims)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := a.Service.EndSession(r.Context(), claims); err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 
-	user, err := a.Service.Authenticate(r.Context(), req.Email, req.Password)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSessions handles GET /api/me/sessions (requires authentication).
+//
+// Returns: 200 OK with the user's active sessions; the one making the
+// request is flagged as current
+func (a *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*auth.Claims)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	list, err := a.Service.ListSessions(r.Context(), claims.UserID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	response := make([]SessionResponse, len(list))
+	for i, s := range list {
+		response[i] = sessionResponse(s)
+		response[i].Current = int(s.ID) == claims.SessionID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// RevokeSession handles DELETE /api/me/sessions/:id (requires authentication).
+//
+// Signs the device out: its refresh token stops working and its current
+// access token is denylisted.
+//
+// Path parameters:
+//   - id: session identifier
+//
+// Returns: 204 No Content, or 404 if the user has no such active session
+func (a *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*auth.Claims)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	sessionID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	err = a.Service.RevokeSession(r.Context(), claims.UserID, sessionID)
+	if errors.Is(err, service.ErrSessionNotFound) {
+		apierror.Write(w, http.StatusNotFound, "session not found")
+		return
+	}
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "invalid credentials"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 
-	token, err := auth.GenerateJWT(a.JWTSecret, int(user.ID), a.JWTExpiry)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeOtherSessions handles DELETE /api/me/sessions (requires authentication).
+//
+// Signs out every device except the one making the request.
+//
+// Returns: 200 OK with {"revoked": n}
+func (a *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(middleware.ClaimsKey).(*auth.Claims)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	n, err := a.Service.RevokeOtherSessions(r.Context(), claims.UserID, claims.SessionID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "could not generate token"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+	_ = json.NewEncoder(w).Encode(map[string]int{"revoked": n})
+}
+
+// JWKS handles GET /.well-known/jwks.json.
+//
+// Publishes the public keys access tokens are verified with, so other
+// services can validate tokens without sharing a secret. Keys that were
+// rotated out stay listed until the tokens they signed have expired.
+//
+// Returns: 200 OK with a JSON Web Key Set (empty when signing with HS256)
+func (a *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(a.Service.Tokens.Keys.JWKS())
+}
+
+func writeTokens(w http.ResponseWriter, pair service.TokenPair) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(TokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    pair.AccessExpiresAt,
+	})
+}
+
+// sessionClient describes the device making the request, with the client
+// IP middleware.RealIP resolved.
+func sessionClient(r *http.Request) service.SessionClient {
+	return service.SessionClient{UserAgent: r.UserAgent(), IPAddress: middleware.ClientIP(r)}
+}
+
+// requestActor returns the authenticated user and role from the principal
+// put in the context by JWTAuth or Authenticate.
+func requestActor(r *http.Request) (service.Actor, bool) {
+	principal, ok := r.Context().Value(middleware.PrincipalKey).(*auth.Principal)
+	if !ok || principal.UserID <= 0 {
+		return service.Actor{}, false
+	}
+	return service.Actor{UserID: principal.UserID, Role: principal.Role}, true
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// writeInputError writes 400 with field details for validation failures and
+// 409 for unique conflicts. It reports whether err was one of those; other
+// errors are left for the caller to handle.
+func writeInputError(w http.ResponseWriter, err error) bool {
+	var invalid validate.Errors
+	if errors.As(err, &invalid) {
+		apierror.WriteFields(w, http.StatusBadRequest, "validation failed", invalid)
+		return true
+	}
+	var conflict *service.ConflictError
+	if errors.As(err, &conflict) {
+		apierror.WriteFields(w, http.StatusConflict, conflict.Error(), conflict.Fields)
+		return true
+	}
+	return false
+}
+
+// writeAccessError writes 404 for a missing resource and 403 when the
+// acting user may not change it. It reports whether err was one of those.
+func writeAccessError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrRecipeNotFound),
+		errors.Is(err, service.ErrReviewNotFound),
+		errors.Is(err, service.ErrLexiconEntryNotFound):
+		apierror.Write(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrOwnRole):
+		apierror.Write(w, http.StatusForbidden, err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// writeWriteError maps errors of create, update and delete endpoints:
+// 400/409 for bad input, 403/404 for access, 500 for anything else.
+func writeWriteError(w http.ResponseWriter, err error) {
+	if writeInputError(w, err) || writeAccessError(w, err) {
+		return
+	}
+	apierror.Write(w, http.StatusInternalServerError, "server error")
+}
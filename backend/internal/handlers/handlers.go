@@ -5,13 +5,14 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
-	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/vision"
@@ -43,57 +44,110 @@ func New(s *service.Service, vs vision.VisionService, maxImageMB int) *Handler {
 // ListRecipes handles GET /api/recipes with search and filtering.
 //
 // Query parameters:
-//   - q: search query for title/tags
+//   - q: full-text query over title, tags, description, ingredients and steps;
+//     supports "quoted phrases", prefix*, -exclusions and OR
 //   - diet: dietary restriction (e.g., "vegetarian")
 //   - difficulty: "easy", "medium", or "hard"
 //   - cuisine: cuisine type filter
-//   - maxTime: maximum cooking time in minutes
+//   - tag: recipe tag filter
+//   - maxTotalTime, maxPrepTime, maxCookTime, minServings, maxServings:
+//     time and servings filters (see parseFilterSpec)
+//   - sort: "rating", "newest", "quickest" or "popular" (default: by ID)
+//   - facets: "true" to include facet counts
 //   - limit: results per page (default 50, max 200)
-//   - offset: pagination offset
+//   - cursor: nextCursor from the previous page
 //
-// Returns: 200 OK with recipe array or error
+// diet, difficulty, cuisine and tag accept several comma-separated values
+// (cuisine=Italian,Thai) and match any of them.
+//
+// Returns: 200 OK with {"items": [...], "nextCursor": ..., "total": n} and a
+// Link header to the next page, plus "facets" when requested; 400 on an
+// unknown sort or a cursor from a different query, or error
 func (h *Handler) ListRecipes(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query().Get("q")
-	diet := r.URL.Query().Get("diet")
-	difficulty := r.URL.Query().Get("difficulty")
-	cuisine := r.URL.Query().Get("cuisine")
-	maxTimeStr := r.URL.Query().Get("maxTime")
+	filters := parseFilterSpec(r)
+	withFacets, _ := strconv.ParseBool(r.URL.Query().Get("facets"))
+	sort, err := service.ParseSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, "invalid sort")
+		return
+	}
 	limit := 50
 	if v := r.URL.Query().Get("limit"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
 			limit = n
 		}
 	}
-	offset := 0
-	if v := r.URL.Query().Get("offset"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
-			offset = n
-		}
+
+	page, err := h.Service.SearchAndFilterRecipes(r.Context(), q, filters, sort, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writePageError(w, r, err)
+		return
 	}
-	var maxTimePtr *int
-	if maxTimeStr != "" {
-		if n, err := strconv.Atoi(maxTimeStr); err == nil && n > 0 {
-			maxTimePtr = &n
+
+	items := make([]SearchResultResponse, len(page.Items))
+	for i, r := range page.Items {
+		items[i] = SearchResultResponse{
+			RecipeDetailResponse: toSearchRecipeResponse(r.SearchRecipesRow),
+			Rank:                 r.Rank,
+			Snippet:              r.Snippet,
 		}
 	}
+	response := newPageResponse(items, page)
 
-	recipes, err := h.Service.SearchAndFilterRecipes(r.Context(), q, diet, difficulty, maxTimePtr, cuisine, limit, offset)
-	if err != nil {
-		println("SearchAndFilterRecipes error:", err.Error())
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "database error"})
+	if withFacets {
+		facets, err := h.Service.RecipeFacets(r.Context(), q, filters)
+		if err != nil {
+			apierror.Write(w, http.StatusInternalServerError, "database error")
+			return
+		}
+		writePage(w, r, FacetedSearchResponse{PageResponse: response, Facets: facets}, response.NextCursor)
 		return
 	}
 
-	response := make([]RecipeDetailResponse, len(recipes))
-	for i, r := range recipes {
-		response[i] = toSearchRecipeResponse(r)
-	}
+	writePage(w, r, response, response.NextCursor)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(response)
+// parseFilterSpec reads the recipe filter query parameters shared by
+// /recipes, /match and /suggestions.
+//
+// Query parameters:
+//   - diet, difficulty, cuisine, tag: repeated or comma-separated lists
+//   - maxTotalTime: maximum total time in minutes (maxTime is an alias)
+//   - maxPrepTime: maximum preparation time in minutes
+//   - maxCookTime: maximum cooking time in minutes
+//   - minServings, maxServings: servings range
+//
+// Invalid or non-positive numbers are ignored.
+func parseFilterSpec(r *http.Request) service.FilterSpec {
+	query := r.URL.Query()
+	spec := service.FilterSpec{
+		Diets:        service.SplitList(query["diet"]),
+		Difficulties: service.SplitList(query["difficulty"]),
+		Cuisines:     service.SplitList(query["cuisine"]),
+		Tags:         service.SplitList(query["tag"]),
+		MaxTotalTime: positiveIntParam(query.Get("maxTotalTime")),
+		MaxPrepTime:  positiveIntParam(query.Get("maxPrepTime")),
+		MaxCookTime:  positiveIntParam(query.Get("maxCookTime")),
+		MinServings:  positiveIntParam(query.Get("minServings")),
+		MaxServings:  positiveIntParam(query.Get("maxServings")),
+	}
+	if spec.MaxTotalTime == nil {
+		spec.MaxTotalTime = positiveIntParam(query.Get("maxTime"))
+	}
+	return spec
+}
+
+// positiveIntParam parses v as a positive integer, returning nil when v is
+// empty or invalid.
+func positiveIntParam(v string) *int {
+	if v == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return &n
+	}
+	return nil
 }
 
 // GetRecipe handles GET /api/recipes/:id to retrieve full recipe details.
@@ -101,18 +155,25 @@ func (h *Handler) ListRecipes(w http.ResponseWriter, r *http.Request) {
 // Path parameters:
 //   - id: recipe identifier
 //
+// Each successful lookup is recorded as a view for the trending feeds, once
+// per signed-in user or client IP within service.ViewWindow.
+//
 // Returns: 200 OK with recipe details, or 404 if not found
 func (h *Handler) GetRecipe(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, _ := strconv.Atoi(idStr)
 	recipe, err := h.Service.GetRecipe(r.Context(), id)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "recipe not found"})
+		apierror.Write(w, http.StatusNotFound, "recipe not found")
 		return
 	}
 
+	uid, _ := r.Context().Value(middleware.UserIDKey).(int)
+	viewer := service.Viewer{UserID: uid, IP: middleware.ClientIP(r)}
+	if err := h.Service.RecordView(r.Context(), id, viewer); err != nil {
+		logging.FromContext(r.Context()).Warn("recording recipe view failed", slog.Int("recipe_id", id), slog.Any("error", err))
+	}
+
 	response := toRecipeDetailResponse(recipe)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -127,63 +188,45 @@ type MatchRequest struct {
 // Match handles POST /api/match to find recipes matching ingredients.
 //
 // Request body: MatchRequest with detectedIngredients array
-// Query parameters: same as ListRecipes (diet, difficulty, etc.)
+// Query parameters: same filters as ListRecipes (diet, difficulty, etc.),
+// plus limit (default 50, max 200) and cursor
 //
-// Returns: 200 OK with scored recipes sorted by match relevance
+// Returns: 200 OK with a page of scored recipes sorted by match relevance,
+// in the same envelope as ListRecipes
 func (h *Handler) Match(w http.ResponseWriter, r *http.Request) {
 	var req MatchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
-	diet := r.URL.Query().Get("diet")
-	difficulty := r.URL.Query().Get("difficulty")
-	cuisine := r.URL.Query().Get("cuisine")
-	maxTimeStr := r.URL.Query().Get("maxTime")
+	filters := parseFilterSpec(r)
 	limit := 50
 	if v := r.URL.Query().Get("limit"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
 			limit = n
 		}
 	}
-	offset := 0
-	if v := r.URL.Query().Get("offset"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
-			offset = n
-		}
-	}
-	var maxTimePtr *int
-	if maxTimeStr != "" {
-		if n, err := strconv.Atoi(maxTimeStr); err == nil && n > 0 {
-			maxTimePtr = &n
-		}
-	}
 
-	recipes, err := h.Service.MatchWithFilters(r.Context(), req.DetectedIngredients, service.MatchFilters{
-		Diet: diet, Difficulty: difficulty, MaxTimeMinutes: maxTimePtr, Cuisine: cuisine, Limit: limit, Offset: offset,
+	page, err := h.Service.MatchWithFilters(r.Context(), req.DetectedIngredients, service.MatchFilters{
+		FilterSpec: filters, Limit: limit, After: r.URL.Query().Get("cursor"),
 	})
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		writePageError(w, r, err)
 		return
 	}
 	type RecipeWithScoreResponse struct {
 		RecipeDetailResponse
 		Score int `json:"score"`
 	}
-	response := make([]RecipeWithScoreResponse, len(recipes))
-	for i, r := range recipes {
-		response[i] = RecipeWithScoreResponse{
+	items := make([]RecipeWithScoreResponse, len(page.Items))
+	for i, r := range page.Items {
+		items[i] = RecipeWithScoreResponse{
 			RecipeDetailResponse: toSearchRecipeResponse(r.SearchRecipesRow),
 			Score:                r.Score,
 		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(response)
+	response := newPageResponse(items, page)
+	writePage(w, r, response, response.NextCursor)
 }
 
 // RatingRequest contains a user's recipe rating submission.
@@ -200,9 +243,7 @@ type RatingRequest struct {
 func (h *Handler) PostRating(w http.ResponseWriter, r *http.Request) {
 	var req RatingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
 
@@ -215,9 +256,7 @@ func (h *Handler) PostRating(w http.ResponseWriter, r *http.Request) {
 
 	rt, err := h.Service.AddRating(r.Context(), uid32, req.RecipeID, req.Rating)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -235,25 +274,19 @@ func (h *Handler) AddFavorite(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	recipeID, err := strconv.Atoi(idStr)
 	if err != nil || recipeID <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
 	v := r.Context().Value(middleware.UserIDKey)
 	id, ok := v.(int)
 	if !ok || id <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	fav, err := h.Service.AddFavorite(r.Context(), id, recipeID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -271,24 +304,18 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	recipeID, err := strconv.Atoi(idStr)
 	if err != nil || recipeID <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
 	v := r.Context().Value(middleware.UserIDKey)
 	id, ok := v.(int)
 	if !ok || id <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	if err := h.Service.RemoveFavorite(r.Context(), id, recipeID); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 
@@ -297,33 +324,40 @@ func (h *Handler) RemoveFavorite(w http.ResponseWriter, r *http.Request) {
 
 // ListFavorites handles GET /api/favorites (requires authentication).
 //
-// Returns: 200 OK with array of user's favorited recipes
+// Query parameters:
+//   - limit: favorites per page (default 50, max 200)
+//   - cursor: nextCursor from the previous page
+//
+// Returns: 200 OK with a page of the user's favorited recipes, newest first,
+// in the same envelope as ListRecipes
 func (h *Handler) ListFavorites(w http.ResponseWriter, r *http.Request) {
 	v := r.Context().Value(middleware.UserIDKey)
 	id, ok := v.(int)
 	if !ok || id <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	list, err := h.Service.ListFavorites(r.Context(), id)
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+
+	page, err := h.Service.ListFavoritesPage(r.Context(), id, limit, r.URL.Query().Get("cursor"))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		writePageError(w, r, err)
 		return
 	}
 
-	response := make([]FavoriteRecipeResponse, len(list))
-	for i, fav := range list {
-		response[i] = toFavoriteRecipeResponse(fav)
+	items := make([]FavoriteRecipeResponse, len(page.Items))
+	for i, fav := range page.Items {
+		items[i] = toFavoriteRecipeResponse(fav)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(response)
+	response := newPageResponse(items, page)
+	writePage(w, r, response, response.NextCursor)
 }
 
 // IsFavorite handles GET /api/favorites/:id/status (requires authentication).
@@ -336,25 +370,19 @@ func (h *Handler) IsFavorite(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	recipeID, err := strconv.Atoi(idStr)
 	if err != nil || recipeID <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "bad request"})
+		apierror.Write(w, http.StatusBadRequest, "bad request")
 		return
 	}
 	v := r.Context().Value(middleware.UserIDKey)
 	id, ok := v.(int)
 	if !ok || id <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	isFav, err := h.Service.IsFavorite(r.Context(), id, recipeID)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		apierror.Write(w, http.StatusInternalServerError, "server error")
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -371,31 +399,18 @@ func (h *Handler) IsFavorite(w http.ResponseWriter, r *http.Request) {
 // Returns: 200 OK with detected ingredients and confidence score
 func (h *Handler) DetectIngredients(w http.ResponseWriter, r *http.Request) {
 	if h.VisionService == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		_ = json.NewEncoder(w).Encode(map[string]interface{}{
-			"message":             "vision service not configured",
-			"detectedIngredients": []string{},
-		})
+		apierror.Write(w, http.StatusServiceUnavailable, "vision service not configured")
 		return
 	}
 
 	if err := r.ParseMultipartForm(h.MaxImageBytes); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"message": "image too large or invalid form data",
-		})
+		apierror.Write(w, http.StatusBadRequest, "image too large or invalid form data")
 		return
 	}
 
 	file, header, err := r.FormFile("image")
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"message": "no image file provided",
-		})
+		apierror.Write(w, http.StatusBadRequest, "no image file provided")
 		return
 	}
 	defer file.Close()
@@ -404,38 +419,26 @@ func (h *Handler) DetectIngredients(w http.ResponseWriter, r *http.Request) {
 	if header != nil {
 		filename = header.Filename
 		contentType := header.Header.Get("Content-Type")
-		if !isValidImageType(contentType) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_ = json.NewEncoder(w).Encode(map[string]string{
-				"message": "invalid image format. Supported: JPEG, PNG, GIF, WebP",
-			})
+		if !vision.SupportedImageType(contentType) {
+			apierror.Write(w, http.StatusBadRequest, "invalid image format. Supported: JPEG, PNG, GIF, WebP")
 			return
 		}
 	}
 
 	imageData, err := io.ReadAll(file)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"message": "failed to read image",
-		})
+		apierror.Write(w, http.StatusInternalServerError, "failed to read image")
 		return
 	}
 
 	if len(imageData) == 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(map[string]string{
-			"message": "empty image file",
-		})
+		apierror.Write(w, http.StatusBadRequest, "empty image file")
 		return
 	}
 
 	result, err := h.VisionService.DetectIngredients(r.Context(), imageData, filename)
 	if err != nil {
-		fmt.Printf("Vision API error: %v\n", err)
+		logging.FromContext(r.Context()).Warn("ingredient detection failed", slog.String("filename", filename), slog.Any("error", err))
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{
@@ -473,40 +476,22 @@ func (h *Handler) DetectIngredients(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
-// isValidImageType validates that the uploaded file is a supported image format.
-//
-// Supported types: JPEG, PNG, GIF, WebP
-func isValidImageType(contentType string) bool {
-	validTypes := []string{
-		"image/jpeg",
-		"image/jpg",
-		"image/png",
-		"image/gif",
-		"image/webp",
-	}
-	for _, t := range validTypes {
-		if strings.Contains(strings.ToLower(contentType), t) {
-			return true
-		}
-	}
-	return false
-}
-
 // GetSuggestions handles GET /api/suggestions (requires authentication).
 //
 // Generates personalized recipe recommendations based on user's favorites.
 //
 // Query parameters:
 //   - limit: maximum suggestions to return (default 10, max 100)
+//   - cursor: nextCursor from the previous page
+//   - filters: same as ListRecipes (diet, maxTotalTime, etc.)
 //
-// Returns: 200 OK with scored recipe suggestions
+// Returns: 200 OK with a page of scored recipe suggestions, in the same
+// envelope as ListRecipes
 func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
 	v := r.Context().Value(middleware.UserIDKey)
 	id, ok := v.(int)
 	if !ok || id <= 0 {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "unauthorized"})
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -517,25 +502,22 @@ func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	list, err := h.Service.GetSuggestions(r.Context(), id, limit)
+	page, err := h.Service.GetSuggestions(r.Context(), id, parseFilterSpec(r), limit, r.URL.Query().Get("cursor"))
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(map[string]string{"message": "server error"})
+		writePageError(w, r, err)
 		return
 	}
 	type RecipeWithScoreResponse struct {
 		RecipeDetailResponse
 		Score int `json:"score"`
 	}
-	response := make([]RecipeWithScoreResponse, len(list))
-	for i, r := range list {
-		response[i] = RecipeWithScoreResponse{
+	items := make([]RecipeWithScoreResponse, len(page.Items))
+	for i, r := range page.Items {
+		items[i] = RecipeWithScoreResponse{
 			RecipeDetailResponse: toSearchRecipeResponse(r.SearchRecipesRow),
 			Score:                r.Score,
 		}
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(response)
+	response := newPageResponse(items, page)
+	writePage(w, r, response, response.NextCursor)
 }
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/lib/pq"
 	"github.com/sqlc-dev/pqtype"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
 )
 
 // RecipeListResponse is a clean JSON response for recipe lists
@@ -43,6 +45,20 @@ type RecipeDetailResponse struct {
 	AverageRating    string      `json:"average_rating"`
 }
 
+// SearchResultResponse is a recipe in /recipes results. Rank and Snippet are
+// set for full-text queries; the snippet highlights matches with <mark> tags.
+type SearchResultResponse struct {
+	RecipeDetailResponse
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// FacetedSearchResponse is a /recipes page with facet counts attached
+type FacetedSearchResponse struct {
+	PageResponse[SearchResultResponse]
+	Facets service.Facets `json:"facets"`
+}
+
 func toRecipeListResponse(row db.ListRecipesRow) RecipeListResponse {
 	return RecipeListResponse{
 		ID:               row.ID,
@@ -113,6 +129,13 @@ func nullInt32Value(ni sql.NullInt32) int32 {
 	return 0
 }
 
+func nullTimePtr(nt sql.NullTime) *time.Time {
+	if nt.Valid {
+		return &nt.Time
+	}
+	return nil
+}
+
 func pqNullRawMessageValue(nrm pqtype.NullRawMessage) interface{} {
 	if nrm.Valid {
 		return nrm.RawMessage
@@ -162,7 +185,7 @@ type FavoriteRecipeResponse struct {
 	AverageRating    string `json:"average_rating"`
 }
 
-func toFavoriteRecipeResponse(row db.ListFavoritesByUserRow) FavoriteRecipeResponse {
+func toFavoriteRecipeResponse(row db.ListFavoritesByUserPageRow) FavoriteRecipeResponse {
 	return FavoriteRecipeResponse{
 		FavoriteID:       row.FavoriteID,
 		RecipeID:         nullInt32Value(row.RecipeID),
@@ -178,3 +201,39 @@ func toFavoriteRecipeResponse(row db.ListFavoritesByUserRow) FavoriteRecipeRespo
 		AverageRating:    interfaceToString(row.AverageRating),
 	}
 }
+
+// PopularRecipeResponse is a recipe entry in the trending and popular feeds
+type PopularRecipeResponse struct {
+	RecipeListResponse
+	Window         string    `json:"window"`
+	Score          float64   `json:"score"`
+	FavoritesCount int       `json:"favorites_count"`
+	RatingsCount   int       `json:"ratings_count"`
+	ViewsCount     int       `json:"views_count"`
+	RefreshedAt    time.Time `json:"refreshed_at"`
+}
+
+func toPopularRecipeResponse(row db.ListRecipesByPopularityRow, window service.PopularityWindow, score float64) PopularRecipeResponse {
+	return PopularRecipeResponse{
+		RecipeListResponse: RecipeListResponse{
+			ID:               row.ID,
+			Title:            row.Title,
+			Description:      nullStringValue(row.Description),
+			Cuisine:          nullStringValue(row.Cuisine),
+			Difficulty:       nullStringValue(row.Difficulty),
+			DietType:         nullStringValue(row.DietType),
+			PrepTimeMinutes:  int(nullInt32Value(row.PrepTimeMinutes)),
+			CookTimeMinutes:  int(nullInt32Value(row.CookTimeMinutes)),
+			TotalTimeMinutes: int(nullInt32Value(row.TotalTimeMinutes)),
+			Servings:         int(nullInt32Value(row.Servings)),
+			AverageRating:    interfaceToString(row.AverageRating),
+			Tags:             row.Tags,
+		},
+		Window:         string(window),
+		Score:          score,
+		FavoritesCount: int(row.FavoritesCount),
+		RatingsCount:   int(row.RatingsCount),
+		ViewsCount:     int(row.ViewsCount),
+		RefreshedAt:    row.RefreshedAt,
+	}
+}
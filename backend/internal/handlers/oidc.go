@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// OIDCProviderResponse is an identity provider users can sign in with.
+type OIDCProviderResponse struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	LoginURL    string `json:"loginUrl"`
+}
+
+// IdentityResponse is a provider identity listed by /me/identities.
+type IdentityResponse struct {
+	ID          int32     `json:"id"`
+	Provider    string    `json:"provider"`
+	Email       string    `json:"email,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
+
+func identityResponse(id db.ListUserIdentitiesRow) IdentityResponse {
+	return IdentityResponse{
+		ID:          id.ID,
+		Provider:    id.Provider,
+		Email:       nullStringValue(id.Email),
+		CreatedAt:   id.CreatedAt,
+		LastLoginAt: id.LastLoginAt,
+	}
+}
+
+// ListOIDCProviders handles GET /api/auth/oidc/providers.
+//
+// Returns: 200 OK with the configured identity providers and the path that
+// starts a login with each
+func (a *AuthHandler) ListOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	providers := a.Service.OIDCProviders()
+	response := make([]OIDCProviderResponse, len(providers))
+	for i, p := range providers {
+		response[i] = OIDCProviderResponse{
+			Name:        p.Name(),
+			DisplayName: p.DisplayName(),
+			LoginURL:    "/auth/oidc/" + p.Name() + "/login",
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// OIDCLogin handles GET /api/auth/oidc/:provider/login.
+//
+// Starts the authorization code flow: the user is redirected to the
+// provider, which sends them back to the provider's redirect URL with a
+// code and state for OIDCCallback.
+//
+// Security:
+// - Uses PKCE (S256); the verifier never leaves the server
+// - State and nonce are single-use and expire after OIDC_STATE_TTL
+//
+// Returns: 302 Found to the provider, or 404 for an unknown provider
+func (a *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	url, err := a.Service.StartOIDCLogin(r.Context(), chi.URLParam(r, "provider"))
+	if errors.Is(err, service.ErrUnknownProvider) {
+		apierror.Write(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		logging.FromContext(r.Context()).Error("oidc login start failed", slog.Any("error", err))
+		apierror.Write(w, http.StatusBadGateway, "identity provider unavailable")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// OIDCCallback handles GET /api/auth/oidc/:provider/callback.
+//
+// Query parameters: code and state as sent back by the provider; the
+// frontend page at the redirect URL forwards them unchanged
+//
+// Security:
+// - The ID token signature is checked against the provider's JWKS, along with issuer, audience, expiry and nonce
+// - A new identity is linked to an existing account only if both the provider and the account verified its email
+//
+// Returns: 200 OK with TokenResponse, 400 for an invalid state or missing
+// email, 401 if the provider refused the login, 404 for an unknown
+// provider, or 409 if the email belongs to an account it cannot be
+// linked to
+func (a *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if providerErr := q.Get("error"); providerErr != "" {
+		apierror.Write(w, http.StatusUnauthorized, "sign-in was refused by the identity provider: "+providerErr)
+		return
+	}
+	if q.Get("code") == "" || q.Get("state") == "" {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	client := sessionClient(r)
+	userID, err := a.Service.FinishOIDCLogin(r.Context(), chi.URLParam(r, "provider"), q.Get("state"), q.Get("code"), client.IPAddress)
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider):
+		apierror.Write(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrInvalidOIDCState), errors.Is(err, service.ErrOIDCEmailRequired):
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, service.ErrOIDCLoginFailed):
+		logging.FromContext(r.Context()).Warn("oidc login failed", slog.Any("error", err))
+		apierror.Write(w, http.StatusUnauthorized, service.ErrOIDCLoginFailed.Error())
+		return
+	case errors.Is(err, service.ErrOIDCEmailUnverified):
+		apierror.Write(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		logging.FromContext(r.Context()).Error("oidc login failed", slog.Any("error", err))
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	a.startSession(w, r, userID)
+}
+
+// ListIdentities handles GET /api/me/identities (requires authentication).
+//
+// Returns: 200 OK with the identity providers linked to the account
+func (a *AuthHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	identities, err := a.Service.ListIdentities(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	response := make([]IdentityResponse, len(identities))
+	for i, id := range identities {
+		response[i] = identityResponse(id)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
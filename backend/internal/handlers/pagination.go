@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// PageResponse is the envelope for cursor-paginated listings.
+//
+// NextCursor is null on the last page; pass it back as ?cursor= to fetch the
+// next one. Total counts every matching item across all pages.
+type PageResponse[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"nextCursor"`
+	Total      int     `json:"total"`
+}
+
+// newPageResponse wraps converted items with the paging state of p.
+func newPageResponse[T, S any](items []T, p service.Page[S]) PageResponse[T] {
+	resp := PageResponse[T]{Items: items, Total: p.Total}
+	if p.NextCursor != "" {
+		next := p.NextCursor
+		resp.NextCursor = &next
+	}
+	return resp
+}
+
+// setNextLink adds an RFC 8288 Link header pointing at the next page: the
+// request URL with its cursor parameter replaced.
+func setNextLink(w http.ResponseWriter, r *http.Request, nextCursor *string) {
+	if nextCursor == nil {
+		return
+	}
+	q := r.URL.Query()
+	q.Set("cursor", *nextCursor)
+	u := *r.URL
+	u.RawQuery = q.Encode()
+	w.Header().Set("Link", "<"+u.RequestURI()+`>; rel="next"`)
+}
+
+// writePage writes a paginated 200 response with its Link header.
+func writePage(w http.ResponseWriter, r *http.Request, body any, nextCursor *string) {
+	setNextLink(w, r, nextCursor)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writePageError maps a paging error to 400 for a bad cursor and to 500
+// otherwise. Only the latter is logged, as clients cause the former.
+func writePageError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, service.ErrInvalidCursor) {
+		apierror.WriteFields(w, http.StatusBadRequest, "invalid cursor", []validate.FieldError{{Field: "cursor", Message: "is not valid for this listing"}})
+		return
+	}
+	logging.FromContext(r.Context()).Error("listing failed", slog.Any("error", err))
+	apierror.Write(w, http.StatusInternalServerError, "server error")
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// ListTrending handles GET /api/recipes/trending.
+//
+// Ranks recipes by time-decayed favorites, ratings and views.
+//
+// Query parameters:
+//   - window: "day", "week" or "all" (default "week")
+//   - limit: results per page (default 20, max 100)
+//   - offset: pagination offset
+//
+// Returns: 200 OK with ranked recipes, or 400 on an unknown window
+func (h *Handler) ListTrending(w http.ResponseWriter, r *http.Request) {
+	h.listByPopularity(w, r, service.RankTrending)
+}
+
+// ListPopular handles GET /api/recipes/popular.
+//
+// Ranks recipes by total favorites, ratings and views within the window.
+//
+// Query parameters:
+//   - window: "day", "week" or "all" (default "week")
+//   - limit: results per page (default 20, max 100)
+//   - offset: pagination offset
+//
+// Returns: 200 OK with ranked recipes, or 400 on an unknown window
+func (h *Handler) ListPopular(w http.ResponseWriter, r *http.Request) {
+	h.listByPopularity(w, r, service.RankPopular)
+}
+
+func (h *Handler) listByPopularity(w http.ResponseWriter, r *http.Request, rankBy string) {
+	window, err := service.ParsePopularityWindow(r.URL.Query().Get("window"))
+	if err != nil {
+		apierror.Write(w, http.StatusBadRequest, "invalid window")
+		return
+	}
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 && n <= 100 {
+			limit = n
+		}
+	}
+	offset := 0
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	var rows []db.ListRecipesByPopularityRow
+	if rankBy == service.RankTrending {
+		rows, err = h.Service.ListTrendingRecipes(r.Context(), window, limit, offset)
+	} else {
+		rows, err = h.Service.ListPopularRecipes(r.Context(), window, limit, offset)
+	}
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "database error")
+		return
+	}
+
+	response := make([]PopularRecipeResponse, len(rows))
+	for i, row := range rows {
+		score := row.PopularScore
+		if rankBy == service.RankTrending {
+			score = row.TrendingScore
+		}
+		response[i] = toPopularRecipeResponse(row, window, score)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
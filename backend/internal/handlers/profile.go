@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/middleware"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// ProfileResponse is the signed-in user's account as returned by /me.
+type ProfileResponse struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	// PendingEmail is a new address awaiting confirmation.
+	PendingEmail  string     `json:"pending_email,omitempty"`
+	EmailVerified bool       `json:"email_verified"`
+	DisplayName   string     `json:"display_name,omitempty"`
+	AvatarURL     string     `json:"avatar_url,omitempty"`
+	Role          string     `json:"role"`
+	HasPassword   bool       `json:"has_password"`
+	CreatedAt     *time.Time `json:"created_at"`
+}
+
+func profileResponse(p db.GetUserProfileRow) ProfileResponse {
+	return ProfileResponse{
+		ID:            p.ID,
+		Username:      nullStringValue(p.Username),
+		Email:         nullStringValue(p.Email),
+		PendingEmail:  nullStringValue(p.PendingEmail),
+		EmailVerified: p.EmailVerifiedAt.Valid,
+		DisplayName:   nullStringValue(p.DisplayName),
+		AvatarURL:     nullStringValue(p.AvatarUrl),
+		Role:          p.Role,
+		HasPassword:   p.HasPassword,
+		CreatedAt:     nullTimePtr(p.CreatedAt),
+	}
+}
+
+// ProfileRequest changes profile fields. Omitted fields are left as they
+// are; an empty display_name or avatar_url clears it.
+type ProfileRequest struct {
+	Username    *string `json:"username"`
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// EmailChangeRequest names the new email address, confirmed with the
+// current password.
+type EmailChangeRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// DeleteAccountRequest confirms deleting the account with the current
+// password.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+// ExportResponse is the archive returned by /me/export. In the zip format
+// each top-level field is a separate JSON file.
+type ExportResponse struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	Profile     ProfileResponse    `json:"profile"`
+	Preferences ExportPreferences  `json:"preferences"`
+	Favorites   []ExportFavorite   `json:"favorites"`
+	Ratings     []ExportRating     `json:"ratings"`
+	Recipes     []ExportRecipe     `json:"recipes"`
+	History     ExportHistory      `json:"history"`
+	Sessions    []SessionResponse  `json:"sessions"`
+	APIKeys     []APIKeyResponse   `json:"api_keys"`
+	Identities  []IdentityResponse `json:"identities"`
+}
+
+// ExportPreferences are the tastes inferred from the user's favorites.
+type ExportPreferences struct {
+	// FavoriteTags counts recipe tags among favorites; suggestions are
+	// ranked by them.
+	FavoriteTags map[string]int `json:"favorite_tags"`
+}
+
+// ExportFavorite is a recipe the user saved.
+type ExportFavorite struct {
+	RecipeID  int32      `json:"recipe_id"`
+	Title     string     `json:"title"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// ExportRating is a rating the user gave.
+type ExportRating struct {
+	ID          int32      `json:"id"`
+	RecipeID    int32      `json:"recipe_id"`
+	RecipeTitle string     `json:"recipe_title"`
+	Rating      int32      `json:"rating"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+// ExportRecipe is a recipe the user wrote.
+type ExportRecipe struct {
+	ID        int32      `json:"id"`
+	Title     string     `json:"title"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// ExportHistory is what the user looked at and the security events of the
+// account.
+type ExportHistory struct {
+	Views  []ExportView  `json:"views"`
+	Events []ExportEvent `json:"events"`
+}
+
+// ExportView is a recipe page view.
+type ExportView struct {
+	RecipeID    int32      `json:"recipe_id"`
+	RecipeTitle string     `json:"recipe_title"`
+	ViewedAt    *time.Time `json:"viewed_at"`
+}
+
+// ExportEvent is an audit log entry about the user.
+type ExportEvent struct {
+	Event     string          `json:"event"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	Details   json.RawMessage `json:"details,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func exportResponse(data service.AccountData) ExportResponse {
+	e := ExportResponse{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     profileResponse(data.Profile),
+		Preferences: ExportPreferences{FavoriteTags: data.FavoriteTags},
+		Favorites:   make([]ExportFavorite, len(data.Favorites)),
+		Ratings:     make([]ExportRating, len(data.Ratings)),
+		Recipes:     make([]ExportRecipe, len(data.Recipes)),
+		History: ExportHistory{
+			Views:  make([]ExportView, len(data.Views)),
+			Events: make([]ExportEvent, len(data.Events)),
+		},
+		Sessions:   make([]SessionResponse, len(data.Sessions)),
+		APIKeys:    make([]APIKeyResponse, len(data.APIKeys)),
+		Identities: make([]IdentityResponse, len(data.Identities)),
+	}
+	for i, f := range data.Favorites {
+		e.Favorites[i] = ExportFavorite{RecipeID: nullInt32Value(f.RecipeID), Title: f.Title, CreatedAt: nullTimePtr(f.CreatedAt)}
+	}
+	for i, r := range data.Ratings {
+		e.Ratings[i] = ExportRating{
+			ID:          r.ID,
+			RecipeID:    nullInt32Value(r.RecipeID),
+			RecipeTitle: nullStringValue(r.RecipeTitle),
+			Rating:      nullInt32Value(r.Rating),
+			CreatedAt:   nullTimePtr(r.CreatedAt),
+		}
+	}
+	for i, r := range data.Recipes {
+		e.Recipes[i] = ExportRecipe{ID: r.ID, Title: r.Title, CreatedAt: nullTimePtr(r.CreatedAt), UpdatedAt: nullTimePtr(r.UpdatedAt)}
+	}
+	for i, v := range data.Views {
+		e.History.Views[i] = ExportView{RecipeID: nullInt32Value(v.RecipeID), RecipeTitle: nullStringValue(v.RecipeTitle), ViewedAt: nullTimePtr(v.ViewedAt)}
+	}
+	for i, ev := range data.Events {
+		e.History.Events[i] = ExportEvent{Event: ev.Event, IPAddress: nullStringValue(ev.IpAddress), CreatedAt: ev.CreatedAt}
+		if ev.Details.Valid {
+			e.History.Events[i].Details = ev.Details.RawMessage
+		}
+	}
+	for i, s := range data.Sessions {
+		e.Sessions[i] = sessionResponse(s)
+	}
+	for i, k := range data.APIKeys {
+		e.APIKeys[i] = apiKeyResponse(k)
+	}
+	for i, id := range data.Identities {
+		e.Identities[i] = identityResponse(id)
+	}
+	return e
+}
+
+// GetProfile handles GET /api/me (requires authentication).
+//
+// Returns: 200 OK with ProfileResponse
+func (a *AuthHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	profile, err := a.Service.GetProfile(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(profileResponse(profile))
+}
+
+// UpdateProfile handles PATCH /api/me (requires authentication).
+//
+// Request body: ProfileRequest with any of username, display_name and
+// avatar_url
+//
+// Returns: 200 OK with ProfileResponse, 400 with field errors for invalid
+// input, or 409 if the username is taken
+func (a *AuthHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req ProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	profile, err := a.Service.UpdateProfile(r.Context(), userID, service.ProfileInput{
+		Username:    req.Username,
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+	})
+	if err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(profileResponse(profile))
+}
+
+// ChangeEmail handles PUT /api/me/email (requires authentication).
+//
+// Request body: EmailChangeRequest with email and password
+//
+// Sends a confirmation link to the new address, which replaces the current
+// one once the link is followed (see ConfirmEmail). The current address is
+// notified of the request.
+//
+// Security:
+// - The current password must be confirmed, unless the account has none
+//
+// Returns: 202 Accepted, 400 with field errors for an invalid address, 403
+// if the password is wrong, or 409 if the address is registered
+func (a *AuthHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req EmailChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.RequestEmailChange(r.Context(), userID, req.Email, req.Password); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ConfirmEmail handles POST /api/auth/confirm-email.
+//
+// Request body: VerifyEmailRequest with the token from the confirmation
+// email
+//
+// Returns: 204 No Content, 400 for an invalid or expired token, or 409 if
+// another account took the address meanwhile
+func (a *AuthHandler) ConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.ConfirmEmailChange(r.Context(), req.Token); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportAccount handles GET /api/me/export (requires authentication).
+//
+// Query parameters:
+//   - format: "json" (default) or "zip"
+//
+// Returns: 200 OK with ExportResponse as a JSON attachment, or as a zip
+// with one JSON file per section
+func (a *AuthHandler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		apierror.WriteFields(w, http.StatusBadRequest, "validation failed", []validate.FieldError{{Field: "format", Message: "must be json or zip"}})
+		return
+	}
+
+	data, err := a.Service.ExportAccount(r.Context(), userID)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	export := exportResponse(data)
+	filename := fmt.Sprintf("smart-recipe-export-%s.%s", export.ExportedAt.Format("20060102"), format)
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(export)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.WriteHeader(http.StatusOK)
+	_ = writeExportZip(w, export)
+}
+
+// writeExportZip writes each section of an export as its own JSON file.
+func writeExportZip(w io.Writer, export ExportResponse) error {
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"preferences.json", export.Preferences},
+		{"favorites.json", export.Favorites},
+		{"ratings.json", export.Ratings},
+		{"recipes.json", export.Recipes},
+		{"history.json", export.History},
+		{"sessions.json", export.Sessions},
+		{"api_keys.json", export.APIKeys},
+		{"identities.json", export.Identities},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// DeleteAccount handles DELETE /api/me (requires authentication).
+//
+// Request body: DeleteAccountRequest with password; may be omitted for
+// accounts without a password
+//
+// Permanently deletes the account with its favorites, ratings, sessions,
+// API keys and linked identities. Recipes the user wrote are kept without
+// an author.
+//
+// Security:
+// - The current password must be confirmed, unless the account has none
+// - Every session is revoked and outstanding access tokens are denylisted
+// - The deletion is written to the audit log
+//
+// Returns: 204 No Content, or 403 if the password is wrong
+func (a *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := a.Service.DeleteAccount(r.Context(), userID, req.Password, sessionClient(r).IPAddress); err != nil {
+		writeProfileError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeProfileError maps profile and account errors to 400/403/409 and
+// anything else to 500.
+func writeProfileError(w http.ResponseWriter, err error) {
+	switch {
+	case writeInputError(w, err):
+	case errors.Is(err, service.ErrInvalidAccountToken):
+		apierror.Write(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrWrongPassword):
+		apierror.WriteFields(w, http.StatusForbidden, "password is incorrect", []validate.FieldError{{Field: "password", Message: "is incorrect"}})
+	default:
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+	}
+}
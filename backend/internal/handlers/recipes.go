@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/service"
+)
+
+// RecipeRequest is the body of recipe create and update requests.
+type RecipeRequest struct {
+	Title            string          `json:"title"`
+	Description      string          `json:"description"`
+	Cuisine          string          `json:"cuisine"`
+	Difficulty       string          `json:"difficulty"`
+	DietType         string          `json:"dietType"`
+	PrepTimeMinutes  int             `json:"prepTimeMinutes"`
+	CookTimeMinutes  int             `json:"cookTimeMinutes"`
+	TotalTimeMinutes int             `json:"totalTimeMinutes"`
+	Servings         int             `json:"servings"`
+	Tags             []string        `json:"tags"`
+	Ingredients      json.RawMessage `json:"ingredients"`
+	Steps            json.RawMessage `json:"steps"`
+	Nutrition        json.RawMessage `json:"nutrition"`
+}
+
+func (req RecipeRequest) input() service.RecipeInput {
+	return service.RecipeInput{
+		Title:            req.Title,
+		Description:      req.Description,
+		Cuisine:          req.Cuisine,
+		Difficulty:       req.Difficulty,
+		DietType:         req.DietType,
+		PrepTimeMinutes:  req.PrepTimeMinutes,
+		CookTimeMinutes:  req.CookTimeMinutes,
+		TotalTimeMinutes: req.TotalTimeMinutes,
+		Servings:         req.Servings,
+		Tags:             req.Tags,
+		Ingredients:      req.Ingredients,
+		Steps:            req.Steps,
+		Nutrition:        req.Nutrition,
+	}
+}
+
+// CreateRecipe handles POST /api/recipes (requires authentication).
+//
+// Request body: RecipeRequest; title, ingredients and steps are required
+//
+// The authenticated user becomes the recipe's author.
+//
+// Returns: 201 Created with the recipe, or 400 with the invalid fields
+func (h *Handler) CreateRecipe(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req RecipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	id, err := h.Service.CreateRecipe(r.Context(), actor.UserID, req.input())
+	if err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	h.writeRecipe(w, r, id, http.StatusCreated)
+}
+
+// UpdateRecipe handles PUT /api/recipes/:id (requires authentication).
+//
+// Request body: RecipeRequest, replacing the whole recipe
+//
+// Security:
+// - Authors may edit their own recipes
+// - Editors, moderators and admins may edit any recipe
+//
+// Returns: 200 OK with the recipe, 400, 403 or 404
+func (h *Handler) UpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+	var req RecipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := h.Service.UpdateRecipe(r.Context(), actor, id, req.input()); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	h.writeRecipe(w, r, id, http.StatusOK)
+}
+
+// DeleteRecipe handles DELETE /api/recipes/:id (requires authentication).
+//
+// Security:
+// - Authors may delete their own recipes
+// - Editors, moderators and admins may delete any recipe
+//
+// Returns: 204 No Content, 403 or 404
+func (h *Handler) DeleteRecipe(w http.ResponseWriter, r *http.Request) {
+	actor, ok := requestActor(r)
+	if !ok {
+		apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		apierror.Write(w, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	if err := h.Service.DeleteRecipe(r.Context(), actor, id); err != nil {
+		writeWriteError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeRecipe writes the current state of recipe id with status.
+func (h *Handler) writeRecipe(w http.ResponseWriter, r *http.Request, id, status int) {
+	recipe, err := h.Service.GetRecipe(r.Context(), id)
+	if err != nil {
+		apierror.Write(w, http.StatusInternalServerError, "server error")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(toRecipeDetailResponse(recipe))
+}
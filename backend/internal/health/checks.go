@@ -0,0 +1,58 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// undefinedTable is the Postgres error code for a missing table.
+const undefinedTable = "42P01"
+
+// Ping checks that the database accepts connections.
+func Ping(db *sql.DB) ProbeFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		if err := db.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		stats := db.Stats()
+		return map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+		}, nil
+	}
+}
+
+// Migrations checks that the database schema is at version want, as
+// recorded by golang-migrate in the schema_migrations table, and that no
+// migration failed half-way. A newer schema is accepted, so instances still
+// running the previous release stay ready while a deployment that migrated
+// the database rolls out.
+func Migrations(db *sql.DB, want uint) ProbeFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		var (
+			version int64
+			dirty   bool
+		)
+		err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, sql.ErrNoRows), errors.As(err, &pqErr) && pqErr.Code == undefinedTable:
+			return map[string]any{"expected": want}, errors.New("no migrations applied")
+		case err != nil:
+			return nil, err
+		}
+
+		details := map[string]any{"version": version, "expected": want, "dirty": dirty}
+		switch {
+		case dirty:
+			return details, fmt.Errorf("migration %d failed and must be fixed by hand", version)
+		case version < int64(want):
+			return details, fmt.Errorf("schema at version %d, expected %d", version, want)
+		}
+		return details, nil
+	}
+}
@@ -0,0 +1,205 @@
+// Package health reports whether the server and the services it depends on
+// are able to handle requests.
+//
+// Liveness only says the process is running and serving HTTP; it never looks
+// at dependencies, so an orchestrator does not restart a healthy server
+// because the database is briefly unreachable. Readiness runs a Check per
+// component and combines them into a Report: a critical component that is
+// down makes the server unavailable, a non-critical one only degrades it,
+// e.g. recipes keep working while ingredient detection is down.
+//
+// When the server shuts down, Drain fails readiness at once so load
+// balancers stop sending requests while those in flight finish.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Component statuses.
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Overall statuses of a Report.
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+)
+
+// defaultTimeout bounds a probe whose Check sets no Timeout.
+const defaultTimeout = 2 * time.Second
+
+// ProbeFunc checks one component. It returns details worth reporting, such
+// as the version of a dependency, and an error if the component cannot
+// serve requests.
+type ProbeFunc func(ctx context.Context) (map[string]any, error)
+
+// Check is a component of the readiness report.
+type Check struct {
+	Name string
+	// Critical components make the server unavailable when down; the
+	// others only degrade it.
+	Critical bool
+	// Timeout bounds each probe. Default: 2s.
+	Timeout time.Duration
+	// CacheTTL reuses a probe's result for this long, so a slow or remote
+	// dependency is not probed on every readiness request. Zero probes every
+	// time.
+	CacheTTL time.Duration
+	Probe    ProbeFunc
+}
+
+// Component is the result of a Check.
+type Component struct {
+	Status    string         `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMS float64        `json:"latency_ms"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Report is the readiness of the server and each of its components.
+type Report struct {
+	Status string `json:"status"`
+	// Draining is set once the server is shutting down; components are not
+	// probed then.
+	Draining   bool                 `json:"draining,omitempty"`
+	Components map[string]Component `json:"components"`
+}
+
+// Checker runs the readiness checks.
+type Checker struct {
+	checks   []*cachedCheck
+	draining atomic.Bool
+}
+
+// cachedCheck is a Check with its last result. mu is held while probing, so
+// concurrent readiness requests wait for one probe instead of each starting
+// their own.
+type cachedCheck struct {
+	Check
+
+	mu      sync.Mutex
+	last    Component
+	expires time.Time
+}
+
+// NewChecker creates a Checker running checks.
+func NewChecker(checks ...Check) *Checker {
+	c := &Checker{}
+	for _, check := range checks {
+		c.checks = append(c.checks, &cachedCheck{Check: check})
+	}
+	return c
+}
+
+// Drain marks the server as shutting down: from now on the report is
+// unavailable whatever the state of its components.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs every check concurrently, reusing cached results, and
+// returns the combined report.
+func (c *Checker) Check(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusUnavailable, Draining: true, Components: map[string]Component{}}
+	}
+	results := make([]Component, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = check.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Component, len(c.checks))}
+	for i, check := range c.checks {
+		comp := results[i]
+		report.Components[check.Name] = comp
+		if comp.Status == StatusUp {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run returns the cached result of the check or probes the component.
+func (c *cachedCheck) run(ctx context.Context) Component {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Now().Before(c.expires) {
+		return c.last
+	}
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.Probe(probeCtx)
+	comp := Component{
+		Status:    StatusUp,
+		Critical:  c.Critical,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt: start.UTC(),
+		Details:   details,
+	}
+	if err != nil {
+		comp.Status = StatusDown
+		comp.Error = err.Error()
+	}
+
+	// A probe cut short by the caller going away says nothing about the
+	// component, so it is not cached.
+	if ctx.Err() == nil {
+		c.last, c.expires = comp, start.Add(c.CacheTTL)
+	}
+	return comp
+}
+
+// Handler serves the readiness report as JSON: 200 OK when the server is
+// ready, even if degraded, and 503 Service Unavailable otherwise.
+func (c *Checker) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+		status := http.StatusOK
+		if report.Status == StatusUnavailable {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+// Live serves the liveness check: 200 OK for as long as the server can
+// answer HTTP requests at all.
+func Live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
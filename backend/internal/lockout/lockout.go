@@ -0,0 +1,190 @@
+// Package lockout tracks failed login attempts and temporarily locks out
+// accounts and clients that keep failing.
+//
+// Each attempt is counted under two keys, the account (submitted email) and
+// the client (IP address), with separate policies. Once a key reaches its
+// policy's MaxFailures it is locked for BaseDelay, and every further failure
+// doubles the lock up to MaxDelay. Counters are forgotten after Window
+// without failures.
+//
+// Attempt state lives in a pluggable Store: MemoryStore for a single
+// instance or PostgresStore to share it between instances.
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Policy configures when a key is locked and for how long.
+type Policy struct {
+	// MaxFailures is the number of failures that triggers the first lock.
+	// Zero disables locking for the key.
+	MaxFailures int
+	// BaseDelay is the length of the first lock.
+	BaseDelay time.Duration
+	// MaxDelay caps the lock length; it should be at least BaseDelay.
+	MaxDelay time.Duration
+	// Window is how long after the last failure (or the end of the last
+	// lock) the failure count is kept.
+	Window time.Duration
+}
+
+// LockDuration returns how long a key with the given number of consecutive
+// failures is locked for: 0 below MaxFailures, then BaseDelay doubling per
+// further failure, capped at MaxDelay.
+func (p Policy) LockDuration(failures int) time.Duration {
+	if p.MaxFailures <= 0 || failures < p.MaxFailures {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := p.MaxFailures; i < failures && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// State is the failure record of one key.
+type State struct {
+	Failures    int
+	LockedUntil time.Time
+}
+
+// Remaining returns how much longer the key is locked at now, or 0.
+func (s State) Remaining(now time.Time) time.Duration {
+	if s.LockedUntil.After(now) {
+		return s.LockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// Store persists attempt state. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Get returns the state of key; unknown keys have a zero State.
+	Get(ctx context.Context, key string) (State, error)
+	// Fail records a failure for key at now under policy p, locking the key
+	// when p says so, and returns the new state.
+	Fail(ctx context.Context, key string, p Policy, now time.Time) (State, error)
+	// Reset forgets key, lifting any lock.
+	Reset(ctx context.Context, key string) error
+	// Prune forgets keys whose last failure and lock both ended before
+	// cutoff.
+	Prune(ctx context.Context, cutoff time.Time) error
+}
+
+// AccountKey is the store key for login attempts on an email address.
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ClientKey is the store key for login attempts from an IP address.
+func ClientKey(ip string) string {
+	return "ip:" + ip
+}
+
+// expired reports whether a record whose last failure was at lastFailure is
+// past the policy window at now. The window is counted from the later of the
+// last failure and the end of the lock, so escalating locks do not reset the
+// count by outlasting the window.
+func (s State) expired(lastFailure time.Time, p Policy, now time.Time) bool {
+	if p.Window <= 0 {
+		return false
+	}
+	last := lastFailure
+	if s.LockedUntil.After(last) {
+		last = s.LockedUntil
+	}
+	return last.Before(now.Add(-p.Window))
+}
+
+// Attempt identifies a login attempt by the submitted email and the
+// client's IP address. Either may be empty.
+type Attempt struct {
+	Email string
+	IP    string
+}
+
+// Guard applies the account and client policies to login attempts.
+type Guard struct {
+	Store   Store
+	Account Policy
+	Client  Policy
+}
+
+// keys returns the store keys of an attempt with their policies.
+func (g *Guard) keys(a Attempt) map[string]Policy {
+	keys := make(map[string]Policy, 2)
+	if a.Email != "" {
+		keys[AccountKey(a.Email)] = g.Account
+	}
+	if a.IP != "" {
+		keys[ClientKey(a.IP)] = g.Client
+	}
+	return keys
+}
+
+// Check returns how long the attempt must wait before it may be tried, or 0
+// if neither the account nor the client is locked.
+func (g *Guard) Check(ctx context.Context, a Attempt, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for key := range g.keys(a) {
+		st, err := g.Store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if d := st.Remaining(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt against the account and the client and
+// returns the lock now in effect, or 0.
+func (g *Guard) Fail(ctx context.Context, a Attempt, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for key, p := range g.keys(a) {
+		st, err := g.Store.Fail(ctx, key, p, now)
+		if err != nil {
+			return 0, err
+		}
+		if d := st.Remaining(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// Succeed clears the account's failures after a successful login. The
+// client's counter is kept, so one valid account cannot be used to reset
+// the lock on an IP address that is guessing others.
+func (g *Guard) Succeed(ctx context.Context, a Attempt) error {
+	if a.Email == "" {
+		return nil
+	}
+	return g.Store.Reset(ctx, AccountKey(a.Email))
+}
+
+// Unlock lifts the locks and failure counts of the account and the client.
+func (g *Guard) Unlock(ctx context.Context, a Attempt) error {
+	for key := range g.keys(a) {
+		if err := g.Store.Reset(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prune forgets records that are past both policy windows.
+func (g *Guard) Prune(ctx context.Context, now time.Time) error {
+	window := g.Account.Window
+	if g.Client.Window > window {
+		window = g.Client.Window
+	}
+	return g.Store.Prune(ctx, now.Add(-window))
+}
@@ -0,0 +1,69 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempt state in process memory. State is lost on
+// restart and not shared between instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	State
+	lastFailure time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+// Get returns the state of key.
+func (m *MemoryStore) Get(ctx context.Context, key string) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries[key].State, nil
+}
+
+// Fail records a failure for key.
+func (m *MemoryStore) Fail(ctx context.Context, key string, p Policy, now time.Time) (State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.entries[key]
+	if e.expired(e.lastFailure, p, now) {
+		e = memoryEntry{}
+	}
+	e.Failures++
+	e.lastFailure = now
+	if d := p.LockDuration(e.Failures); d > 0 {
+		e.LockedUntil = now.Add(d)
+	}
+	m.entries[key] = e
+	return e.State, nil
+}
+
+// Reset forgets key.
+func (m *MemoryStore) Reset(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// Prune forgets stale keys.
+func (m *MemoryStore) Prune(ctx context.Context, cutoff time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.entries {
+		if e.lastFailure.Before(cutoff) && e.LockedUntil.Before(cutoff) {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package lockout
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// PostgresStore keeps attempt state in the login_attempts table, so every
+// instance of the API sees the same counters and locks.
+type PostgresStore struct {
+	q *db.Queries
+}
+
+// NewPostgresStore creates a store backed by the given database.
+func NewPostgresStore(conn db.DBTX) *PostgresStore {
+	return &PostgresStore{q: db.New(conn)}
+}
+
+// Get returns the state of key.
+func (s *PostgresStore) Get(ctx context.Context, key string) (State, error) {
+	row, err := s.q.GetLoginAttempt(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return State{Failures: int(row.Failures), LockedUntil: row.LockedUntil.Time}, nil
+}
+
+// Fail records a failure for key. The counter is updated atomically; the
+// lock is applied by a second statement.
+func (s *PostgresStore) Fail(ctx context.Context, key string, p Policy, now time.Time) (State, error) {
+	windowStart := time.Time{}
+	if p.Window > 0 {
+		windowStart = now.Add(-p.Window)
+	}
+	row, err := s.q.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		Key:         key,
+		Now:         now,
+		WindowStart: windowStart,
+	})
+	if err != nil {
+		return State{}, err
+	}
+
+	st := State{Failures: int(row.Failures), LockedUntil: row.LockedUntil.Time}
+	if d := p.LockDuration(st.Failures); d > 0 {
+		st.LockedUntil = now.Add(d)
+		if err := s.q.SetLoginLock(ctx, db.SetLoginLockParams{
+			Key:         key,
+			LockedUntil: sql.NullTime{Time: st.LockedUntil, Valid: true},
+		}); err != nil {
+			return State{}, err
+		}
+	}
+	return st, nil
+}
+
+// Reset forgets key.
+func (s *PostgresStore) Reset(ctx context.Context, key string) error {
+	return s.q.DeleteLoginAttempt(ctx, key)
+}
+
+// Prune forgets stale keys.
+func (s *PostgresStore) Prune(ctx context.Context, cutoff time.Time) error {
+	return s.q.DeleteStaleLoginAttempts(ctx, cutoff)
+}
@@ -0,0 +1,50 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+)
+
+// FileMailer writes each message as an .eml file in Dir instead of sending
+// it. It is meant for development and tests, where the files stand in for a
+// mailbox.
+type FileMailer struct {
+	Dir  string
+	From string
+
+	seq atomic.Int64
+}
+
+// Send writes msg to a new file in Dir.
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if err := validHeader(msg.To, msg.Subject); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%04d.eml", time.Now().UTC().Format("20060102T150405.000Z"), m.seq.Add(1))
+	return os.WriteFile(filepath.Join(m.Dir, name), format(m.From, msg), 0o600)
+}
+
+// LogMailer writes messages to the application log instead of sending them.
+// It is the fallback when no SMTP server or mail directory is configured.
+type LogMailer struct {
+	From string
+}
+
+// Send logs msg, including its body.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if err := validHeader(msg.To, msg.Subject); err != nil {
+		return err
+	}
+	logging.FromContext(ctx).Info("mail", slog.String("to", msg.To), slog.String("subject", msg.Subject), slog.String("body", msg.Body))
+	return nil
+}
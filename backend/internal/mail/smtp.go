@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+// SMTPMailer sends email through an SMTP server. STARTTLS is used when the
+// server offers it; credentials are only sent over TLS or to localhost, as
+// enforced by net/smtp.
+type SMTPMailer struct {
+	// Addr is the server's host:port.
+	Addr string
+	// Username and Password enable PLAIN authentication when set.
+	Username string
+	Password string
+	// From is the sender address.
+	From string
+}
+
+// Send delivers msg through the SMTP server.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := validHeader(msg.To, msg.Subject); err != nil {
+		return err
+	}
+
+	var a smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return err
+		}
+		a = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	// net/smtp does not take a context; honour cancellation before dialling.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(m.Addr, a, m.From, []string{msg.To}, format(m.From, msg))
+}
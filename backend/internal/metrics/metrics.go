@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+)
+
+// Label values recorded for authentication outcomes.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultLocked  = "locked"
+	ResultError   = "error"
+)
+
+// Metrics are the application's instruments. All methods are safe to call
+// on a nil *Metrics, which records nothing, so components work without
+// metrics configured.
+type Metrics struct {
+	// Registry serves the instruments below and any registered later.
+	Registry *Registry
+
+	httpDuration      *HistogramVec
+	visionDuration    *HistogramVec
+	visionFailures    *CounterVec
+	visionIngredients *HistogramVec
+	matchResults      *HistogramVec
+	logins            *CounterVec
+	authentications   *CounterVec
+}
+
+// New creates the application's instruments in a new registry.
+func New() *Metrics {
+	r := NewRegistry()
+	return &Metrics{
+		Registry: r,
+		httpDuration: r.NewHistogramVec("http_request_duration_seconds",
+			"Duration of HTTP requests by method, chi route pattern and status code.",
+			[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			"method", "route", "status"),
+		visionDuration: r.NewHistogramVec("vision_request_duration_seconds",
+			"Duration of ingredient detection calls by provider, failed ones included.",
+			[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			"provider"),
+		visionFailures: r.NewCounterVec("vision_failures_total",
+			"Ingredient detection calls that failed, by provider.",
+			"provider"),
+		visionIngredients: r.NewHistogramVec("vision_detected_ingredients",
+			"Number of ingredients found by successful detection calls, by provider.",
+			[]float64{0, 1, 2, 3, 5, 8, 13, 20},
+			"provider"),
+		matchResults: r.NewHistogramVec("recipe_match_results",
+			"Number of recipes returned per ingredient match request.",
+			[]float64{0, 1, 5, 10, 20, 50, 100, 200}),
+		logins: r.NewCounterVec("auth_logins_total",
+			"Sign-in attempts by method (password, oidc) and result (success, failure, locked).",
+			"method", "result"),
+		authentications: r.NewCounterVec("auth_requests_total",
+			"Requests carrying credentials by authenticator (jwt, api_key) and result (success, failure, error).",
+			"authenticator", "result"),
+	}
+}
+
+// ObserveHTTP records a served request. route is the chi route pattern,
+// never the raw path, to keep the number of series bounded.
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpDuration.Observe(d.Seconds(), method, route, strconv.Itoa(status))
+}
+
+// ObserveDetection records an ingredient detection call. Failed calls
+// count as failures; successful ones record the number of ingredients.
+func (m *Metrics) ObserveDetection(provider string, d time.Duration, ingredients int, err error) {
+	if m == nil {
+		return
+	}
+	m.visionDuration.Observe(d.Seconds(), provider)
+	if err != nil {
+		m.visionFailures.Inc(provider)
+		return
+	}
+	m.visionIngredients.Observe(float64(ingredients), provider)
+}
+
+// ObserveMatch records the number of recipes an ingredient match returned.
+func (m *Metrics) ObserveMatch(results int) {
+	if m == nil {
+		return
+	}
+	m.matchResults.Observe(float64(results))
+}
+
+// ObserveLogin records a sign-in attempt with one of the Result* values.
+func (m *Metrics) ObserveLogin(method, result string) {
+	if m == nil {
+		return
+	}
+	m.logins.Inc(method, result)
+}
+
+// ObserveAuthentication records the outcome of checking a request's
+// credentials with one of the Result* values.
+func (m *Metrics) ObserveAuthentication(authenticator, result string) {
+	if m == nil {
+		return
+	}
+	m.authentications.Inc(authenticator, result)
+}
+
+// ObserveDB exposes the connection pool statistics of db, read on every
+// scrape.
+func (m *Metrics) ObserveDB(db *sql.DB) {
+	if m == nil {
+		return
+	}
+	stat := func(f func(sql.DBStats) float64) func() float64 {
+		return func() float64 { return f(db.Stats()) }
+	}
+	r := m.Registry
+	r.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	r.NewGaugeFunc("db_open_connections", "Established connections, in use and idle.",
+		stat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	r.NewGaugeFunc("db_in_use_connections", "Connections currently in use.",
+		stat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	r.NewGaugeFunc("db_idle_connections", "Idle connections.",
+		stat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	r.NewCounterFunc("db_wait_count_total", "Connections waited for because the pool was exhausted.",
+		stat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for a connection.",
+		stat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	r.NewCounterFunc("db_max_idle_closed_total", "Connections closed because of DB_MAX_IDLE_CONNS.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	r.NewCounterFunc("db_max_idle_time_closed_total", "Connections closed because of DB_CONN_MAX_IDLE.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	r.NewCounterFunc("db_max_lifetime_closed_total", "Connections closed because of DB_CONN_MAX_LIFE.",
+		stat(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
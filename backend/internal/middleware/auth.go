@@ -4,10 +4,14 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
 )
 
 // ctxKey is a custom type for context keys to avoid collisions.
@@ -17,41 +21,213 @@ type ctxKey string
 // Handlers can retrieve the user ID from the request context using this key.
 const UserIDKey ctxKey = "userId"
 
-// JWTAuth returns a middleware function that validates JWT tokens.
-// It extracts the token from the Authorization header (format: "Bearer <token>"),
-// validates it, and stores the user ID in the request context.
+// ClaimsKey is the context key used to store the validated token claims
+// (*auth.Claims), for handlers that need the token or session ID. It is
+// only set for requests authenticated with an access token.
+const ClaimsKey ctxKey = "claims"
+
+// PrincipalKey is the context key used to store the authenticated caller
+// (*auth.Principal), however it authenticated.
+const PrincipalKey ctxKey = "principal"
+
+// ErrNoCredentials is returned by an Authenticator when the request does not
+// carry the kind of credentials it checks, so the next one should be tried.
+var ErrNoCredentials = errors.New("no credentials")
+
+// ErrBadCredentials is returned by an Authenticator when the request carries
+// its kind of credentials but they are invalid, expired or revoked.
+var ErrBadCredentials = errors.New("bad credentials")
+
+// Authenticator identifies the caller of a request from one kind of
+// credentials.
+//
+// Authenticate returns ErrNoCredentials if the request has none of them,
+// ErrBadCredentials if they are not valid, and any other error if they
+// could not be checked.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*auth.Principal, error)
+}
+
+// TokenVerifier validates an access token and returns its claims.
+// *auth.KeyRing implements it.
+type TokenVerifier interface {
+	Parse(tokenStr string) (*auth.Claims, error)
+}
+
+// TokenDenylist reports whether an access token has been revoked by its ID.
+type TokenDenylist interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// BearerJWT authenticates access tokens sent as "Authorization: Bearer <token>".
+// Tokens whose ID (jti) is on the denylist are rejected even if they have
+// not expired yet.
+type BearerJWT struct {
+	// Verifier validates token signatures, e.g. the application key ring.
+	Verifier TokenVerifier
+	// Denylist looks up revoked tokens; nil disables revocation checks.
+	Denylist TokenDenylist
+}
+
+// Authenticate implements Authenticator.
+func (b BearerJWT) Authenticate(r *http.Request) (*auth.Principal, error) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return nil, ErrNoCredentials
+	}
+
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, ErrBadCredentials
+	}
+
+	claims, err := b.Verifier.Parse(parts[1])
+	if err != nil {
+		return nil, ErrBadCredentials
+	}
+
+	if b.Denylist != nil && claims.ID != "" {
+		revoked, err := b.Denylist.IsTokenRevoked(r.Context(), claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, ErrBadCredentials
+		}
+	}
+
+	return &auth.Principal{UserID: claims.UserID, Role: claims.Role, Claims: claims}, nil
+}
+
+// APIKeyStore resolves API keys to the users they act for.
+type APIKeyStore interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// APIKeyHeader is the request header API keys are sent in.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey authenticates personal API keys sent in the X-API-Key header.
+type APIKey struct {
+	Store APIKeyStore
+}
+
+// Authenticate implements Authenticator.
+func (a APIKey) Authenticate(r *http.Request) (*auth.Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+	p, err := a.Store.AuthenticateAPIKey(r.Context(), key)
+	if errors.Is(err, auth.ErrInvalidAPIKey) {
+		return nil, ErrBadCredentials
+	}
+	return p, err
+}
+
+// Authenticate returns a middleware that identifies the caller with the
+// first authenticator whose credentials the request carries, and stores the
+// principal, user ID and (for access tokens) claims in the request context.
 //
 // Protected routes should use this middleware to ensure authentication.
-// If authentication fails, returns 401 Unauthorized.
+// Returns 401 Unauthorized when no authenticator applies or the credentials
+// are invalid, and 500 if they could not be checked.
 //
 // Parameters:
-//   - secret: The secret key used to verify JWT token signatures
+//   - authenticators: credential checks to try, in order
 //
 // Returns a middleware function that can be chained with Chi router.
-func JWTAuth(secret string) func(http.Handler) http.Handler {
+func Authenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
+			var principal *auth.Principal
+			for _, a := range authenticators {
+				p, err := a.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+				if errors.Is(err, ErrBadCredentials) {
+					apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+					return
+				}
+				if err != nil {
+					logging.FromContext(r.Context()).Error("authentication failed", slog.Any("error", err))
+					apierror.Write(w, http.StatusInternalServerError, "server error")
+					return
+				}
+				principal = p
+				break
 			}
-
-			parts := strings.SplitN(authHeader, " ", 2)
-			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			if principal == nil {
+				apierror.Write(w, http.StatusUnauthorized, "unauthorized")
 				return
 			}
 
-			tokenString := parts[1]
-			claims, err := auth.ParseJWT(secret, tokenString)
-			if err != nil {
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
-				return
-			}
+			next.ServeHTTP(w, withPrincipal(r, principal))
+		})
+	}
+}
 
-			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-			next.ServeHTTP(w, r.WithContext(ctx))
+// Identify returns a middleware that authenticates the request if it
+// carries valid credentials, and otherwise lets it through anonymously.
+// Public routes use it where knowing the caller helps, such as rate
+// limiting signed-in users per account rather than per IP address.
+//
+// Parameters:
+//   - authenticators: credential checks to try, in order
+//
+// Returns a middleware function that can be chained with Chi router.
+func Identify(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, a := range authenticators {
+				p, err := a.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+				if err == nil {
+					r = withPrincipal(r, p)
+				}
+				break
+			}
+			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// withPrincipal stores the authenticated caller in the request context and
+// tags the request's log lines with the user ID.
+func withPrincipal(r *http.Request, principal *auth.Principal) *http.Request {
+	setLogUser(r.Context(), principal.UserID)
+	return r.WithContext(WithPrincipal(r.Context(), principal))
+}
+
+// WithPrincipal returns ctx carrying the authenticated caller under
+// PrincipalKey, UserIDKey and, for access tokens, ClaimsKey, with a logger
+// tagging lines with the user ID. Servers other than the HTTP one use it to
+// authenticate the way Authenticate does.
+func WithPrincipal(ctx context.Context, principal *auth.Principal) context.Context {
+	ctx = logging.With(ctx, slog.Int("user_id", principal.UserID))
+	ctx = context.WithValue(ctx, UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, PrincipalKey, principal)
+	if principal.Claims != nil {
+		ctx = context.WithValue(ctx, ClaimsKey, principal.Claims)
+	}
+	return ctx
+}
+
+// JWTAuth returns a middleware function that validates JWT tokens.
+// It extracts the token from the Authorization header (format: "Bearer <token>"),
+// validates it, and stores the user ID in the request context.
+//
+// Routes that API keys may not use, such as session and account management,
+// should use this middleware; see Authenticate for the other routes.
+//
+// Parameters:
+//   - verifier: validates token signatures, e.g. the application key ring
+//   - denylist: revoked token lookup (nil disables revocation checks)
+//
+// Returns a middleware function that can be chained with Chi router.
+func JWTAuth(verifier TokenVerifier, denylist TokenDenylist) func(http.Handler) http.Handler {
+	return Authenticate(BearerJWT{Verifier: verifier, Denylist: denylist})
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// clientIPKey is the context key of the client IP address resolved by
+// RealIP.
+const clientIPKey ctxKey = "clientIP"
+
+// RealIP returns a middleware that resolves the IP address of the client a
+// request came from, for logging, tracing, rate limiting and sessions to
+// read with ClientIP.
+//
+// Only requests from a trusted proxy have their X-Forwarded-For or
+// X-Real-IP header read, as anyone else can set them to any address. The
+// client is the last address of X-Forwarded-For that is not a trusted
+// proxy itself, so addresses a client prepends are never picked up.
+//
+// Parameters:
+//   - trusted: addresses of the reverse proxies in front of the API; none
+//     means the peer address is always the client
+//
+// Returns a middleware function that can be chained with Chi router.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := ResolveClientIP(r, trusted)
+			next.ServeHTTP(w, r.WithContext(WithClientIP(r.Context(), ip)))
+		})
+	}
+}
+
+// ClientIP returns the IP address of the client a request came from: the
+// one RealIP resolved, or the peer address for requests it did not handle.
+func ClientIP(r *http.Request) string {
+	if ip := ContextClientIP(r.Context()); ip != "" {
+		return ip
+	}
+	return peerIP(r)
+}
+
+// WithClientIP returns ctx carrying ip as the client IP address, for
+// servers other than the HTTP one to resolve it the way RealIP does.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey, ip)
+}
+
+// ContextClientIP returns the client IP RealIP stored in ctx, or "" for
+// contexts of requests it did not handle.
+func ContextClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// ResolveClientIP returns the client address of r, reading the forwarding
+// headers only when the peer is one of the trusted proxies.
+func ResolveClientIP(r *http.Request, trusted []netip.Prefix) string {
+	peer := peerIP(r)
+	if !isTrusted(peer, trusted) {
+		return peer
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		client := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				// A malformed hop was not added by a trusted proxy, so
+				// nothing before it can be trusted.
+				break
+			}
+			client = addr.Unmap().String()
+			if !isTrusted(client, trusted) {
+				return client
+			}
+		}
+		if client != "" {
+			return client
+		}
+	}
+	if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return addr.Unmap().String()
+	}
+	return peer
+}
+
+// isTrusted reports whether ip is within one of the trusted prefixes.
+func isTrusted(ip string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIP returns the address of the connection's peer.
+func peerIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
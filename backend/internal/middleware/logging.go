@@ -2,20 +2,163 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
 )
 
-// Logging is a middleware that logs HTTP requests with method, path, and duration.
-// Logs are written in the format: "METHOD PATH DURATION"
-// Example: "GET /recipes/123 15.2ms"
+// maxRequestIDLength bounds the incoming request IDs that are honoured.
+const maxRequestIDLength = 128
+
+// RequestID is a middleware that gives every request an ID. A well-formed
+// X-Request-ID sent by the client or a proxy in front of the server is kept;
+// otherwise a random one is generated. The ID is echoed in the X-Request-ID
+// response header and stored in the request context together with a logger
+// that tags every line with it (see logging.FromContext).
+//
+// This middleware should be applied globally, before Logging.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(logging.RequestIDHeader, id)
+
+		ctx := logging.WithRequestID(r.Context(), id)
+		ctx = logging.With(ctx, slog.String("request_id", id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validRequestID accepts IDs of up to maxRequestIDLength characters made of
+// letters, digits and the punctuation common in trace and UUID formats, so
+// an ID cannot inject anything into logs or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '+', c == '/', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes, hex encoded.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestLog collects what the access log line reports about a request but
+// only becomes known further down the chain.
+type requestLog struct {
+	userID int
+}
+
+const requestLogKey ctxKey = "requestLog"
+
+// withRequestLog returns the request log of ctx, adding one if the request
+// has none yet, so middleware that runs early and late shares it.
+func withRequestLog(ctx context.Context) (context.Context, *requestLog) {
+	if rl, ok := ctx.Value(requestLogKey).(*requestLog); ok {
+		return ctx, rl
+	}
+	rl := &requestLog{}
+	return context.WithValue(ctx, requestLogKey, rl), rl
+}
+
+// setLogUser records the authenticated user for the access log line.
+func setLogUser(ctx context.Context, userID int) {
+	if rl, ok := ctx.Value(requestLogKey).(*requestLog); ok {
+		rl.userID = userID
+	}
+}
+
+// statusRecorder captures the status code and body size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	if s.status == 0 {
+		s.status = code
+	}
+	s.ResponseWriter.WriteHeader(code)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// Logging is a middleware that writes one structured log line per request
+// with its method, path, route pattern, status, response size, duration,
+// client IP and, for authenticated requests, the user ID. Server errors are
+// logged at error level, everything else at info.
+//
+// Example (text format):
 //
-// This middleware should be applied globally to log all incoming requests.
+//	level=INFO msg=request request_id=4f1c… method=GET path=/recipes/123 route=/recipes/{id} status=200 bytes=812 duration_ms=15.2 user_id=7
+//
+// This middleware should be applied globally, after RequestID and Tracing,
+// to log all incoming requests.
 func Logging(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
+		ctx, rl := withRequestLog(r.Context())
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+		}
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			attrs = append(attrs, slog.String("route", rctx.RoutePattern()))
+		}
+		attrs = append(attrs,
+			slog.Int("status", status),
+			slog.Int("bytes", rec.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", ClientIP(r)),
+		)
+		if rl.userID != 0 {
+			attrs = append(attrs, slog.Int("user_id", rl.userID))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logging.FromContext(r.Context()).LogAttrs(r.Context(), level, "request", attrs...)
 	})
 }
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
+)
+
+// unmatchedRoute is the route label of requests no route matched, so
+// arbitrary paths do not each create a series.
+const unmatchedRoute = "unmatched"
+
+// otherMethod is the method label of requests with a method outside
+// metricMethods, which clients can make up at will.
+const otherMethod = "OTHER"
+
+// metricMethods are the methods recorded under their own label.
+var metricMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// Metrics returns a middleware that records the duration of every request
+// by method, chi route pattern and status code.
+//
+// Parameters:
+//   - m: application metrics; nil disables the middleware
+//
+// Returns a middleware function that can be chained with Chi router.
+func Metrics(m *metrics.Metrics) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			method := r.Method
+			if !metricMethods[method] {
+				method = otherMethod
+			}
+			m.ObserveHTTP(method, route, status, time.Since(start))
+		})
+	}
+}
+
+// MetricsAccess returns a middleware that serves the metrics endpoint only
+// to scrapers sending "Authorization: Bearer <token>". Other requests get
+// 401 Unauthorized.
+//
+// Parameters:
+//   - token: the scrapers' token; empty lets every request through
+//
+// Returns a middleware function that can be chained with Chi router.
+func MetricsAccess(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if token == "" {
+			return next
+		}
+		want := []byte("Bearer " + token)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CountedAuthenticator wraps an Authenticator and counts the outcomes of
+// requests that carry its kind of credentials.
+type CountedAuthenticator struct {
+	// Name labels the outcomes, e.g. "jwt" or "api_key".
+	Name          string
+	Authenticator Authenticator
+	Metrics       *metrics.Metrics
+}
+
+// Authenticate implements Authenticator.
+func (c CountedAuthenticator) Authenticate(r *http.Request) (*auth.Principal, error) {
+	p, err := c.Authenticator.Authenticate(r)
+	switch {
+	case errors.Is(err, ErrNoCredentials):
+	case err == nil:
+		c.Metrics.ObserveAuthentication(c.Name, metrics.ResultSuccess)
+	case errors.Is(err, ErrBadCredentials):
+		c.Metrics.ObserveAuthentication(c.Name, metrics.ResultFailure)
+	default:
+		c.Metrics.ObserveAuthentication(c.Name, metrics.ResultError)
+	}
+	return p, err
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/ratelimit"
+)
+
+// RateLimit returns a middleware that throttles requests under the named
+// policy of limiter. Requests are counted per user, whether made with an
+// access token or an API key, or, for anonymous requests, per client IP
+// (see RateLimitKey), so it should run after any authentication middleware
+// of the route.
+//
+// Every response carries RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers. Requests over the limit get
+// 429 Too Many Requests with Retry-After. If the limiter's store fails the
+// request is let through, so an outage of the store does not take the API
+// down with it.
+//
+// Parameters:
+//   - limiter: buckets and policies; nil disables rate limiting
+//   - policy: name of the policy in limiter.Policies
+//
+// Returns a middleware function that can be chained with Chi router.
+func RateLimit(limiter *ratelimit.Limiter, policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil || !limiter.Policy(policy).Enabled() {
+			return next
+		}
+		header := limiter.Policy(policy).String()
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := RateLimitKey(r)
+			res, err := limiter.Allow(r.Context(), policy, key, time.Now())
+			if err != nil {
+				logging.FromContext(r.Context()).Error("rate limit check failed", slog.String("policy", policy), slog.Any("error", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", HeaderSeconds(res.Reset))
+			h.Set("RateLimit-Policy", header)
+			if !res.Allowed {
+				h.Set("Retry-After", HeaderSeconds(res.RetryAfter))
+				LogRateLimited(r.Context(), policy, key)
+				apierror.Write(w, http.StatusTooManyRequests, "rate limit exceeded, try again later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitKey returns the bucket key of a request: the authenticated user
+// (see PrincipalRateLimitKey) or, for anonymous requests, the client IP
+// address.
+func RateLimitKey(r *http.Request) string {
+	if principal, ok := r.Context().Value(PrincipalKey).(*auth.Principal); ok {
+		return PrincipalRateLimitKey(principal)
+	}
+	if userID, ok := r.Context().Value(UserIDKey).(int); ok {
+		return ratelimit.UserKey(userID)
+	}
+	return ratelimit.ClientKey(ClientIP(r))
+}
+
+// PrincipalRateLimitKey returns the bucket key of an authenticated caller.
+// Requests made with an API key count against the key's owner, so that
+// creating more keys does not raise a user's limits.
+func PrincipalRateLimitKey(p *auth.Principal) string {
+	return ratelimit.UserKey(p.UserID)
+}
+
+// LogRateLimited logs a request refused under policy, labelled with the
+// bucket key and, for requests made with an API key, the key's ID, so the
+// key of a user running into limits can be told apart.
+func LogRateLimited(ctx context.Context, policy, key string) {
+	attrs := []any{slog.String("policy", policy), slog.String("key", key)}
+	if p, ok := ctx.Value(PrincipalKey).(*auth.Principal); ok && p.APIKeyID != 0 {
+		attrs = append(attrs, slog.Int("api_key_id", p.APIKeyID))
+	}
+	logging.FromContext(ctx).Info("rate limit exceeded", attrs...)
+}
+
+// HeaderSeconds formats d as whole seconds, rounded up, as the rate limit
+// headers carry it.
+func HeaderSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+)
+
+// RequireRole returns a middleware that only lets through users whose role
+// grants at least min (see auth.Role). It must run after JWTAuth or
+// Authenticate, which put the principal in the context.
+//
+// Returns 401 Unauthorized without a principal and 403 Forbidden for a
+// lower role.
+//
+// Parameters:
+//   - min: lowest role allowed through
+//
+// Returns a middleware function that can be chained with Chi router.
+func RequireRole(min auth.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := r.Context().Value(PrincipalKey).(*auth.Principal)
+			if !ok {
+				apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			if !principal.Role.AtLeast(min) {
+				apierror.Write(w, http.StatusForbidden, "forbidden")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireScope returns a middleware that only lets through API keys granted
+// scope. Access tokens are not scoped and always pass. It must run after
+// Authenticate.
+//
+// Returns 401 Unauthorized without a principal and 403 Forbidden for a key
+// without the scope.
+//
+// Parameters:
+//   - scope: scope the route needs
+//
+// Returns a middleware function that can be chained with Chi router.
+func RequireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := r.Context().Value(PrincipalKey).(*auth.Principal)
+			if !ok {
+				apierror.Write(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+			if !principal.HasScope(scope) {
+				apierror.Write(w, http.StatusForbidden, "api key lacks scope "+string(scope))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
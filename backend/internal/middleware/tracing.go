@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/tracing"
+)
+
+// Tracing returns a middleware that records a server span for every
+// request. A trace started by the caller is continued from the W3C
+// traceparent header. The span is named after the method and chi route
+// pattern, and the request's logger is tagged with the trace and span IDs
+// so log lines can be found from a trace.
+//
+// Parameters:
+//   - tracer: the application tracer; nil disables the middleware
+//
+// Returns a middleware function that can be chained with Chi router.
+func Tracing(tracer *tracing.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if tracer == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := tracing.Extract(r.Context(), r.Header)
+			ctx, span := tracer.Start(ctx, r.Method, tracing.WithKind(tracing.KindServer), tracing.WithAttributes(
+				slog.String("http.request.method", r.Method),
+				slog.String("url.path", r.URL.Path),
+				slog.String("client.address", ClientIP(r)),
+			))
+			defer span.End()
+			if id := logging.RequestID(ctx); id != "" {
+				span.SetAttributes(slog.String("http.request.header.x_request_id", id))
+			}
+			sc := span.SpanContext()
+			ctx = logging.With(ctx, slog.String("trace_id", sc.TraceID.String()), slog.String("span_id", sc.SpanID.String()))
+			ctx, rl := withRequestLog(ctx)
+
+			rec := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(slog.String("http.route", route), slog.Int("http.response.status_code", status))
+			if rl.userID != 0 {
+				span.SetAttributes(slog.Int("enduser.id", rl.userID))
+			}
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(tracing.StatusError, http.StatusText(status))
+			}
+		})
+	}
+}
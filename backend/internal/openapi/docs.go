@@ -0,0 +1,258 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//go:embed docs.html
+var docsTemplate string
+
+// docs is the template of the reference page.
+var docs = template.Must(template.New("docs").Parse(docsTemplate))
+
+// Docs serves a reference page rendering the document: its operations by
+// tag, with their parameters, bodies and responses, and its schemas. The
+// page is plain HTML with inline styles, so it works offline and needs no
+// scripts or assets from elsewhere.
+func (d *Document) Docs() (http.Handler, error) {
+	var page bytes.Buffer
+	if err := docs.Execute(&page, d.docsPage()); err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The API's own policy forbids styles as well, which this page has.
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(page.Bytes())
+	}), nil
+}
+
+// docsPage is the data of the reference page.
+type docsPage struct {
+	Info    Info
+	Groups  []docsGroup
+	Schemas []docsSchema
+}
+
+type docsGroup struct {
+	Tag        Tag
+	Operations []docsOperation
+}
+
+type docsOperation struct {
+	ID          string
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Deprecated  bool
+	Parameters  []docsField
+	Body        []docsContent
+	Responses   []docsResponse
+}
+
+type docsField struct {
+	Name        string
+	In          string
+	Type        string
+	Required    bool
+	Description string
+}
+
+type docsContent struct {
+	MediaType string
+	Type      string
+}
+
+type docsResponse struct {
+	Status      string
+	Description string
+	Content     []docsContent
+}
+
+type docsSchema struct {
+	Name        string
+	Type        string
+	Description string
+	Fields      []docsField
+}
+
+// docsPage arranges the document for the page: operations grouped by their
+// first tag in the order tags are declared, and schemas by name.
+func (d *Document) docsPage() docsPage {
+	page := docsPage{Info: d.Info}
+	groups := map[string]int{}
+	for _, tag := range d.Tags {
+		groups[tag.Name] = len(page.Groups)
+		page.Groups = append(page.Groups, docsGroup{Tag: tag})
+	}
+
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		for _, method := range methods {
+			op, ok := d.Paths[path].Operations[method]
+			if !ok {
+				continue
+			}
+			tag := "other"
+			if len(op.Tags) > 0 {
+				tag = op.Tags[0]
+			}
+			i, ok := groups[tag]
+			if !ok {
+				i = len(page.Groups)
+				groups[tag] = i
+				page.Groups = append(page.Groups, docsGroup{Tag: Tag{Name: tag}})
+			}
+			page.Groups[i].Operations = append(page.Groups[i].Operations, docsOp(method, path, op))
+		}
+	}
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s := d.Components.Schemas[name]
+		page.Schemas = append(page.Schemas, docsSchema{
+			Name:        name,
+			Type:        typeLabel(s),
+			Description: s.Description,
+			Fields:      schemaFields(s),
+		})
+	}
+	return page
+}
+
+// docsOp describes one operation for the page.
+func docsOp(method, path string, op *Operation) docsOperation {
+	o := docsOperation{
+		ID:          op.OperationID,
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Deprecated:  op.Deprecated,
+	}
+	for _, p := range op.Parameters {
+		o.Parameters = append(o.Parameters, docsField{
+			Name:        p.Name,
+			In:          p.In,
+			Type:        typeLabel(p.Schema),
+			Required:    p.Required,
+			Description: p.Description,
+		})
+	}
+	if op.RequestBody != nil {
+		o.Body = contentList(op.RequestBody.Content)
+	}
+	statuses := make([]string, 0, len(op.Responses))
+	for status := range op.Responses {
+		statuses = append(statuses, status)
+	}
+	// Codes sort before "default", as digits sort before letters.
+	slices.Sort(statuses)
+	for _, status := range statuses {
+		resp := op.Responses[status]
+		o.Responses = append(o.Responses, docsResponse{
+			Status:      status,
+			Description: resp.Description,
+			Content:     contentList(resp.Content),
+		})
+	}
+	return o
+}
+
+// contentList lists the media types of content with their schemas.
+func contentList(content map[string]*MediaType) []docsContent {
+	var list []docsContent
+	for _, mediaType := range mediaTypes(content) {
+		list = append(list, docsContent{MediaType: mediaType, Type: typeLabel(content[mediaType].Schema)})
+	}
+	return list
+}
+
+// schemaFields lists the properties of an object schema, including those of
+// the schemas it combines with allOf.
+func schemaFields(s *Schema) []docsField {
+	if s == nil {
+		return nil
+	}
+	var fields []docsField
+	for _, sub := range s.AllOf {
+		if sub.Ref == "" {
+			fields = append(fields, schemaFields(sub)...)
+		}
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := s.Properties[name]
+		fields = append(fields, docsField{
+			Name:        name,
+			Type:        typeLabel(p),
+			Required:    slices.Contains(s.Required, name),
+			Description: p.Description,
+		})
+	}
+	return fields
+}
+
+// typeLabel describes a schema in a few words, e.g. "Recipe[]",
+// "string | null" or "Recipe & PageFields".
+func typeLabel(s *Schema) string {
+	switch {
+	case s == nil:
+		return "any"
+	case s.Ref != "":
+		return refName(s.Ref, "schemas")
+	case len(s.AllOf) > 0:
+		return combinedLabel(s.AllOf, " & ")
+	case len(s.AnyOf) > 0:
+		return combinedLabel(s.AnyOf, " | ")
+	case len(s.OneOf) > 0:
+		return combinedLabel(s.OneOf, " | ")
+	case len(s.Type) == 0:
+		return "any"
+	}
+	types := make([]string, len(s.Type))
+	for i, t := range s.Type {
+		if t == "array" && s.Items != nil {
+			t = typeLabel(s.Items) + "[]"
+		}
+		types[i] = t
+	}
+	return strings.Join(types, " | ")
+}
+
+// combinedLabel joins the labels of schemas, leaving out inline objects,
+// whose fields are listed anyway.
+func combinedLabel(schemas []*Schema, sep string) string {
+	var labels []string
+	for _, s := range schemas {
+		if s.Ref == "" && len(s.Type) == 0 {
+			continue
+		}
+		labels = append(labels, typeLabel(s))
+	}
+	if len(labels) == 0 {
+		return "object"
+	}
+	return strings.Join(labels, sep)
+}
@@ -0,0 +1,298 @@
+// Package openapi loads the OpenAPI 3.1 document of the API and checks the
+// router and traffic against it.
+//
+// CheckRoutes compares the document with the routes of a chi router, so a
+// route cannot be added without documenting it, nor an entry outlive its
+// route. Validator is a middleware rejecting requests whose parameters or
+// body do not match the document, and responses that do not either. Docs
+// serves a page rendering the document.
+//
+// Schemas are checked with the subset of JSON Schema 2020-12 the document
+// uses: type, enum, const, properties, required, additionalProperties,
+// items, minItems/maxItems, minLength/maxLength, minimum/maximum, pattern,
+// format date-time, allOf, anyOf, oneOf and local $ref.
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// methods are the operations a path item may have, in document order.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is a parsed OpenAPI document, with every $ref resolved.
+type Document struct {
+	raw        []byte
+	Info       Info                 `json:"info"`
+	Tags       []Tag                `json:"tags"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info describes the API as a whole.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+}
+
+// Tag groups operations in the docs.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PathItem holds the operations of one path, by lower case method.
+type PathItem struct {
+	Parameters []*Parameter
+	Operations map[string]*Operation
+}
+
+// Operation is one method of a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter.
+type Parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody lists the accepted media types of a request body.
+type RequestBody struct {
+	Ref      string                `json:"$ref"`
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response lists the media types of a response; none means no body.
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+// MediaType is the schema of a body of one media type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components are the reusable parts $ref points at.
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	Responses     map[string]*Response    `json:"responses"`
+}
+
+// UnmarshalJSON reads the parameters and operations of a path item,
+// ignoring its summary and description.
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	p.Operations = map[string]*Operation{}
+	if raw, ok := fields["parameters"]; ok {
+		if err := json.Unmarshal(raw, &p.Parameters); err != nil {
+			return err
+		}
+	}
+	for _, method := range methods {
+		raw, ok := fields[method]
+		if !ok {
+			continue
+		}
+		var op Operation
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+		p.Operations[method] = &op
+	}
+	return nil
+}
+
+// Load parses an OpenAPI 3.1 document and resolves its references.
+// Returns an error for malformed JSON, another OpenAPI version or a $ref
+// to nothing.
+func Load(data []byte) (*Document, error) {
+	var header struct {
+		OpenAPI string `json:"openapi"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if !strings.HasPrefix(header.OpenAPI, "3.1.") {
+		return nil, fmt.Errorf("openapi: version %q is not 3.1", header.OpenAPI)
+	}
+	doc := &Document{raw: data}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if err := doc.resolve(); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	return doc, nil
+}
+
+// resolve replaces parameter, request body and response references by
+// their targets, merges path-level parameters into each operation and links
+// schema references to the schemas they name.
+func (d *Document) resolve() error {
+	var errs []error
+	for _, s := range d.Components.Schemas {
+		errs = append(errs, s.resolve(d.Components.Schemas))
+	}
+	for path, item := range d.Paths {
+		for method, op := range item.Operations {
+			where := strings.ToUpper(method) + " " + path
+			params := make([]*Parameter, 0, len(item.Parameters)+len(op.Parameters))
+			// Operation parameters override path parameters of the same
+			// name and location.
+			for _, p := range append(append([]*Parameter{}, item.Parameters...), op.Parameters...) {
+				p, err := d.parameter(p)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", where, err))
+					continue
+				}
+				params = slices.DeleteFunc(params, func(q *Parameter) bool { return q.Name == p.Name && q.In == p.In })
+				params = append(params, p)
+			}
+			op.Parameters = params
+
+			if op.RequestBody != nil && op.RequestBody.Ref != "" {
+				body, ok := d.Components.RequestBodies[refName(op.RequestBody.Ref, "requestBodies")]
+				if !ok {
+					errs = append(errs, fmt.Errorf("%s: unresolved $ref %q", where, op.RequestBody.Ref))
+					continue
+				}
+				op.RequestBody = body
+			}
+			if op.RequestBody != nil {
+				for _, mt := range op.RequestBody.Content {
+					errs = append(errs, mt.Schema.resolve(d.Components.Schemas))
+				}
+			}
+			if len(op.Responses) == 0 {
+				errs = append(errs, fmt.Errorf("%s: no responses", where))
+			}
+			for status, resp := range op.Responses {
+				if resp.Ref != "" {
+					target, ok := d.Components.Responses[refName(resp.Ref, "responses")]
+					if !ok {
+						errs = append(errs, fmt.Errorf("%s: unresolved $ref %q", where, resp.Ref))
+						continue
+					}
+					op.Responses[status] = target
+					resp = target
+				}
+				for _, mt := range resp.Content {
+					errs = append(errs, mt.Schema.resolve(d.Components.Schemas))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parameter resolves a parameter reference and its schema.
+func (d *Document) parameter(p *Parameter) (*Parameter, error) {
+	if p.Ref != "" {
+		target, ok := d.Components.Parameters[refName(p.Ref, "parameters")]
+		if !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", p.Ref)
+		}
+		p = target
+	}
+	if p.Name == "" || (p.In != "path" && p.In != "query" && p.In != "header" && p.In != "cookie") {
+		return nil, fmt.Errorf("parameter %q in %q is malformed", p.Name, p.In)
+	}
+	return p, p.Schema.resolve(d.Components.Schemas)
+}
+
+// refName returns the component name of a local reference such as
+// "#/components/schemas/Recipe", or "" if ref does not point into kind.
+func refName(ref, kind string) string {
+	name, ok := strings.CutPrefix(ref, "#/components/"+kind+"/")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// JSON returns the document as it was loaded.
+func (d *Document) JSON() []byte {
+	return d.raw
+}
+
+// Handler serves the document as JSON.
+func (d *Document) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(d.raw)
+	})
+}
+
+// Operation returns the operation of method on path, a path template such
+// as "/recipes/{id}", or nil if the document has none.
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	return item.Operations[strings.ToLower(method)]
+}
+
+// CheckRoutes compares the routes of r with the document. Returns an error
+// listing every route without an operation and every operation without a
+// route; chi's {param} patterns are the document's path templates.
+func (d *Document) CheckRoutes(r chi.Routes) error {
+	routes := map[string]bool{}
+	var problems []string
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		key := method + " " + route
+		routes[key] = true
+		if d.Operation(method, route) == nil {
+			problems = append(problems, key+" is not in the OpenAPI document")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for path, item := range d.Paths {
+		for method := range item.Operations {
+			key := strings.ToUpper(method) + " " + path
+			if !routes[key] {
+				problems = append(problems, key+" is in the OpenAPI document but has no route")
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("routes and OpenAPI document disagree:\n  - %s", strings.Join(problems, "\n  - "))
+}
@@ -0,0 +1,305 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is a JSON Schema. A boolean schema is read as an empty schema,
+// accepting anything, or one rejecting everything.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 Types              `json:"type"`
+	Enum                 []any              `json:"enum"`
+	Const                json.RawMessage    `json:"const"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+	Pattern              string             `json:"pattern"`
+	Format               string             `json:"format"`
+	AllOf                []*Schema          `json:"allOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Description          string             `json:"description"`
+
+	never   bool
+	target  *Schema
+	pattern *regexp.Regexp
+	konst   any
+}
+
+// Types is the type keyword, one type name or several.
+type Types []string
+
+// UnmarshalJSON reads "string" as well as ["string", "null"].
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// UnmarshalJSON reads a schema object, or true or false.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// resolve links the $ref of s and its subschemas to the schemas they name
+// and compiles patterns. A nil schema accepts anything.
+func (s *Schema) resolve(schemas map[string]*Schema) error {
+	if s == nil || s.target != nil {
+		return nil
+	}
+	if s.Ref != "" {
+		target, ok := schemas[refName(s.Ref, "schemas")]
+		if !ok {
+			return fmt.Errorf("unresolved $ref %q", s.Ref)
+		}
+		s.target = target
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	if s.Const != nil {
+		if err := json.Unmarshal(s.Const, &s.konst); err != nil {
+			return fmt.Errorf("const: %w", err)
+		}
+	}
+	subs := slices.Concat(s.AllOf, s.AnyOf, s.OneOf, []*Schema{s.Items, s.AdditionalProperties})
+	for _, p := range s.Properties {
+		subs = append(subs, p)
+	}
+	for _, sub := range subs {
+		if err := sub.resolve(schemas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Violation is a place where a value does not match its schema.
+type Violation struct {
+	// Path locates the value, e.g. "body.ingredients[0].name".
+	Path    string
+	Message string
+}
+
+// Validate checks v, a value as decoded by encoding/json into an any, and
+// returns every violation, located under path.
+func (s *Schema) Validate(path string, v any) []Violation {
+	var vs []Violation
+	s.validate(path, v, &vs)
+	return vs
+}
+
+func (s *Schema) validate(path string, v any, vs *[]Violation) {
+	if s == nil {
+		return
+	}
+	if s.target != nil {
+		s.target.validate(path, v, vs)
+		return
+	}
+	fail := func(format string, args ...any) {
+		*vs = append(*vs, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.never {
+		fail("is not allowed")
+		return
+	}
+
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(v, t) }) {
+		if len(s.Type) == 1 {
+			fail("must be of type %s", s.Type[0])
+		} else {
+			fail("must be one of the types %v", []string(s.Type))
+		}
+		return
+	}
+	if s.Enum != nil && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		fail("must be one of %s", formatEnum(s.Enum))
+	}
+	if s.Const != nil && !equal(s.konst, v) {
+		fail("must be %s", s.Const)
+	}
+
+	switch v := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("must match %s", s.Pattern)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				fail("must be an RFC 3339 date-time")
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("must be at least %s", formatNumber(*s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("must be at most %s", formatNumber(*s.Maximum))
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, vs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*vs = append(*vs, Violation{Path: join(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := s.Properties[name]; ok {
+				prop.validate(join(path, name), v[name], vs)
+			} else if s.AdditionalProperties != nil {
+				if s.AdditionalProperties.never {
+					*vs = append(*vs, Violation{Path: join(path, name), Message: "is not a known field"})
+				} else {
+					s.AdditionalProperties.validate(join(path, name), v[name], vs)
+				}
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(path, v, vs)
+	}
+	if len(s.AnyOf) > 0 {
+		if matching := s.countMatching(s.AnyOf, path, v); matching == 0 {
+			fail("must match at least one of the allowed schemas")
+		}
+	}
+	if len(s.OneOf) > 0 {
+		if matching := s.countMatching(s.OneOf, path, v); matching != 1 {
+			fail("must match exactly one of the allowed schemas, matches %d", matching)
+		}
+	}
+}
+
+// countMatching returns how many of schemas v matches.
+func (s *Schema) countMatching(schemas []*Schema, path string, v any) int {
+	n := 0
+	for _, sub := range schemas {
+		if len(sub.Validate(path, v)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// hasType reports whether v is of the JSON Schema type t; an integer is a
+// number without a fractional part.
+func hasType(v any, t string) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return false
+}
+
+// equal compares two decoded JSON values.
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// join appends a field name to a path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// formatEnum lists enum values as JSON, e.g. "easy", "medium", "hard".
+func formatEnum(values []any) string {
+	var b bytes.Buffer
+	for i, v := range values {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		data, _ := json.Marshal(v)
+		b.Write(data)
+	}
+	return b.String()
+}
+
+// formatNumber formats a bound without a trailing ".0".
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
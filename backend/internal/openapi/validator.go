@@ -0,0 +1,342 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/apierror"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// MaxJSONBody is the largest JSON request body the validator reads. Larger
+// bodies are rejected with 413; no operation takes anything near as large.
+const MaxJSONBody = 1 << 20
+
+// Validator is a middleware checking traffic against the document.
+//
+// Requests to an operation of the document have their path, query and
+// header parameters and their JSON body validated, and are rejected with
+// 400 listing every problem, 413 for a body over MaxJSONBody or 415 for a
+// media type the operation does not accept. Multipart bodies are left to
+// their handler. Requests matching no operation, such as CORS preflights or
+// unknown routes, pass through untouched for the router to answer.
+//
+// With responses set, responses are buffered and checked as well: an
+// undocumented status, media type or a JSON body not matching its schema is
+// logged and replaced by a 500, so that a handler drifting from the document
+// is noticed in development and tests rather than by clients.
+func (d *Document) Validator(responses bool) func(http.Handler) http.Handler {
+	// The operations are matched with a router of their own, so that paths
+	// are matched exactly as chi matches the routes the document describes.
+	ops := chi.NewRouter()
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	for path, item := range d.Paths {
+		for method := range item.Operations {
+			ops.Method(strings.ToUpper(method), path, noop)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.NewRouteContext()
+			if !ops.Match(rctx, r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			op := d.Operation(r.Method, rctx.RoutePattern())
+
+			if status, message, fields := checkRequest(r, rctx, op); status != 0 {
+				apierror.WriteFields(w, status, message, fields)
+				return
+			}
+			if !responses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			rec := &recorder{header: w.Header(), status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if violations := checkResponse(op, rec); len(violations) > 0 {
+				logging.FromContext(r.Context()).Error("response does not match the OpenAPI document",
+					slog.String("operation", op.OperationID),
+					slog.Int("status", rec.status),
+					slog.Any("violations", violations),
+				)
+				w.Header().Del("Content-Length")
+				w.Header().Del("Content-Disposition")
+				apierror.Write(w, http.StatusInternalServerError, "server error")
+				return
+			}
+			w.WriteHeader(rec.status)
+			_, _ = w.Write(rec.body.Bytes())
+		})
+	}
+}
+
+// checkRequest validates the parameters and body of r against op. Returns
+// the status, message and fields of the error response to send, or a zero
+// status if r is valid. A JSON body is read and replaced by a copy for the
+// handler.
+func checkRequest(r *http.Request, rctx *chi.Context, op *Operation) (int, string, []validate.FieldError) {
+	var errs validate.Errors
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var values []string
+		switch p.In {
+		case "path":
+			values = []string{rctx.URLParam(p.Name)}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = r.Header.Values(p.Name)
+		case "cookie":
+			if c, err := r.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		// An empty value of a non-string parameter, as in ?limit=, is
+		// treated as absent: handlers use their default for both.
+		if len(values) == 1 && values[0] == "" && p.In != "path" && !p.Schema.allows("string") {
+			values = nil
+		}
+		if len(values) == 0 {
+			if p.Required {
+				errs.Add(p.Name, "is required")
+			}
+			continue
+		}
+		v, ok := p.Schema.parse(values)
+		if !ok {
+			errs.Add(p.Name, "must be of type "+strings.Join(p.Schema.types(), " or "))
+			continue
+		}
+		addViolations(&errs, p.Schema.Validate(p.Name, v))
+	}
+
+	if op.RequestBody != nil {
+		status, message := checkBody(r, op.RequestBody, &errs)
+		if status != 0 {
+			return status, message, nil
+		}
+	}
+	if len(errs) > 0 {
+		return http.StatusBadRequest, "validation failed", errs
+	}
+	return 0, "", nil
+}
+
+// checkBody validates the body of r against body, adding problems to errs.
+// Returns a status and message for a body that cannot be validated at all.
+func checkBody(r *http.Request, body *RequestBody, errs *validate.Errors) (int, string) {
+	contentType := r.Header.Get("Content-Type")
+	if r.ContentLength == 0 && contentType == "" {
+		if body.Required {
+			errs.Add("body", "is required")
+		}
+		return 0, ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return http.StatusUnsupportedMediaType, "missing or malformed Content-Type"
+	}
+	mt := lookupMediaType(body.Content, mediaType)
+	if mt == nil {
+		return http.StatusUnsupportedMediaType, "Content-Type " + mediaType + " is not accepted, use " + strings.Join(mediaTypes(body.Content), " or ")
+	}
+	if !isJSON(mediaType) {
+		return 0, ""
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, MaxJSONBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return http.StatusRequestEntityTooLarge, "request body too large"
+		}
+		return http.StatusBadRequest, "could not read request body"
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	if len(bytes.TrimSpace(data)) == 0 {
+		if body.Required {
+			errs.Add("body", "is required")
+		}
+		return 0, ""
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return http.StatusBadRequest, "invalid request body"
+	}
+	addViolations(errs, mt.Schema.Validate("", v))
+	return 0, ""
+}
+
+// checkResponse returns the ways rec departs from the responses of op.
+func checkResponse(op *Operation, rec *recorder) []Violation {
+	resp := lookupResponse(op.Responses, rec.status)
+	if resp == nil {
+		return []Violation{{Path: "status", Message: strconv.Itoa(rec.status) + " is not documented"}}
+	}
+	// A response documented without content, such as a redirect, may
+	// still carry a short body, e.g. the link http.Redirect writes.
+	if len(resp.Content) == 0 {
+		return nil
+	}
+	if rec.body.Len() == 0 {
+		return []Violation{{Path: "body", Message: "is missing"}}
+	}
+	mediaType, _, _ := mime.ParseMediaType(rec.header.Get("Content-Type"))
+	mt := lookupMediaType(resp.Content, mediaType)
+	if mt == nil {
+		return []Violation{{Path: "Content-Type", Message: strconv.Quote(mediaType) + " is not documented"}}
+	}
+	if !isJSON(mediaType) {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(rec.body.Bytes(), &v); err != nil {
+		return []Violation{{Path: "body", Message: "is not valid JSON"}}
+	}
+	return mt.Schema.Validate("body", v)
+}
+
+// lookupResponse returns the response documented for status: the exact
+// code, then its range such as 4XX, then the default.
+func lookupResponse(responses map[string]*Response, status int) *Response {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", "default"} {
+		if resp, ok := responses[key]; ok {
+			return resp
+		}
+	}
+	return nil
+}
+
+// lookupMediaType returns the entry of content for mediaType, trying
+// "type/*" and "*/*" ranges after the exact type.
+func lookupMediaType(content map[string]*MediaType, mediaType string) *MediaType {
+	major, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, major + "/*", "*/*"} {
+		if mt, ok := content[key]; ok {
+			return mt
+		}
+	}
+	return nil
+}
+
+// mediaTypes returns the media types of content, sorted.
+func mediaTypes(content map[string]*MediaType) []string {
+	types := make([]string, 0, len(content))
+	for t := range content {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// isJSON reports whether mediaType is JSON, such as application/json or
+// application/problem+json.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// addViolations records violations as field errors. The body itself is
+// called "body"; its fields go by their path.
+func addViolations(errs *validate.Errors, violations []Violation) {
+	for _, v := range violations {
+		field := v.Path
+		if field == "" {
+			field = "body"
+		}
+		errs.Add(field, v.Message)
+	}
+}
+
+// types returns the type names s allows, following its $ref.
+func (s *Schema) types() []string {
+	for s != nil && s.target != nil {
+		s = s.target
+	}
+	if s == nil || len(s.Type) == 0 {
+		return []string{"string"}
+	}
+	return s.Type
+}
+
+// allows reports whether s allows values of type t.
+func (s *Schema) allows(t string) bool {
+	return slices.Contains(s.types(), t)
+}
+
+// parse converts the raw values of a parameter to the JSON value s
+// describes: an array for an array schema, else the first value as a
+// number, boolean or string. Reports false if no allowed type fits.
+func (s *Schema) parse(values []string) (any, bool) {
+	if s.allows("array") {
+		items := s
+		for items.target != nil {
+			items = items.target
+		}
+		out := make([]any, len(values))
+		for i, raw := range values {
+			v, ok := items.Items.parse([]string{raw})
+			if !ok {
+				return nil, false
+			}
+			out[i] = v
+		}
+		return out, true
+	}
+	raw := values[0]
+	for _, t := range s.types() {
+		switch t {
+		case "integer", "number":
+			if f, err := strconv.ParseFloat(raw, 64); err == nil {
+				return f, true
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(raw); err == nil {
+				return b, true
+			}
+		case "string":
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// recorder buffers a response so that it can be validated before it is
+// sent. Headers go straight to the real response writer.
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+	rec.status = status
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
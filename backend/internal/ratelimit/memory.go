@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in process memory. Buckets are lost on restart
+// and not shared between instances, so each instance allows the full limit.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]bucket
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]bucket)}
+}
+
+// Take takes a token from key's bucket.
+func (m *MemoryStore) Take(ctx context.Context, key string, p Policy, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = bucket{tokens: float64(p.Limit), updatedAt: now}
+	}
+	b.tokens = p.refill(b.tokens, b.updatedAt, now)
+	if now.After(b.updatedAt) {
+		b.updatedAt = now
+	}
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	m.buckets[key] = b
+	return p.result(b.tokens, allowed), nil
+}
+
+// Prune forgets idle buckets.
+func (m *MemoryStore) Prune(ctx context.Context, cutoff time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, b := range m.buckets {
+		if b.updatedAt.Before(cutoff) {
+			delete(m.buckets, key)
+		}
+	}
+	return nil
+}
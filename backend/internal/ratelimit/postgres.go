@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table, so every
+// instance of the API draws from the same buckets. Each request costs one
+// upsert.
+type PostgresStore struct {
+	q *db.Queries
+}
+
+// NewPostgresStore creates a store backed by the given database.
+func NewPostgresStore(conn db.DBTX) *PostgresStore {
+	return &PostgresStore{q: db.New(conn)}
+}
+
+// Take takes a token from key's bucket in a single statement.
+func (s *PostgresStore) Take(ctx context.Context, key string, p Policy, now time.Time) (Result, error) {
+	row, err := s.q.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:      key,
+		Capacity: float64(p.Limit),
+		Now:      now,
+		Rate:     p.rate(),
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return p.result(row.Tokens, row.Allowed), nil
+}
+
+// Prune forgets idle buckets.
+func (s *PostgresStore) Prune(ctx context.Context, cutoff time.Time) error {
+	return s.q.DeleteIdleRateLimitBuckets(ctx, cutoff)
+}
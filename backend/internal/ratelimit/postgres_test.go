@@ -0,0 +1,36 @@
+//go:build integration
+
+package ratelimit
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPostgresStore runs the store checks against the database at
+// TEST_DATABASE_URL, which must be migrated (make migrateup).
+func TestPostgresStore(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	prefix := fmt.Sprintf("test-%d:", time.Now().UnixNano())
+	t.Cleanup(func() {
+		if _, err := conn.Exec("DELETE FROM rate_limit_buckets WHERE key LIKE $1", prefix+"%"); err != nil {
+			t.Errorf("deleting test buckets: %v", err)
+		}
+	})
+	// The database keeps microseconds; whole seconds survive the round trip.
+	// The prune check also forgets other buckets idle at the time, which
+	// a test database can spare.
+	testStore(t, NewPostgresStore(conn), prefix, time.Now().Truncate(time.Second))
+}
@@ -0,0 +1,139 @@
+// Package ratelimit throttles requests with token buckets.
+//
+// Every key (a user or a client IP under a named policy) has a bucket
+// holding up to Policy.Limit tokens. A request takes one token; the bucket
+// refills at Limit tokens per Period, so clients can burst up to Limit
+// requests and then sustain Limit per Period. A request finding less than
+// one token is refused.
+//
+// Buckets live in a pluggable Store: MemoryStore for a single instance or
+// PostgresStore to share them between instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Policy configures the bucket of one rate limited route group.
+type Policy struct {
+	// Limit is the bucket size: the burst allowed, and the number of
+	// requests refilled per Period. Zero disables the policy.
+	Limit int
+	// Period is the time in which Limit tokens are refilled.
+	Period time.Duration
+}
+
+// Enabled reports whether the policy limits anything.
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+// String formats the policy for the RateLimit-Policy header, e.g. "10;w=60".
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int(math.Ceil(p.Period.Seconds())))
+}
+
+// rate is the refill rate in tokens per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// refill returns the tokens in a bucket that held tokens at last, at now.
+func (p Policy) refill(tokens float64, last, now time.Time) float64 {
+	if elapsed := now.Sub(last); elapsed > 0 {
+		tokens += elapsed.Seconds() * p.rate()
+	}
+	return math.Min(tokens, float64(p.Limit))
+}
+
+// result describes a bucket left holding tokens after a request.
+func (p Policy) result(tokens float64, allowed bool) Result {
+	r := Result{
+		Allowed:   allowed,
+		Limit:     p.Limit,
+		Remaining: int(math.Max(math.Floor(tokens), 0)),
+		Reset:     p.wait(float64(p.Limit) - tokens),
+	}
+	if tokens < 1 {
+		r.RetryAfter = p.wait(1 - tokens)
+	}
+	return r
+}
+
+// wait returns how long refilling the given number of tokens takes.
+func (p Policy) wait(tokens float64) time.Duration {
+	if tokens <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / p.rate() * float64(time.Second))
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is the number of whole tokens left.
+	Remaining int
+	// RetryAfter is how long until the next token is available; 0 if one
+	// is available now.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// Store persists buckets. Implementations must be safe for concurrent use
+// and take tokens atomically.
+type Store interface {
+	// Take refills key's bucket under policy p up to now and takes a token
+	// if one is left. Unknown keys start with a full bucket.
+	Take(ctx context.Context, key string, p Policy, now time.Time) (Result, error)
+	// Prune forgets buckets last used before cutoff.
+	Prune(ctx context.Context, cutoff time.Time) error
+}
+
+// Limiter applies named policies to requests.
+type Limiter struct {
+	Store    Store
+	Policies map[string]Policy
+}
+
+// Policy returns the named policy; a missing policy is disabled.
+func (l *Limiter) Policy(name string) Policy {
+	return l.Policies[name]
+}
+
+// Allow takes a token for key under the named policy. Requests under a
+// disabled policy are always allowed.
+func (l *Limiter) Allow(ctx context.Context, policy, key string, now time.Time) (Result, error) {
+	p := l.Policy(policy)
+	if !p.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+	return l.Store.Take(ctx, policy+":"+key, p, now)
+}
+
+// Prune forgets buckets idle for longer than the longest policy period;
+// they would be full again anyway.
+func (l *Limiter) Prune(ctx context.Context, now time.Time) error {
+	var period time.Duration
+	for _, p := range l.Policies {
+		if p.Period > period {
+			period = p.Period
+		}
+	}
+	return l.Store.Prune(ctx, now.Add(-period))
+}
+
+// UserKey is the bucket key for requests of a signed-in user, made with an
+// access token or any of the user's API keys.
+func UserKey(userID int) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// ClientKey is the bucket key for anonymous requests from an IP address.
+func ClientKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testPolicy refills one token a second into a bucket of three.
+var testPolicy = Policy{Limit: 3, Period: 3 * time.Second}
+
+// testStore checks the token bucket behaviour every Store must have. Keys
+// start with prefix, so runs against a shared database do not collide.
+func testStore(t *testing.T, store Store, prefix string, start time.Time) {
+	ctx := context.Background()
+	take := func(t *testing.T, key string, at time.Time) Result {
+		t.Helper()
+		res, err := store.Take(ctx, prefix+key, testPolicy, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	check := func(t *testing.T, got, want Result) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	t.Run("burst", func(t *testing.T) {
+		check(t, take(t, "burst", start), Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second})
+		check(t, take(t, "burst", start), Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second})
+		check(t, take(t, "burst", start), Result{Allowed: true, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second})
+		check(t, take(t, "burst", start), Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second})
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		for range 3 {
+			take(t, "refill", start)
+		}
+		check(t, take(t, "refill", start.Add(500*time.Millisecond)),
+			Result{Allowed: false, Limit: 3, Remaining: 0, RetryAfter: 500 * time.Millisecond, Reset: 2500 * time.Millisecond})
+		check(t, take(t, "refill", start.Add(time.Second)),
+			Result{Allowed: true, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second})
+		// A long idle time refills the bucket to its size, no further.
+		check(t, take(t, "refill", start.Add(time.Hour)),
+			Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second})
+	})
+
+	t.Run("clock going back", func(t *testing.T) {
+		take(t, "back", start)
+		// An earlier time, from another instance's clock, refills nothing
+		// and does not move the bucket's clock back.
+		check(t, take(t, "back", start.Add(-time.Second)), Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second})
+		check(t, take(t, "back", start.Add(time.Second)), Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 2 * time.Second})
+	})
+
+	t.Run("keys are independent", func(t *testing.T) {
+		for range 4 {
+			take(t, "a", start)
+		}
+		check(t, take(t, "b", start), Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second})
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		later := start.Add(10 * time.Second)
+		take(t, "idle", start)
+		take(t, "busy", later)
+		take(t, "busy", later)
+		if err := store.Prune(ctx, later); err != nil {
+			t.Fatal(err)
+		}
+		// The pruned bucket starts over full; the other is kept.
+		check(t, take(t, "idle", later), Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second})
+		check(t, take(t, "busy", later), Result{Allowed: true, Limit: 3, Remaining: 0, RetryAfter: time.Second, Reset: 3 * time.Second})
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore(), "", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := &Limiter{Store: NewMemoryStore(), Policies: map[string]Policy{
+		"match":  {Limit: 1, Period: time.Minute},
+		"detect": {Limit: 1, Period: time.Minute},
+		"off":    {Limit: 0, Period: time.Minute},
+	}}
+
+	for _, policy := range []string{"match", "detect"} {
+		res, err := l.Allow(ctx, policy, UserKey(7), now)
+		if err != nil || !res.Allowed {
+			t.Errorf("first %s request: %+v, %v; want allowed", policy, res, err)
+		}
+	}
+	if res, _ := l.Allow(ctx, "match", UserKey(7), now); res.Allowed {
+		t.Error("second match request allowed, want the policy's bucket empty")
+	}
+	if res, _ := l.Allow(ctx, "match", ClientKey("192.0.2.1"), now); !res.Allowed {
+		t.Error("another key's request refused")
+	}
+	for range 3 {
+		if res, _ := l.Allow(ctx, "off", UserKey(7), now); !res.Allowed {
+			t.Error("request under a disabled policy refused")
+		}
+	}
+	if res, _ := l.Allow(ctx, "missing", UserKey(7), now); !res.Allowed {
+		t.Error("request under a missing policy refused")
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	for _, tt := range []struct {
+		p    Policy
+		want string
+	}{
+		{Policy{Limit: 10, Period: time.Minute}, "10;w=60"},
+		{Policy{Limit: 5, Period: 1500 * time.Millisecond}, "5;w=2"},
+	} {
+		if got := tt.p.String(); got != tt.want {
+			t.Errorf("%+v: got %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/mail"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// Account token purposes, stored in account_tokens.purpose.
+const (
+	purposeVerifyEmail   = "verify_email"
+	purposeResetPassword = "reset_password"
+)
+
+// ErrInvalidAccountToken is returned when a verification or reset token is
+// unknown, expired or already used.
+var ErrInvalidAccountToken = fmt.Errorf("invalid or expired token")
+
+// ErrWrongPassword is returned when the current password given to
+// ChangePassword does not match.
+var ErrWrongPassword = fmt.Errorf("current password is incorrect")
+
+// MailSettings configures account email.
+type MailSettings struct {
+	// Mailer delivers messages. A nil Mailer disables account email.
+	Mailer mail.Mailer
+	// AppURL is the frontend base URL links in emails point to.
+	AppURL string
+	// VerifyTTL is how long an email verification link stays valid.
+	VerifyTTL time.Duration
+	// ResetTTL is how long a password reset link stays valid.
+	ResetTTL time.Duration
+}
+
+// SendVerificationEmail emails a user a link to confirm their address.
+// Earlier verification links stop working.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user to verify
+//
+// Returns error if the user is unknown or the email cannot be sent.
+func (s *Service) SendVerificationEmail(ctx context.Context, userID int) error {
+	user, err := s.q.GetUserCredentials(ctx, int32(userID))
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt.Valid || !user.Email.Valid {
+		return nil
+	}
+
+	if err := s.q.InvalidateAccountTokens(ctx, db.InvalidateAccountTokensParams{UserID: user.ID, Purpose: purposeVerifyEmail}); err != nil {
+		return err
+	}
+	token, err := s.issueAccountToken(ctx, user.ID, purposeVerifyEmail, s.Mail.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendMail(ctx, mail.Message{
+		To:      user.Email.String,
+		Subject: "Confirm your email address",
+		Body: "Welcome to Smart Recipe Generator!\n\n" +
+			"Confirm your email address by opening this link:\n\n" +
+			s.accountLink("/verify-email", token) + "\n\n" +
+			fmt.Sprintf("The link expires in %s.\n", s.Mail.VerifyTTL),
+	})
+}
+
+// VerifyEmail marks the address behind a verification token as confirmed.
+//
+// Parameters:
+//   - ctx: request context
+//   - token: token from the verification email
+//
+// Returns ErrInvalidAccountToken if the token is unknown, expired or used.
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.consumeAccountToken(ctx, token, purposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	return s.q.MarkEmailVerified(ctx, userID)
+}
+
+// passwordResetTimeout bounds the background work of one reset request.
+const passwordResetTimeout = time.Minute
+
+// RequestPasswordReset emails a password reset link to the account with the
+// given address. Unknown addresses are ignored so callers cannot probe which
+// emails are registered.
+//
+// The lookup, the token and the email happen in the background, after the
+// call has returned, so requests for known and unknown addresses take the
+// same time; failures are logged. Wait awaits outstanding requests.
+//
+// Parameters:
+//   - ctx: request context; its values, such as the logger, are kept but
+//     its cancellation is not
+//   - email: address the reset was requested for
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) {
+	ctx = context.WithoutCancel(ctx)
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		ctx, cancel := context.WithTimeout(ctx, passwordResetTimeout)
+		defer cancel()
+		if err := s.sendPasswordReset(ctx, email); err != nil {
+			logging.FromContext(ctx).Error("password reset email failed", slog.Any("error", err))
+		}
+	}()
+}
+
+// Wait blocks until background work started by the service, such as
+// password reset emails, has finished.
+func (s *Service) Wait() {
+	s.background.Wait()
+}
+
+// sendPasswordReset issues a reset token for the account with the given
+// address and emails the link. Returns error only if a known user's link
+// cannot be issued or sent.
+func (s *Service) sendPasswordReset(ctx context.Context, email string) error {
+	user, err := s.q.GetUserByEmail(ctx, sql.NullString{String: strings.TrimSpace(email), Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only the most recent link works.
+	if err := s.q.InvalidateAccountTokens(ctx, db.InvalidateAccountTokensParams{UserID: user.ID, Purpose: purposeResetPassword}); err != nil {
+		return err
+	}
+	token, err := s.issueAccountToken(ctx, user.ID, purposeResetPassword, s.Mail.ResetTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendMail(ctx, mail.Message{
+		To:      user.Email.String,
+		Subject: "Reset your password",
+		Body: "Someone asked to reset the password of your Smart Recipe Generator account.\n\n" +
+			"Choose a new password by opening this link:\n\n" +
+			s.accountLink("/reset-password", token) + "\n\n" +
+			fmt.Sprintf("The link expires in %s. If you did not ask for a reset, ignore this email.\n", s.Mail.ResetTTL),
+	})
+}
+
+// ResetPassword sets a new password using a reset token.
+//
+// Every session of the user is revoked, so a stolen session does not survive
+// the reset. Following a reset link also proves ownership of the address, so
+// the email is marked verified.
+//
+// Parameters:
+//   - ctx: request context
+//   - token: token from the reset email
+//   - newPassword: plain text password (will be hashed)
+//
+// Returns ErrInvalidAccountToken, or validate.Errors for a weak password.
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if err := validate.Password(newPassword); err != nil {
+		return validate.Errors{{Field: "password", Message: err.Error()}}
+	}
+	userID, err := s.consumeAccountToken(ctx, token, purposeResetPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.setPassword(ctx, userID, newPassword); err != nil {
+		return err
+	}
+	if err := s.q.MarkEmailVerified(ctx, userID); err != nil {
+		return err
+	}
+	_, err = s.RevokeOtherSessions(ctx, int(userID), 0)
+	return err
+}
+
+// ChangePassword replaces a signed-in user's password after checking the
+// current one. Every other session is revoked; the current one stays.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - sessionID: session making the request (0 revokes every session)
+//   - current: the user's current password
+//   - newPassword: plain text password (will be hashed)
+//
+// Returns ErrWrongPassword, or validate.Errors for a weak password.
+func (s *Service) ChangePassword(ctx context.Context, userID, sessionID int, current, newPassword string) error {
+	if err := validate.Password(newPassword); err != nil {
+		return validate.Errors{{Field: "newPassword", Message: err.Error()}}
+	}
+	user, err := s.q.GetUserCredentials(ctx, int32(userID))
+	if err != nil {
+		return err
+	}
+	if err := auth.VerifyPassword(user.PasswordHash.String, current); err != nil {
+		return ErrWrongPassword
+	}
+	if err := s.setPassword(ctx, user.ID, newPassword); err != nil {
+		return err
+	}
+	// Outstanding reset links would undo the change.
+	if err := s.q.InvalidateAccountTokens(ctx, db.InvalidateAccountTokensParams{UserID: user.ID, Purpose: purposeResetPassword}); err != nil {
+		return err
+	}
+	_, err = s.RevokeOtherSessions(ctx, userID, sessionID)
+	return err
+}
+
+// PruneAccountTokens deletes used and expired verification and reset tokens.
+func (s *Service) PruneAccountTokens(ctx context.Context) error {
+	return s.q.DeleteExpiredAccountTokens(ctx)
+}
+
+func (s *Service) setPassword(ctx context.Context, userID int32, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.q.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+		ID:           userID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+	})
+}
+
+// issueAccountToken stores the hash of a new single-use token and returns
+// the plain token for the email link.
+func (s *Service) issueAccountToken(ctx context.Context, userID int32, purpose string, ttl time.Duration) (string, error) {
+	token, err := auth.RandomSecret()
+	if err != nil {
+		return "", err
+	}
+	err = s.q.CreateAccountToken(ctx, db.CreateAccountTokenParams{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	return token, err
+}
+
+// consumeAccountToken marks a token used and returns its user.
+func (s *Service) consumeAccountToken(ctx context.Context, token, purpose string) (int32, error) {
+	if token == "" {
+		return 0, ErrInvalidAccountToken
+	}
+	userID, err := s.q.ConsumeAccountToken(ctx, db.ConsumeAccountTokenParams{TokenHash: auth.HashToken(token), Purpose: purpose})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidAccountToken
+	}
+	return userID, err
+}
+
+// accountLink builds a frontend link carrying token.
+func (s *Service) accountLink(path, token string) string {
+	return strings.TrimRight(s.Mail.AppURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func (s *Service) sendMail(ctx context.Context, msg mail.Message) error {
+	if s.Mail.Mailer == nil {
+		return nil
+	}
+	return s.Mail.Mailer.Send(ctx, msg)
+}
@@ -0,0 +1,177 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/mail"
+)
+
+// resetLink finds the reset token in an emailed link.
+var resetLink = regexp.MustCompile(`/reset-password\?token=(\S+)`)
+
+// withFileMailer makes s write account emails to a new directory, which it
+// returns.
+func withFileMailer(t *testing.T, s *Service) string {
+	t.Helper()
+	dir := t.TempDir()
+	s.Mail = MailSettings{
+		Mailer:    &mail.FileMailer{Dir: dir, From: "test@example.com"},
+		AppURL:    "http://app.test",
+		VerifyTTL: time.Hour,
+		ResetTTL:  time.Hour,
+	}
+	return dir
+}
+
+// resetTokens returns the reset tokens emailed to dir, oldest first.
+func resetTokens(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tokens []string
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := resetLink.FindSubmatch(b)
+		if m == nil {
+			t.Fatalf("%s has no reset link:\n%s", f, b)
+		}
+		token, err := url.QueryUnescape(string(m[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+func TestPasswordResetTokenIsSingleUse(t *testing.T) {
+	s := testService(t)
+	dir := withFileMailer(t, s)
+	user := createTestUser(t, s)
+	ctx := context.Background()
+
+	s.RequestPasswordReset(ctx, user.Email.String)
+	s.Wait()
+	tokens := resetTokens(t, dir)
+	if len(tokens) != 1 {
+		t.Fatalf("got %d reset emails, want 1", len(tokens))
+	}
+
+	if err := s.ResetPassword(ctx, tokens[0], "new-password-2"); err != nil {
+		t.Fatalf("first reset: %v", err)
+	}
+	if _, err := s.Authenticate(ctx, user.Email.String, "new-password-2"); err != nil {
+		t.Errorf("signing in with the new password: %v", err)
+	}
+	if err := s.ResetPassword(ctx, tokens[0], "new-password-3"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Errorf("reusing the token: got %v, want ErrInvalidAccountToken", err)
+	}
+	if _, err := s.Authenticate(ctx, user.Email.String, "new-password-3"); err == nil {
+		t.Error("the reused token changed the password")
+	}
+}
+
+func TestPasswordResetTokenExpires(t *testing.T) {
+	s := testService(t)
+	withFileMailer(t, s)
+	user := createTestUser(t, s)
+	ctx := context.Background()
+
+	token, err := s.issueAccountToken(ctx, user.ID, purposeResetPassword, -time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ResetPassword(ctx, token, "new-password-2"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Errorf("expired token: got %v, want ErrInvalidAccountToken", err)
+	}
+	if _, err := s.Authenticate(ctx, user.Email.String, testPassword); err != nil {
+		t.Errorf("the expired token changed the password: %v", err)
+	}
+}
+
+func TestPasswordResetSupersedesEarlierTokens(t *testing.T) {
+	s := testService(t)
+	dir := withFileMailer(t, s)
+	user := createTestUser(t, s)
+	ctx := context.Background()
+
+	s.RequestPasswordReset(ctx, user.Email.String)
+	s.Wait()
+	s.RequestPasswordReset(ctx, user.Email.String)
+	s.Wait()
+	tokens := resetTokens(t, dir)
+	if len(tokens) != 2 {
+		t.Fatalf("got %d reset emails, want 2", len(tokens))
+	}
+
+	if err := s.ResetPassword(ctx, tokens[0], "new-password-2"); !errors.Is(err, ErrInvalidAccountToken) {
+		t.Errorf("superseded token: got %v, want ErrInvalidAccountToken", err)
+	}
+	if err := s.ResetPassword(ctx, tokens[1], "new-password-3"); err != nil {
+		t.Errorf("latest token: %v", err)
+	}
+}
+
+func TestPasswordResetOfUnknownAddressSendsNothing(t *testing.T) {
+	s := testService(t)
+	dir := withFileMailer(t, s)
+
+	s.RequestPasswordReset(context.Background(), "nobody-"+time.Now().Format("150405.000000")+"@example.com")
+	s.Wait()
+	if tokens := resetTokens(t, dir); len(tokens) != 0 {
+		t.Errorf("got %d reset emails for an unknown address, want none", len(tokens))
+	}
+}
+
+// TestRequestPasswordResetReturnsBeforeSending checks that the caller does
+// not wait for the email, whose delivery time would tell known addresses
+// from unknown ones.
+func TestRequestPasswordResetReturnsBeforeSending(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	sent := make(chan struct{})
+	release := make(chan struct{})
+	s.Mail = MailSettings{Mailer: blockingMailer{sent: sent, release: release}, AppURL: "http://app.test", ResetTTL: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.RequestPasswordReset(ctx, user.Email.String)
+	// The request is over; the email must still go out.
+	cancel()
+	select {
+	case <-sent:
+	case <-time.After(10 * time.Second):
+		t.Fatal("reset email not sent")
+	}
+	close(release)
+	s.Wait()
+}
+
+// blockingMailer reports each message on sent, then holds the send until
+// release is closed.
+type blockingMailer struct {
+	sent    chan<- struct{}
+	release <-chan struct{}
+}
+
+func (m blockingMailer) Send(ctx context.Context, _ mail.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.sent <- struct{}{}
+	<-m.release
+	return nil
+}
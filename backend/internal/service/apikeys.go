@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// ErrAPIKeyNotFound is returned when a user has no API key with the given ID.
+var ErrAPIKeyNotFound = fmt.Errorf("api key not found")
+
+// ErrTooManyAPIKeys is returned when a user already has MaxAPIKeysPerUser keys.
+var ErrTooManyAPIKeys = fmt.Errorf("too many api keys")
+
+// API key limits.
+const (
+	MaxAPIKeyNameLength = 100
+	MaxAPIKeysPerUser   = 20
+)
+
+// APIKeyInput describes a new API key.
+type APIKeyInput struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is when the key stops working; nil for a key that does
+	// not expire.
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey is a newly created API key. Key is the only copy of the
+// secret: it is not stored and cannot be shown again.
+type CreatedAPIKey struct {
+	Key string
+	db.ApiKey
+}
+
+// CreateAPIKey issues a named, scoped API key for a user. Only a hash of the
+// key is stored.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user the key acts for
+//   - in: key name, scopes and optional expiry
+//
+// Returns the new key, validate.Errors for invalid input, or
+// ErrTooManyAPIKeys.
+func (s *Service) CreateAPIKey(ctx context.Context, userID int, in APIKeyInput) (CreatedAPIKey, error) {
+	name := strings.TrimSpace(in.Name)
+	scopes := make([]string, 0, len(in.Scopes))
+
+	var errs validate.Errors
+	switch {
+	case name == "":
+		errs.Add("name", "is required")
+	case utf8.RuneCountInString(name) > MaxAPIKeyNameLength:
+		errs.Add("name", fmt.Sprintf("must be at most %d characters", MaxAPIKeyNameLength))
+	}
+	// Scopes are stored once each, in documentation order.
+	for _, scope := range auth.Scopes {
+		if slices.Contains(in.Scopes, string(scope)) {
+			scopes = append(scopes, string(scope))
+		}
+	}
+	for _, requested := range in.Scopes {
+		if _, ok := auth.ParseScope(requested); !ok {
+			errs.Add("scopes", fmt.Sprintf("unknown scope %q", requested))
+		}
+	}
+	if len(in.Scopes) == 0 {
+		errs.Add("scopes", "at least one scope is required")
+	}
+	if in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()) {
+		errs.Add("expires_at", "must be in the future")
+	}
+	if err := errs.Err(); err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	count, err := s.q.CountUserApiKeys(ctx, int32(userID))
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	if count >= MaxAPIKeysPerUser {
+		return CreatedAPIKey{}, ErrTooManyAPIKeys
+	}
+
+	key, prefix, err := auth.NewAPIKey()
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	params := db.CreateApiKeyParams{
+		UserID:  int32(userID),
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: auth.HashToken(key),
+		Scopes:  scopes,
+	}
+	if in.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *in.ExpiresAt, Valid: true}
+	}
+	row, err := s.q.CreateApiKey(ctx, params)
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	s.audit(ctx, AuditEvent{Event: AuditAPIKeyCreated, UserID: userID, Subject: prefix, Details: map[string]any{"name": name, "scopes": scopes}})
+	return CreatedAPIKey{Key: key, ApiKey: row}, nil
+}
+
+// ListAPIKeys returns a user's API keys, newest first, including expired
+// ones so they can be cleaned up.
+func (s *Service) ListAPIKeys(ctx context.Context, userID int) ([]db.ApiKey, error) {
+	rows, err := s.q.ListUserApiKeys(ctx, int32(userID))
+	if rows == nil && err == nil {
+		rows = []db.ApiKey{}
+	}
+	return rows, err
+}
+
+// RevokeAPIKey deletes one of a user's API keys; it stops working at once.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user owning the key
+//   - keyID: ID of the key to revoke
+//
+// Returns ErrAPIKeyNotFound if the user has no such key.
+func (s *Service) RevokeAPIKey(ctx context.Context, userID, keyID int) error {
+	n, err := s.q.DeleteApiKey(ctx, db.DeleteApiKeyParams{ID: int32(keyID), UserID: int32(userID)})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	s.audit(ctx, AuditEvent{Event: AuditAPIKeyRevoked, UserID: userID, Details: map[string]any{"api_key_id": keyID}})
+	return nil
+}
+
+// AuthenticateAPIKey resolves an API key presented with a request to the
+// user it acts for and records when it was last used. The role is read
+// afresh on every request, so role changes apply to keys at once.
+//
+// Returns the principal, or auth.ErrInvalidAPIKey for an unknown or
+// expired key.
+func (s *Service) AuthenticateAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, auth.APIKeyPrefix) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	row, err := s.q.UseApiKey(ctx, auth.HashToken(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]auth.Scope, len(row.Scopes))
+	for i, scope := range row.Scopes {
+		scopes[i] = auth.Scope(scope)
+	}
+	return &auth.Principal{
+		UserID:   int(row.UserID),
+		Role:     auth.Role(row.Role),
+		APIKeyID: int(row.ID),
+		Scopes:   scopes,
+	}, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// Batched lookups serve callers resolving many recipes at once, such as the
+// GraphQL API's dataloaders, with one query per kind of data rather than one
+// per recipe. Unknown IDs are left out of the results.
+
+// GetRecipesByIDs retrieves the recipes with the given IDs, in no particular
+// order.
+func (s *Service) GetRecipesByIDs(ctx context.Context, ids []int) ([]db.GetRecipesByIDsRow, error) {
+	return s.q.GetRecipesByIDs(ctx, int32s(ids))
+}
+
+// ListRatingsForRecipes retrieves the ratings of several recipes, grouped by
+// recipe and newest first within each.
+func (s *Service) ListRatingsForRecipes(ctx context.Context, recipeIDs []int) ([]db.Rating, error) {
+	return s.q.ListRatingsByRecipeIDs(ctx, int32s(recipeIDs))
+}
+
+// FavoritedRecipeIDs reports which of recipeIDs the user has favorited.
+//
+// Returns the set of favorited recipe IDs; recipes missing from it are not
+// favorites.
+func (s *Service) FavoritedRecipeIDs(ctx context.Context, userID int, recipeIDs []int) (map[int]bool, error) {
+	ids, err := s.q.ListFavoritedRecipeIDs(ctx, db.ListFavoritedRecipeIDsParams{
+		UserID:    sql.NullInt32{Int32: int32(userID), Valid: true},
+		RecipeIds: int32s(recipeIDs),
+	})
+	if err != nil {
+		return nil, err
+	}
+	favorited := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if id.Valid {
+			favorited[int(id.Int32)] = true
+		}
+	}
+	return favorited, nil
+}
+
+// GetUsersByIDs retrieves the public profiles of several users.
+func (s *Service) GetUsersByIDs(ctx context.Context, ids []int) ([]db.GetUsersByIDsRow, error) {
+	return s.q.GetUsersByIDs(ctx, int32s(ids))
+}
+
+// int32s converts IDs to the type of the database's int columns.
+func int32s(ids []int) []int32 {
+	out := make([]int32, len(ids))
+	for i, id := range ids {
+		out[i] = int32(id)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/sqlc-dev/pqtype"
+)
+
+// Ingredient is an entry of a recipe's ingredients. Quantity is nil and
+// Unit empty when the recipe does not give them.
+type Ingredient struct {
+	Name     string
+	Quantity *float64
+	Unit     string
+}
+
+// RecipeIngredients decodes a recipe's ingredients for the APIs that type
+// them. Entries are objects such as {"name": "tomato", "qty": 2, "unit":
+// "pcs"} or plain names; quantities may be numbers or numeric strings.
+// Entries without a name are skipped.
+func RecipeIngredients(raw pqtype.NullRawMessage) []Ingredient {
+	var entries []json.RawMessage
+	if !raw.Valid || json.Unmarshal(raw.RawMessage, &entries) != nil {
+		return []Ingredient{}
+	}
+	out := make([]Ingredient, 0, len(entries))
+	for _, entry := range entries {
+		var name string
+		if json.Unmarshal(entry, &name) == nil {
+			out = append(out, Ingredient{Name: name})
+			continue
+		}
+		var obj struct {
+			Name     string `json:"name"`
+			Qty      any    `json:"qty"`
+			Quantity any    `json:"quantity"`
+			Unit     string `json:"unit"`
+		}
+		if json.Unmarshal(entry, &obj) != nil || obj.Name == "" {
+			continue
+		}
+		in := Ingredient{Name: obj.Name, Unit: obj.Unit}
+		qty := obj.Qty
+		if qty == nil {
+			qty = obj.Quantity
+		}
+		switch q := qty.(type) {
+		case float64:
+			in.Quantity = &q
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(q), 64); err == nil {
+				in.Quantity = &f
+			}
+		}
+		out = append(out, in)
+	}
+	return out
+}
+
+// RecipeSteps decodes a recipe's steps, a list of instructions. Entries
+// that are not strings are passed on as their JSON text.
+func RecipeSteps(raw pqtype.NullRawMessage) []string {
+	var entries []json.RawMessage
+	if !raw.Valid || json.Unmarshal(raw.RawMessage, &entries) != nil {
+		return []string{}
+	}
+	out := make([]string, len(entries))
+	for i, entry := range entries {
+		if json.Unmarshal(entry, &out[i]) != nil {
+			out[i] = string(entry)
+		}
+	}
+	return out
+}
+
+// AverageRating parses the average rating the recipe queries compute as
+// text, e.g. "4.5". Recipes without ratings average 0.
+func AverageRating(v any) float64 {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// pqUniqueViolation is the Postgres error code for a unique constraint
+// violation.
+const pqUniqueViolation = "23505"
+
+// uniqueFields maps unique constraints to the input field they guard and the
+// message reported for a duplicate.
+var uniqueFields = map[string]validate.FieldError{
+	"users_email_key":    {Field: "email", Message: "is already registered"},
+	"users_username_key": {Field: "username", Message: "is already taken"},
+}
+
+// ConflictError is returned when a write would duplicate a value that must
+// be unique, such as a registered email.
+type ConflictError struct {
+	Fields validate.Errors
+}
+
+// Error describes the conflicting fields, e.g. "email is already registered".
+func (e *ConflictError) Error() string {
+	if len(e.Fields) == 0 {
+		return "already exists"
+	}
+	return e.Fields[0].Field + " " + e.Fields[0].Message
+}
+
+// conflictFrom converts a Postgres unique violation into a ConflictError.
+// Other errors are returned unchanged.
+func conflictFrom(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != pqUniqueViolation {
+		return err
+	}
+	field, ok := uniqueFields[pqErr.Constraint]
+	if !ok {
+		return &ConflictError{}
+	}
+	return &ConflictError{Fields: validate.Errors{field}}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// FilterSpec is the shared filter specification for /recipes, /match and
+// /suggestions, so every endpoint narrows recipes the same way.
+//
+// Values within one list field are ORed (cuisine is Italian or Thai);
+// different fields are ANDed. Nil and empty fields do not filter.
+//
+// Time semantics:
+//   - MaxTotalTime compares total_time_minutes, falling back to
+//     prep_time_minutes + cook_time_minutes when the total is missing
+//   - MaxPrepTime compares prep_time_minutes
+//   - MaxCookTime compares cook_time_minutes
+//
+// Recipes with an unknown value for a filtered field never match.
+//
+// Diets match both the diet_type column and recipe tags, expanded through
+// the diet hierarchy (see dietImplies): a vegan recipe matches a
+// "vegetarian" filter, but not the other way round.
+//
+// The FilterRecipes and CountRecipeFacets queries apply these rules in SQL
+// and must be kept in step with each other.
+type FilterSpec struct {
+	Diets        []string
+	Difficulties []string
+	Cuisines     []string
+	Tags         []string
+	MaxTotalTime *int
+	MaxPrepTime  *int
+	MaxCookTime  *int
+	MinServings  *int
+	MaxServings  *int
+}
+
+// dietImplies lists the diets a recipe also satisfies when it satisfies the
+// key. The relation is applied transitively.
+var dietImplies = map[string][]string{
+	"vegan":       {"vegetarian", "dairy-free", "egg-free"},
+	"plant-based": {"vegan"},
+	"vegetarian":  {"pescatarian"},
+	"keto":        {"low-carb"},
+}
+
+// knownDiets are the tags that describe a diet rather than a dish, used to
+// build the diet_type facet from tags.
+var knownDiets = map[string]bool{
+	"vegan": true, "plant-based": true, "vegetarian": true, "pescatarian": true,
+	"gluten-free": true, "dairy-free": true, "egg-free": true, "nut-free": true,
+	"keto": true, "low-carb": true, "paleo": true, "halal": true, "kosher": true,
+}
+
+// knownDietList, impliedFrom and impliedTo pass knownDiets and the
+// transitive closure of dietImplies to CountRecipeFacets: a recipe of diet
+// impliedFrom[i] is also counted under impliedTo[i].
+var knownDietList, impliedFrom, impliedTo = dietFacetParams()
+
+func dietFacetParams() (known, from, to []string) {
+	diets := map[string]bool{}
+	for d, implied := range dietImplies {
+		diets[d] = true
+		for _, i := range implied {
+			diets[i] = true
+		}
+	}
+	for d := range knownDiets {
+		known = append(known, d)
+		diets[d] = true
+	}
+	sort.Strings(known)
+
+	all := make([]string, 0, len(diets))
+	for d := range diets {
+		all = append(all, d)
+	}
+	sort.Strings(all)
+	for _, d := range all {
+		for _, target := range all {
+			if implies(d, target, map[string]bool{}) {
+				from = append(from, d)
+				to = append(to, target)
+			}
+		}
+	}
+	return known, from, to
+}
+
+// Facet dimensions reported by RecipeFacets.
+const (
+	FacetCuisine    = "cuisine"
+	FacetDifficulty = "difficulty"
+	FacetDietType   = "diet_type"
+	FacetTag        = "tag"
+	FacetCookTime   = "cook_time"
+	FacetTotalTime  = "total_time"
+)
+
+// FacetValue is a single facet bucket and the number of recipes in it.
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets maps a facet dimension to its buckets, ordered by descending count.
+type Facets map[string][]FacetValue
+
+// timeBuckets are the labels of the cook-time and total-time facet buckets,
+// in order; the CountRecipeFacets query sorts times into them.
+var timeBuckets = []string{"<15", "15-30", "30-60", "60+"}
+
+// SplitList flattens repeated and comma-separated query values
+// (?cuisine=Italian,Thai&cuisine=French) into a trimmed list.
+func SplitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if p := strings.TrimSpace(part); p != "" {
+				out = append(out, p)
+			}
+		}
+	}
+	return out
+}
+
+// acceptedDiets returns the lower-cased diets whose recipes satisfy any of
+// the requested diets: the diets themselves plus every diet that implies
+// them, so "vegetarian" accepts vegan and plant-based recipes too.
+func acceptedDiets(requested []string) []string {
+	seen := map[string]bool{}
+	// Never nil: FilterRecipes reads a NULL array as no recipe matching.
+	out := []string{}
+	accept := func(d string) {
+		if !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	for _, d := range requested {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		accept(d)
+		for diet := range dietImplies {
+			if implies(diet, d, map[string]bool{}) {
+				accept(diet)
+			}
+		}
+	}
+	return out
+}
+
+// implies reports whether a recipe satisfying diet also satisfies target.
+func implies(diet, target string, visited map[string]bool) bool {
+	if visited[diet] {
+		return false
+	}
+	visited[diet] = true
+	for _, d := range dietImplies[diet] {
+		if d == target || implies(d, target, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipeFacets counts recipes matching query per cuisine, difficulty, diet,
+// tag, and cook-time and total-time bucket.
+//
+// Diet counts use the same expansion as the diet filter, so a vegan recipe
+// is counted under both "vegan" and "vegetarian".
+//
+// Each dimension's counts apply every active filter except that dimension's
+// own, so selecting "Italian" still shows how many Thai recipes exist under
+// the remaining filters.
+//
+// Parameters:
+//   - ctx: request context
+//   - query: full-text search query (empty = all recipes)
+//   - filters: active filters
+//
+// Returns facet buckets keyed by dimension.
+func (s *Service) RecipeFacets(ctx context.Context, query string, filters FilterSpec) (Facets, error) {
+	rows, err := s.q.CountRecipeFacets(ctx, db.CountRecipeFacetsParams{
+		Tsquery:      BuildTSQuery(query),
+		Query:        query,
+		Diets:        acceptedDiets(filters.Diets),
+		Difficulties: lowerAll(filters.Difficulties),
+		Cuisines:     lowerAll(filters.Cuisines),
+		Tags:         lowerAll(filters.Tags),
+		MaxTotalTime: nullInt32(filters.MaxTotalTime),
+		MaxCookTime:  nullInt32(filters.MaxCookTime),
+		MaxPrepTime:  nullInt32(filters.MaxPrepTime),
+		MinServings:  nullInt32(filters.MinServings),
+		MaxServings:  nullInt32(filters.MaxServings),
+		KnownDiets:   knownDietList,
+		ImpliedFrom:  impliedFrom,
+		ImpliedTo:    impliedTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counters := map[string]*facetCounter{
+		FacetCuisine:    newFacetCounter(),
+		FacetDifficulty: newFacetCounter(),
+		FacetDietType:   newFacetCounter(),
+		FacetTag:        newFacetCounter(),
+		FacetCookTime:   newFacetCounter(),
+		FacetTotalTime:  newFacetCounter(),
+	}
+	for _, b := range timeBuckets {
+		counters[FacetCookTime].add(b, 0)
+		counters[FacetTotalTime].add(b, 0)
+	}
+	for _, row := range rows {
+		if c, ok := counters[row.Facet]; ok {
+			c.add(row.Value, int(row.Count))
+		}
+	}
+
+	facets := Facets{}
+	for dim, c := range counters {
+		facets[dim] = c.values(dim != FacetCookTime && dim != FacetTotalTime)
+	}
+	return facets, nil
+}
+
+// facetCounter counts facet values case-insensitively, keeping the first
+// spelling seen for display.
+type facetCounter struct {
+	order  []string
+	labels map[string]string
+	counts map[string]int
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{labels: map[string]string{}, counts: map[string]int{}}
+}
+
+func (c *facetCounter) add(value string, n int) {
+	key := strings.ToLower(strings.TrimSpace(value))
+	if _, ok := c.labels[key]; !ok {
+		c.labels[key] = value
+		c.order = append(c.order, key)
+	}
+	c.counts[key] += n
+}
+
+// values returns the buckets, sorted by count when byCount is set and in
+// insertion order otherwise.
+func (c *facetCounter) values(byCount bool) []FacetValue {
+	out := make([]FacetValue, 0, len(c.order))
+	for _, key := range c.order {
+		out = append(out, FacetValue{Value: c.labels[key], Count: c.counts[key]})
+	}
+	if byCount {
+		sort.SliceStable(out, func(i, j int) bool {
+			if out[i].Count != out[j].Count {
+				return out[i].Count > out[j].Count
+			}
+			return strings.ToLower(out[i].Value) < strings.ToLower(out[j].Value)
+		})
+	}
+	return out
+}
+
+// lowerAll returns the trimmed, lower-cased values, as compared by the
+// FilterRecipes query.
+func lowerAll(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// nullInt32 converts an optional filter bound into a nullable query parameter.
+func nullInt32(v *int) sql.NullInt32 {
+	if v == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: int32(*v), Valid: true}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/vision"
+)
+
+// ErrLexiconEntryNotFound is returned when an ingredient variant is not in
+// the lexicon.
+var ErrLexiconEntryNotFound = fmt.Errorf("ingredient variant not found")
+
+// MaxIngredientNameLength limits lexicon variants and canonical names.
+const MaxIngredientNameLength = 60
+
+// LoadIngredientLexicon reads the admin-managed ingredient variants and hands
+// them to the ingredient parser. It runs at startup and after every change;
+// other instances pick changes up when they restart.
+func (s *Service) LoadIngredientLexicon(ctx context.Context) error {
+	rows, err := s.q.ListIngredientLexicon(ctx)
+	if err != nil {
+		return err
+	}
+	variants := make(map[string]string, len(rows))
+	for _, row := range rows {
+		variants[row.Variant] = row.Canonical
+	}
+	vision.SetCustomIngredients(variants)
+	return nil
+}
+
+// ListIngredientLexicon returns the admin-managed ingredient variants in
+// alphabetical order. Built-in variants are not included.
+func (s *Service) ListIngredientLexicon(ctx context.Context) ([]db.IngredientLexicon, error) {
+	rows, err := s.q.ListIngredientLexicon(ctx)
+	if rows == nil && err == nil {
+		rows = []db.IngredientLexicon{}
+	}
+	return rows, err
+}
+
+// SetIngredientVariant adds or replaces a lexicon entry mapping variant to
+// its canonical ingredient name.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the editor making the change
+//   - variant: name as it appears in captions, e.g. "aubergines"
+//   - canonical: ingredient it stands for, e.g. "eggplant"
+//
+// Returns the stored entry, or validate.Errors for invalid names.
+func (s *Service) SetIngredientVariant(ctx context.Context, actor Actor, variant, canonical string) (db.IngredientLexicon, error) {
+	variant = strings.ToLower(strings.TrimSpace(variant))
+	canonical = strings.ToLower(strings.TrimSpace(canonical))
+
+	var errs validate.Errors
+	errs.Check("variant", ingredientName(variant))
+	errs.Check("canonical", ingredientName(canonical))
+	if err := errs.Err(); err != nil {
+		return db.IngredientLexicon{}, err
+	}
+
+	row, err := s.q.UpsertIngredientLexicon(ctx, db.UpsertIngredientLexiconParams{Variant: variant, Canonical: canonical})
+	if err != nil {
+		return db.IngredientLexicon{}, err
+	}
+	s.audit(ctx, AuditEvent{Event: AuditLexiconUpdated, UserID: actor.UserID, Subject: variant, Details: map[string]any{"canonical": canonical}})
+	return row, s.LoadIngredientLexicon(ctx)
+}
+
+// DeleteIngredientVariant removes a lexicon entry. A built-in variant of the
+// same name applies again afterwards.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the editor making the change
+//   - variant: variant to remove
+//
+// Returns ErrLexiconEntryNotFound if the variant is not in the lexicon.
+func (s *Service) DeleteIngredientVariant(ctx context.Context, actor Actor, variant string) error {
+	variant = strings.ToLower(strings.TrimSpace(variant))
+	n, err := s.q.DeleteIngredientLexicon(ctx, variant)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLexiconEntryNotFound
+	}
+	s.audit(ctx, AuditEvent{Event: AuditLexiconDeleted, UserID: actor.UserID, Subject: variant})
+	return s.LoadIngredientLexicon(ctx)
+}
+
+// ingredientName checks a lowercase ingredient name: one to three words of
+// letters and digits, the only shapes the ingredient parser can match.
+func ingredientName(name string) error {
+	if name == "" {
+		return fmt.Errorf("is required")
+	}
+	if len(name) > MaxIngredientNameLength {
+		return fmt.Errorf("must be at most %d characters", MaxIngredientNameLength)
+	}
+	words := strings.Fields(name)
+	if len(words) > 3 || strings.Join(words, " ") != name {
+		return fmt.Errorf("must be one to three words separated by single spaces")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == ' ') {
+			return fmt.Errorf("may only contain letters, digits and spaces")
+		}
+	}
+	return nil
+}
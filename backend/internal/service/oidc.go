@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/oidc"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// ErrUnknownProvider is returned for an OIDC provider that is not configured.
+var ErrUnknownProvider = fmt.Errorf("unknown identity provider")
+
+// ErrInvalidOIDCState is returned when a login callback carries an unknown,
+// expired or already used state.
+var ErrInvalidOIDCState = fmt.Errorf("invalid or expired login state")
+
+// ErrOIDCLoginFailed is returned when the provider refuses the authorization
+// code or its ID token fails validation.
+var ErrOIDCLoginFailed = fmt.Errorf("sign-in with the identity provider failed")
+
+// ErrOIDCEmailRequired is returned when a new identity comes without a
+// usable email address, which every account needs.
+var ErrOIDCEmailRequired = fmt.Errorf("the identity provider did not share an email address")
+
+// ErrOIDCEmailUnverified is returned when a new identity's email belongs to
+// an existing account but either the provider or the account has not
+// verified it, so it cannot be trusted to link the two.
+var ErrOIDCEmailUnverified = fmt.Errorf("an account with this email already exists; sign in with your password")
+
+// usernameAttempts bounds the random suffixes tried when the username derived
+// for a new user is taken.
+const usernameAttempts = 5
+
+// OIDCSettings configures sign-in with OpenID Connect providers.
+type OIDCSettings struct {
+	// Providers users can sign in with, in the order they are listed.
+	Providers []*oidc.Provider
+	// StateTTL is how long a user has to complete a login at the provider.
+	StateTTL time.Duration
+}
+
+// OIDCProviders returns the configured identity providers.
+func (s *Service) OIDCProviders() []*oidc.Provider {
+	return s.OIDC.Providers
+}
+
+func (s *Service) oidcProvider(name string) (*oidc.Provider, error) {
+	for _, p := range s.OIDC.Providers {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, ErrUnknownProvider
+}
+
+// StartOIDCLogin begins a login with an identity provider. The state, nonce
+// and PKCE verifier are kept server-side until the user comes back.
+//
+// Parameters:
+//   - ctx: request context
+//   - provider: configured provider name
+//
+// Returns the provider URL to send the user to, or ErrUnknownProvider.
+func (s *Service) StartOIDCLogin(ctx context.Context, provider string) (string, error) {
+	p, err := s.oidcProvider(provider)
+	if err != nil {
+		return "", err
+	}
+
+	var values [3]string
+	for i := range values {
+		if values[i], err = oidc.RandomString(); err != nil {
+			return "", err
+		}
+	}
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	url, err := p.AuthCodeURL(ctx, state, nonce, oidc.S256Challenge(verifier))
+	if err != nil {
+		return "", err
+	}
+	err = s.q.CreateOidcLoginState(ctx, db.CreateOidcLoginStateParams{
+		StateHash:    auth.HashToken(state),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.OIDC.StateTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// FinishOIDCLogin completes a login when the provider redirects back: it
+// redeems the code, validates the ID token and finds the user the identity
+// belongs to.
+//
+// An identity seen for the first time is linked to the account with the
+// same email if both the provider and the account verified that email, and
+// refused if either did not; without such an account a new one without a
+// password is created.
+//
+// Parameters:
+//   - ctx: request context
+//   - provider: configured provider name
+//   - state: state parameter from the redirect
+//   - code: authorization code from the redirect
+//   - ip: client IP address, for the audit log
+//
+// Returns the signed-in user's ID, or ErrUnknownProvider,
+// ErrInvalidOIDCState, ErrOIDCLoginFailed, ErrOIDCEmailRequired or
+// ErrOIDCEmailUnverified.
+func (s *Service) FinishOIDCLogin(ctx context.Context, provider, state, code, ip string) (_ int, err error) {
+	defer func() { s.Metrics.ObserveLogin(loginMethodOIDC, oidcLoginResult(err)) }()
+
+	p, err := s.oidcProvider(provider)
+	if err != nil {
+		return 0, err
+	}
+
+	pending, err := s.q.ConsumeOidcLoginState(ctx, db.ConsumeOidcLoginStateParams{StateHash: auth.HashToken(state), Provider: provider})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrInvalidOIDCState
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	raw, err := p.Exchange(ctx, code, pending.CodeVerifier)
+	var tokenErr *oidc.TokenError
+	if errors.As(err, &tokenErr) {
+		return 0, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	id, err := p.VerifyIDToken(ctx, raw, pending.Nonce)
+	if errors.Is(err, oidc.ErrInvalidIDToken) {
+		return 0, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := s.userForIdentity(ctx, provider, id, ip)
+	if err != nil {
+		return 0, err
+	}
+	s.audit(ctx, AuditEvent{Event: AuditLoginSucceeded, UserID: userID, IPAddress: ip, Details: map[string]any{"provider": provider}})
+	return userID, nil
+}
+
+// oidcLoginResult classifies the outcome of FinishOIDCLogin for metrics:
+// refused or invalid logins are failures, anything else unexpected an error.
+func oidcLoginResult(err error) string {
+	switch {
+	case err == nil:
+		return metrics.ResultSuccess
+	case errors.Is(err, ErrUnknownProvider), errors.Is(err, ErrInvalidOIDCState), errors.Is(err, ErrOIDCLoginFailed),
+		errors.Is(err, ErrOIDCEmailRequired), errors.Is(err, ErrOIDCEmailUnverified):
+		return metrics.ResultFailure
+	default:
+		return metrics.ResultError
+	}
+}
+
+// userForIdentity returns the user a provider identity is linked to,
+// linking or creating one on first sign-in.
+func (s *Service) userForIdentity(ctx context.Context, provider string, id *oidc.IDToken, ip string) (int, error) {
+	email := strings.TrimSpace(id.Email)
+	if validate.Email(email) != nil {
+		email = ""
+	}
+	nullEmail := sql.NullString{String: email, Valid: email != ""}
+
+	userID, err := s.q.UseUserIdentity(ctx, db.UseUserIdentityParams{Email: nullEmail, Provider: provider, Subject: id.Subject})
+	if err == nil {
+		return int(userID), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	if email == "" {
+		return 0, ErrOIDCEmailRequired
+	}
+
+	existing, err := s.q.GetUserByEmail(ctx, nullEmail)
+	switch {
+	case err == nil:
+		// Both sides must have proven the address: otherwise someone could
+		// sign up with a victim's email before them and be linked to the
+		// victim's later identity, or the reverse.
+		if !id.EmailVerified || !existing.EmailVerifiedAt.Valid {
+			return 0, ErrOIDCEmailUnverified
+		}
+		err = s.q.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			UserID:   existing.ID,
+			Provider: provider,
+			Subject:  id.Subject,
+			Email:    nullEmail,
+		})
+		if err != nil {
+			return 0, err
+		}
+		s.audit(ctx, AuditEvent{Event: AuditIdentityLinked, UserID: int(existing.ID), IPAddress: ip, Details: map[string]any{"provider": provider}})
+		return int(existing.ID), nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return 0, err
+	}
+
+	base := usernameFor(id)
+	username := base
+	for attempt := 0; ; attempt++ {
+		userID, err = s.q.CreateUserWithIdentity(ctx, db.CreateUserWithIdentityParams{
+			Username:      sql.NullString{String: username, Valid: true},
+			Email:         nullEmail,
+			EmailVerified: id.EmailVerified,
+			Provider:      provider,
+			Subject:       id.Subject,
+		})
+		err = conflictFrom(err)
+		var conflict *ConflictError
+		if !errors.As(err, &conflict) || len(conflict.Fields) == 0 || conflict.Fields[0].Field != "username" || attempt == usernameAttempts {
+			break
+		}
+		suffix, randErr := rand.Int(rand.Reader, big.NewInt(10000))
+		if randErr != nil {
+			return 0, randErr
+		}
+		username = fmt.Sprintf("%s-%04d", base, suffix.Int64())
+	}
+	if err != nil {
+		return 0, err
+	}
+	s.audit(ctx, AuditEvent{Event: AuditIdentityLinked, UserID: int(userID), IPAddress: ip, Details: map[string]any{"provider": provider, "new_user": true}})
+	return int(userID), nil
+}
+
+// usernameFor derives a valid username for a new user from the identity's
+// preferred username or email, leaving room for a "-NNNN" suffix.
+func usernameFor(id *oidc.IDToken) string {
+	source := id.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(id.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range source {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case (r == '.' || r == '_' || r == '-') && b.Len() > 0:
+			b.WriteRune(r)
+		}
+		if b.Len() == validate.MaxUsernameLength-5 {
+			break
+		}
+	}
+	name := b.String()
+	if len(name) < validate.MinUsernameLength {
+		name = "user" + name
+	}
+	return name
+}
+
+// ListIdentities returns the provider identities linked to a user.
+func (s *Service) ListIdentities(ctx context.Context, userID int) ([]db.ListUserIdentitiesRow, error) {
+	rows, err := s.q.ListUserIdentities(ctx, int32(userID))
+	if rows == nil && err == nil {
+		rows = []db.ListUserIdentitiesRow{}
+	}
+	return rows, err
+}
+
+// PruneOIDCLoginStates deletes logins that were never completed.
+func (s *Service) PruneOIDCLoginStates(ctx context.Context) error {
+	return s.q.DeleteExpiredOidcLoginStates(ctx)
+}
@@ -0,0 +1,186 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/oidc"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/oidc/oidctest"
+)
+
+// withMockOIDC makes s offer sign-in with the provider cmd/mockoidc runs,
+// which it returns.
+func withMockOIDC(t *testing.T, s *Service) *oidctest.Provider {
+	t.Helper()
+	mock, srv, err := oidctest.NewServer("recipes", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Close)
+	s.OIDC = OIDCSettings{
+		Providers: []*oidc.Provider{oidc.NewProvider(oidc.Config{
+			Name:         "mock",
+			Issuer:       mock.Issuer,
+			ClientID:     "recipes",
+			ClientSecret: "secret",
+			RedirectURL:  "http://app.test/auth/oidc/mock/callback",
+		}, nil)},
+		StateTTL: time.Minute,
+	}
+	return mock
+}
+
+// mockUser returns a provider user with a unique subject and address.
+func mockUser(verified bool) oidctest.User {
+	name := fmt.Sprintf("o%d-%d", time.Now().UnixNano()%1e9, testUsers.Add(1))
+	return oidctest.User{Subject: "sub-" + name, Email: name + "@example.com", EmailVerified: verified, Name: name, PreferredUsername: name}
+}
+
+// authorize starts a login and follows it to the provider, returning the
+// state and code the provider redirects back with.
+func authorize(t *testing.T, s *Service) (state, code string) {
+	t.Helper()
+	loginURL, err := s.StartOIDCLogin(context.Background(), "mock")
+	if err != nil {
+		t.Fatalf("starting login: %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(loginURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	back, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("provider answered %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	q := back.Query()
+	if e := q.Get("error"); e != "" {
+		t.Fatalf("provider refused the login: %s %s", e, q.Get("error_description"))
+	}
+	return q.Get("state"), q.Get("code")
+}
+
+// signIn completes a login as the provider's current user.
+func signIn(t *testing.T, s *Service) (int, error) {
+	t.Helper()
+	state, code := authorize(t, s)
+	return s.FinishOIDCLogin(context.Background(), "mock", state, code, "127.0.0.1")
+}
+
+// deleteAfter deletes a user signing in created when the test ends.
+func deleteAfter(t *testing.T, s *Service, userID int) {
+	t.Cleanup(func() {
+		if _, err := s.q.DeleteUser(context.Background(), int32(userID)); err != nil {
+			t.Errorf("deleting user %d: %v", userID, err)
+		}
+	})
+}
+
+func TestOIDCLoginCreatesUserOnce(t *testing.T) {
+	s := testService(t)
+	mock := withMockOIDC(t, s)
+	mock.User = mockUser(true)
+
+	first, err := signIn(t, s)
+	if err != nil {
+		t.Fatalf("first sign-in: %v", err)
+	}
+	deleteAfter(t, s, first)
+
+	second, err := signIn(t, s)
+	if err != nil {
+		t.Fatalf("second sign-in: %v", err)
+	}
+	if second != first {
+		t.Errorf("second sign-in as user %d, want %d", second, first)
+	}
+}
+
+func TestOIDCLoginStateIsSingleUse(t *testing.T) {
+	s := testService(t)
+	mock := withMockOIDC(t, s)
+	mock.User = mockUser(true)
+	ctx := context.Background()
+
+	state, code := authorize(t, s)
+	userID, err := s.FinishOIDCLogin(ctx, "mock", state, code, "127.0.0.1")
+	if err != nil {
+		t.Fatalf("sign-in: %v", err)
+	}
+	deleteAfter(t, s, userID)
+
+	if _, err := s.FinishOIDCLogin(ctx, "mock", state, code, "127.0.0.1"); !errors.Is(err, ErrInvalidOIDCState) {
+		t.Errorf("replaying the callback: got %v, want ErrInvalidOIDCState", err)
+	}
+}
+
+func TestOIDCLoginRejectsWrongVerifier(t *testing.T) {
+	s := testService(t)
+	withMockOIDC(t, s)
+	ctx := context.Background()
+
+	// The code belongs to the first login; redeeming it with the second
+	// login's state sends that login's PKCE verifier instead.
+	_, code := authorize(t, s)
+	state, _ := authorize(t, s)
+	if _, err := s.FinishOIDCLogin(ctx, "mock", state, code, "127.0.0.1"); !errors.Is(err, ErrOIDCLoginFailed) {
+		t.Errorf("got %v, want ErrOIDCLoginFailed", err)
+	}
+}
+
+func TestOIDCLoginLinksVerifiedAccount(t *testing.T) {
+	s := testService(t)
+	mock := withMockOIDC(t, s)
+	user := createTestUser(t, s)
+	if err := s.q.MarkEmailVerified(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	mock.User = mockUser(true)
+	mock.User.Email = user.Email.String
+
+	userID, err := signIn(t, s)
+	if err != nil {
+		t.Fatalf("sign-in: %v", err)
+	}
+	if userID != int(user.ID) {
+		t.Errorf("signed in as user %d, want the existing user %d", userID, user.ID)
+	}
+}
+
+// TestOIDCLoginDoesNotLinkUnverifiedAccount covers an account registered
+// with someone else's address before they sign in with their provider: it
+// must not be handed their identity.
+func TestOIDCLoginDoesNotLinkUnverifiedAccount(t *testing.T) {
+	s := testService(t)
+	mock := withMockOIDC(t, s)
+	user := createTestUser(t, s)
+	mock.User = mockUser(true)
+	mock.User.Email = user.Email.String
+
+	if _, err := signIn(t, s); !errors.Is(err, ErrOIDCEmailUnverified) {
+		t.Errorf("got %v, want ErrOIDCEmailUnverified", err)
+	}
+}
+
+func TestOIDCLoginDoesNotLinkUnverifiedIdentity(t *testing.T) {
+	s := testService(t)
+	mock := withMockOIDC(t, s)
+	user := createTestUser(t, s)
+	if err := s.q.MarkEmailVerified(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+	mock.User = mockUser(false)
+	mock.User.Email = user.Email.String
+
+	if _, err := signIn(t, s); !errors.Is(err, ErrOIDCEmailUnverified) {
+		t.Errorf("got %v, want ErrOIDCEmailUnverified", err)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// was issued for a different ordering.
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
+// Page is one page of a keyset-paginated listing.
+//
+// NextCursor is empty on the last page. Total counts every item matching the
+// request, not just the ones on this page.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	Total      int
+}
+
+// cursor is the position of the last item of a page: its sort key and ID.
+// Order records the ordering the cursor was issued for, so a cursor cannot be
+// replayed against a different sort.
+type cursor struct {
+	Key   float64 `json:"k"`
+	ID    int32   `json:"id"`
+	Order string  `json:"o,omitempty"`
+}
+
+// encodeCursor returns the opaque, URL-safe form of c.
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor parses an opaque cursor issued for order. An empty string
+// decodes to nil, meaning the first page.
+func decodeCursor(s, order string) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Order != order {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// PopularityWindow selects the time range used to compute trending and popular feeds.
+type PopularityWindow string
+
+// Supported popularity windows. They must match the windows materialised by
+// the recipe_popularity view.
+const (
+	WindowDay  PopularityWindow = "day"
+	WindowWeek PopularityWindow = "week"
+	WindowAll  PopularityWindow = "all"
+)
+
+// Ranking modes for ListRecipesByPopularity.
+const (
+	RankTrending = "trending"
+	RankPopular  = "popular"
+)
+
+// Sort options accepted by SearchAndFilterRecipes. An empty sort keeps the
+// default ordering by recipe ID.
+const (
+	SortRating   = "rating"
+	SortNewest   = "newest"
+	SortQuickest = "quickest"
+	SortPopular  = "popular"
+)
+
+// ErrInvalidWindow is returned when a popularity window is not recognised.
+var ErrInvalidWindow = fmt.Errorf("invalid popularity window")
+
+// ErrInvalidSort is returned when a sort option is not recognised.
+var ErrInvalidSort = fmt.Errorf("invalid sort option")
+
+// ParsePopularityWindow converts a query parameter into a PopularityWindow.
+// An empty value defaults to the weekly window.
+func ParsePopularityWindow(v string) (PopularityWindow, error) {
+	switch PopularityWindow(strings.ToLower(strings.TrimSpace(v))) {
+	case "":
+		return WindowWeek, nil
+	case WindowDay:
+		return WindowDay, nil
+	case WindowWeek:
+		return WindowWeek, nil
+	case WindowAll, "all-time", "alltime":
+		return WindowAll, nil
+	}
+	return "", ErrInvalidWindow
+}
+
+// ParseSort validates a recipe sort option. An empty value is allowed and
+// keeps the default ordering.
+func ParseSort(v string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(v))
+	switch s {
+	case "", SortRating, SortNewest, SortQuickest, SortPopular:
+		return s, nil
+	}
+	return "", ErrInvalidSort
+}
+
+// ListTrendingRecipes returns recipes ranked by time-decayed engagement.
+//
+// Favorites, ratings and views inside the window are weighted and decayed
+// by age, so recent activity outranks older activity of the same size.
+//
+// Parameters:
+//   - ctx: request context
+//   - window: time window to rank within
+//   - limit: maximum number of recipes to return
+//   - offset: number of recipes to skip (for pagination)
+//
+// Returns ranked recipes with their engagement counts.
+func (s *Service) ListTrendingRecipes(ctx context.Context, window PopularityWindow, limit, offset int) ([]db.ListRecipesByPopularityRow, error) {
+	return s.listByPopularity(ctx, window, RankTrending, limit, offset)
+}
+
+// ListPopularRecipes returns recipes ranked by total engagement in a window.
+//
+// Unlike ListTrendingRecipes, events are not decayed by age.
+//
+// Parameters:
+//   - ctx: request context
+//   - window: time window to rank within
+//   - limit: maximum number of recipes to return
+//   - offset: number of recipes to skip (for pagination)
+//
+// Returns ranked recipes with their engagement counts.
+func (s *Service) ListPopularRecipes(ctx context.Context, window PopularityWindow, limit, offset int) ([]db.ListRecipesByPopularityRow, error) {
+	return s.listByPopularity(ctx, window, RankPopular, limit, offset)
+}
+
+func (s *Service) listByPopularity(ctx context.Context, window PopularityWindow, rankBy string, limit, offset int) ([]db.ListRecipesByPopularityRow, error) {
+	return s.q.ListRecipesByPopularity(ctx, db.ListRecipesByPopularityParams{
+		TimeWindow: string(window),
+		RankBy:     rankBy,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+}
+
+// ViewWindow is how long repeated views of a recipe by one viewer count as
+// one, so that reloading a page does not inflate its popularity.
+const ViewWindow = 30 * time.Minute
+
+// Viewer identifies who viewed a recipe: a signed-in user or, for anonymous
+// views, the client IP address.
+type Viewer struct {
+	UserID int
+	IP     string
+}
+
+// viewerKey returns the viewer stored with a view. IP addresses are only
+// compared, so they are stored as an HMAC keyed with s.ViewerKey: a plain
+// hash of the small IPv4 space could be reversed by trying every address.
+func (s *Service) viewerKey(v Viewer) string {
+	if v.UserID > 0 {
+		return fmt.Sprintf("user:%d", v.UserID)
+	}
+	mac := hmac.New(sha256.New, s.ViewerKey)
+	mac.Write([]byte(v.IP))
+	return "ip:" + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// RecordView records that a recipe was viewed, unless the same viewer
+// already viewed it within ViewWindow.
+//
+// Parameters:
+//   - ctx: request context
+//   - recipeID: ID of the viewed recipe
+//   - viewer: the user or client IP the view came from
+//
+// Returns error if the view could not be stored.
+func (s *Service) RecordView(ctx context.Context, recipeID int, viewer Viewer) error {
+	return s.q.RecordRecipeView(ctx, db.RecordRecipeViewParams{
+		RecipeID: int32(recipeID),
+		UserID:   sql.NullInt32{Int32: int32(viewer.UserID), Valid: viewer.UserID > 0},
+		Viewer:   s.viewerKey(viewer),
+		Since:    time.Now().Add(-ViewWindow),
+	})
+}
+
+// RefreshPopularity recomputes the materialised popularity scores.
+// The refresh runs concurrently, so readers keep seeing the previous scores
+// until it completes.
+func (s *Service) RefreshPopularity(ctx context.Context) error {
+	return s.q.RefreshRecipePopularity(ctx)
+}
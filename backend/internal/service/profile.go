@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/mail"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// purposeChangeEmail confirms a new email address, stored in
+// users.pending_email until the link is followed.
+const purposeChangeEmail = "change_email"
+
+// Profile field limits.
+const (
+	MaxDisplayNameLength = 50
+	MaxAvatarURLLength   = 500
+)
+
+// ProfileInput holds changes to a user's profile. Nil fields are left as
+// they are; an empty DisplayName or AvatarURL clears it.
+type ProfileInput struct {
+	Username    *string
+	DisplayName *string
+	AvatarURL   *string
+}
+
+// AccountData is everything stored about a user, gathered for an export.
+type AccountData struct {
+	Profile db.GetUserProfileRow
+	// FavoriteTags counts the tags of the user's favorites, the preferences
+	// suggestions are ranked by.
+	FavoriteTags map[string]int
+	Favorites    []db.ListFavoritesByUserRow
+	Ratings      []db.ListRatingsByUserRow
+	Recipes      []db.ListRecipesByAuthorRow
+	Views        []db.ListRecipeViewsByUserRow
+	Sessions     []db.ListUserSessionsRow
+	APIKeys      []db.ApiKey
+	Identities   []db.ListUserIdentitiesRow
+	Events       []db.ListAuditEventsByUserRow
+}
+
+// GetProfile returns a user's profile.
+func (s *Service) GetProfile(ctx context.Context, userID int) (db.GetUserProfileRow, error) {
+	return s.q.GetUserProfile(ctx, int32(userID))
+}
+
+// UpdateProfile changes a user's username, display name or avatar.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - in: fields to change
+//
+// Returns the updated profile, validate.Errors for invalid input, or a
+// ConflictError if the username is taken.
+func (s *Service) UpdateProfile(ctx context.Context, userID int, in ProfileInput) (db.GetUserProfileRow, error) {
+	profile, err := s.q.GetUserProfile(ctx, int32(userID))
+	if err != nil {
+		return db.GetUserProfileRow{}, err
+	}
+
+	var errs validate.Errors
+	if in.Username != nil {
+		username := strings.TrimSpace(*in.Username)
+		errs.Check("username", validate.Username(username))
+		profile.Username = sql.NullString{String: username, Valid: true}
+	}
+	if in.DisplayName != nil {
+		name := strings.TrimSpace(*in.DisplayName)
+		errs.Check("display_name", checkDisplayName(name))
+		profile.DisplayName = sql.NullString{String: name, Valid: name != ""}
+	}
+	if in.AvatarURL != nil {
+		avatar := strings.TrimSpace(*in.AvatarURL)
+		errs.Check("avatar_url", checkAvatarURL(avatar))
+		profile.AvatarUrl = sql.NullString{String: avatar, Valid: avatar != ""}
+	}
+	if err := errs.Err(); err != nil {
+		return db.GetUserProfileRow{}, err
+	}
+
+	err = s.q.UpdateUserProfile(ctx, db.UpdateUserProfileParams{
+		ID:          profile.ID,
+		Username:    profile.Username,
+		DisplayName: profile.DisplayName,
+		AvatarUrl:   profile.AvatarUrl,
+	})
+	if err != nil {
+		return db.GetUserProfileRow{}, conflictFrom(err)
+	}
+	return profile, nil
+}
+
+// checkDisplayName allows any printable text up to MaxDisplayNameLength
+// characters.
+func checkDisplayName(name string) error {
+	if utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return fmt.Errorf("must be at most %d characters", MaxDisplayNameLength)
+	}
+	for _, r := range name {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("must not contain control characters")
+		}
+	}
+	return nil
+}
+
+// checkAvatarURL allows an absolute http(s) URL; the image itself is not
+// fetched.
+func checkAvatarURL(avatar string) error {
+	if avatar == "" {
+		return nil
+	}
+	if len(avatar) > MaxAvatarURLLength {
+		return fmt.Errorf("must be at most %d characters", MaxAvatarURLLength)
+	}
+	u, err := url.Parse(avatar)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.User != nil {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// RequestEmailChange starts changing a user's email: a confirmation link is
+// sent to the new address, and the current one is told about the request.
+// The email only changes once the link is followed, see ConfirmEmailChange.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - email: the new address
+//   - password: the user's current password; ignored for accounts that
+//     only sign in with an identity provider
+//
+// Returns ErrWrongPassword, validate.Errors for an invalid address, or a
+// ConflictError if another account uses it.
+func (s *Service) RequestEmailChange(ctx context.Context, userID int, email, password string) error {
+	email = strings.TrimSpace(email)
+	if err := validate.Email(email); err != nil {
+		return validate.Errors{{Field: "email", Message: err.Error()}}
+	}
+	user, err := s.q.GetUserCredentials(ctx, int32(userID))
+	if err != nil {
+		return err
+	}
+	if err := checkCurrentPassword(user.PasswordHash, password); err != nil {
+		return err
+	}
+	if strings.EqualFold(email, user.Email.String) {
+		return validate.Errors{{Field: "email", Message: "is already your email address"}}
+	}
+	_, err = s.q.GetUserByEmail(ctx, sql.NullString{String: email, Valid: true})
+	if err == nil {
+		return &ConflictError{Fields: validate.Errors{uniqueFields["users_email_key"]}}
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if err := s.q.SetPendingEmail(ctx, db.SetPendingEmailParams{ID: user.ID, PendingEmail: sql.NullString{String: email, Valid: true}}); err != nil {
+		return err
+	}
+	// Only the link for the latest requested address works.
+	if err := s.q.InvalidateAccountTokens(ctx, db.InvalidateAccountTokensParams{UserID: user.ID, Purpose: purposeChangeEmail}); err != nil {
+		return err
+	}
+	token, err := s.issueAccountToken(ctx, user.ID, purposeChangeEmail, s.Mail.VerifyTTL)
+	if err != nil {
+		return err
+	}
+	err = s.sendMail(ctx, mail.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body: "Confirm that this is the new email address of your Smart Recipe Generator account by opening this link:\n\n" +
+			s.accountLink("/confirm-email", token) + "\n\n" +
+			fmt.Sprintf("The link expires in %s. Until then your account keeps its current address.\n", s.Mail.VerifyTTL),
+	})
+	if err != nil || !user.Email.Valid {
+		return err
+	}
+	return s.sendMail(ctx, mail.Message{
+		To:      user.Email.String,
+		Subject: "Your email address is being changed",
+		Body: "Someone asked to change the email address of your Smart Recipe Generator account to " + email + ".\n\n" +
+			"If this was not you, reset your password right away; the change only happens once the new address is confirmed.\n",
+	})
+}
+
+// ConfirmEmailChange replaces a user's email with the address a change was
+// requested to. The new address counts as verified, and password reset
+// links sent to the old one stop working.
+//
+// Parameters:
+//   - ctx: request context
+//   - token: token from the confirmation email
+//
+// Returns ErrInvalidAccountToken if the token is unknown, expired or used,
+// or a ConflictError if another account took the address meanwhile.
+func (s *Service) ConfirmEmailChange(ctx context.Context, token string) error {
+	userID, err := s.consumeAccountToken(ctx, token, purposeChangeEmail)
+	if err != nil {
+		return err
+	}
+	user, err := s.q.GetUserCredentials(ctx, userID)
+	if err != nil {
+		return err
+	}
+	email, err := s.q.ConfirmEmailChange(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidAccountToken
+	}
+	if err != nil {
+		return conflictFrom(err)
+	}
+	if err := s.q.InvalidateAccountTokens(ctx, db.InvalidateAccountTokensParams{UserID: userID, Purpose: purposeResetPassword}); err != nil {
+		return err
+	}
+	s.audit(ctx, AuditEvent{Event: AuditEmailChanged, UserID: int(userID), Details: map[string]any{"from": user.Email.String, "to": email.String}})
+	return nil
+}
+
+// ExportAccount gathers everything stored about a user: profile, inferred
+// preferences, favorites, ratings, authored recipes, viewing history,
+// sessions, API keys, linked identities and the user's audit events.
+// Secrets such as password and key hashes are never included.
+func (s *Service) ExportAccount(ctx context.Context, userID int) (AccountData, error) {
+	var data AccountData
+	var err error
+	uid := sql.NullInt32{Int32: int32(userID), Valid: true}
+
+	if data.Profile, err = s.q.GetUserProfile(ctx, int32(userID)); err != nil {
+		return AccountData{}, err
+	}
+	if data.Favorites, err = s.ListFavorites(ctx, userID); err != nil {
+		return AccountData{}, err
+	}
+	data.FavoriteTags = s.favoriteTagCounts(ctx, data.Favorites)
+	if data.Ratings, err = s.q.ListRatingsByUser(ctx, uid); err != nil {
+		return AccountData{}, err
+	}
+	if data.Recipes, err = s.q.ListRecipesByAuthor(ctx, uid); err != nil {
+		return AccountData{}, err
+	}
+	if data.Views, err = s.q.ListRecipeViewsByUser(ctx, uid); err != nil {
+		return AccountData{}, err
+	}
+	if data.Sessions, err = s.ListSessions(ctx, userID); err != nil {
+		return AccountData{}, err
+	}
+	if data.APIKeys, err = s.ListAPIKeys(ctx, userID); err != nil {
+		return AccountData{}, err
+	}
+	if data.Identities, err = s.ListIdentities(ctx, userID); err != nil {
+		return AccountData{}, err
+	}
+	if data.Events, err = s.q.ListAuditEventsByUser(ctx, uid); err != nil {
+		return AccountData{}, err
+	}
+	return data, nil
+}
+
+// DeleteAccount permanently deletes a user. Favorites, ratings, views,
+// sessions, account tokens, API keys and identities go with the account;
+// recipes the user wrote stay without an author. Access tokens still in
+// flight are denylisted first.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - password: the user's current password; ignored for accounts that
+//     only sign in with an identity provider
+//   - ip: client IP address, for the audit log
+//
+// Returns ErrWrongPassword if the password does not match.
+func (s *Service) DeleteAccount(ctx context.Context, userID int, password, ip string) error {
+	user, err := s.q.GetUserCredentials(ctx, int32(userID))
+	if err != nil {
+		return err
+	}
+	if err := checkCurrentPassword(user.PasswordHash, password); err != nil {
+		return err
+	}
+	if _, err := s.RevokeOtherSessions(ctx, userID, 0); err != nil {
+		return err
+	}
+	if _, err := s.q.DeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+	// The user is gone, so the event refers to it by ID only.
+	s.audit(ctx, AuditEvent{Event: AuditUserDeleted, Subject: fmt.Sprintf("user:%d", userID), IPAddress: ip})
+	return nil
+}
+
+// checkCurrentPassword confirms a sensitive change with the user's password.
+// Accounts without one sign in through an identity provider only and are
+// not asked.
+func checkCurrentPassword(hash sql.NullString, password string) error {
+	if !hash.Valid {
+		return nil
+	}
+	if auth.VerifyPassword(hash.String, password) != nil {
+		return ErrWrongPassword
+	}
+	return nil
+}
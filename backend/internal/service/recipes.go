@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
+)
+
+// ErrRecipeNotFound is returned when a recipe to change does not exist.
+var ErrRecipeNotFound = fmt.Errorf("recipe not found")
+
+// Recipe input limits.
+const (
+	MaxRecipeTitleLength = 200
+	MaxRecipeMinutes     = 7 * 24 * 60
+	MaxRecipeServings    = 100
+)
+
+// RecipeInput is the content of a recipe being created or replaced.
+// Ingredients and Steps are JSON arrays: ingredients of objects with a
+// "name" (or plain strings), steps of strings.
+type RecipeInput struct {
+	Title            string
+	Description      string
+	Cuisine          string
+	Difficulty       string
+	DietType         string
+	PrepTimeMinutes  int
+	CookTimeMinutes  int
+	TotalTimeMinutes int
+	Servings         int
+	Tags             []string
+	Ingredients      json.RawMessage
+	Steps            json.RawMessage
+	Nutrition        json.RawMessage
+}
+
+// normalize trims the text fields of in and lowercases the difficulty.
+func (in *RecipeInput) normalize() {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Cuisine = strings.TrimSpace(in.Cuisine)
+	in.Difficulty = strings.ToLower(strings.TrimSpace(in.Difficulty))
+	in.DietType = strings.TrimSpace(in.DietType)
+	tags := in.Tags[:0]
+	for _, t := range in.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	in.Tags = tags
+}
+
+// validate reports every invalid field of a normalized input.
+func (in RecipeInput) validate() error {
+	var errs validate.Errors
+	switch {
+	case in.Title == "":
+		errs.Add("title", "is required")
+	case len([]rune(in.Title)) > MaxRecipeTitleLength:
+		errs.Add("title", fmt.Sprintf("must be at most %d characters", MaxRecipeTitleLength))
+	}
+	switch in.Difficulty {
+	case "", "easy", "medium", "hard":
+	default:
+		errs.Add("difficulty", "must be easy, medium or hard")
+	}
+	for _, f := range []struct {
+		field string
+		value int
+	}{
+		{"prepTimeMinutes", in.PrepTimeMinutes},
+		{"cookTimeMinutes", in.CookTimeMinutes},
+		{"totalTimeMinutes", in.TotalTimeMinutes},
+	} {
+		if f.value < 0 || f.value > MaxRecipeMinutes {
+			errs.Add(f.field, fmt.Sprintf("must be between 0 and %d", MaxRecipeMinutes))
+		}
+	}
+	if in.Servings < 0 || in.Servings > MaxRecipeServings {
+		errs.Add("servings", fmt.Sprintf("must be between 0 and %d", MaxRecipeServings))
+	}
+	if !isJSONArray(in.Ingredients) {
+		errs.Add("ingredients", "must be a non-empty array")
+	}
+	if !isJSONArray(in.Steps) {
+		errs.Add("steps", "must be a non-empty array")
+	}
+	if len(in.Nutrition) > 0 && !json.Valid(in.Nutrition) {
+		errs.Add("nutrition", "must be valid JSON")
+	}
+	return errs.Err()
+}
+
+// isJSONArray reports whether raw is a JSON array with at least one element.
+func isJSONArray(raw json.RawMessage) bool {
+	var items []json.RawMessage
+	return json.Unmarshal(raw, &items) == nil && len(items) > 0
+}
+
+// CreateRecipe stores a new recipe authored by userID.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the author
+//   - in: recipe content
+//
+// Returns the new recipe's ID, or validate.Errors for invalid input.
+func (s *Service) CreateRecipe(ctx context.Context, userID int, in RecipeInput) (int, error) {
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return 0, err
+	}
+	id, err := s.q.CreateRecipe(ctx, db.CreateRecipeParams{
+		Title:            in.Title,
+		Description:      nullString(in.Description),
+		Cuisine:          nullString(in.Cuisine),
+		Difficulty:       nullString(in.Difficulty),
+		DietType:         nullString(in.DietType),
+		PrepTimeMinutes:  nullPositive(in.PrepTimeMinutes),
+		CookTimeMinutes:  nullPositive(in.CookTimeMinutes),
+		TotalTimeMinutes: nullPositive(in.TotalTimeMinutes),
+		Servings:         nullPositive(in.Servings),
+		Tags:             in.Tags,
+		Ingredients:      nullJSON(in.Ingredients),
+		Steps:            nullJSON(in.Steps),
+		Nutrition:        nullJSON(in.Nutrition),
+		AuthorID:         sql.NullInt32{Int32: int32(userID), Valid: true},
+	})
+	return int(id), err
+}
+
+// UpdateRecipe replaces a recipe's content. Authors may edit their own
+// recipes; editors and above may edit any recipe.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the user making the change
+//   - id: recipe identifier
+//   - in: new recipe content
+//
+// Returns ErrRecipeNotFound, ErrForbidden, or validate.Errors.
+func (s *Service) UpdateRecipe(ctx context.Context, actor Actor, id int, in RecipeInput) error {
+	own, err := s.authorizeRecipe(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	in.normalize()
+	if err := in.validate(); err != nil {
+		return err
+	}
+
+	n, err := s.q.UpdateRecipe(ctx, db.UpdateRecipeParams{
+		ID:               int32(id),
+		Title:            in.Title,
+		Description:      nullString(in.Description),
+		Cuisine:          nullString(in.Cuisine),
+		Difficulty:       nullString(in.Difficulty),
+		DietType:         nullString(in.DietType),
+		PrepTimeMinutes:  nullPositive(in.PrepTimeMinutes),
+		CookTimeMinutes:  nullPositive(in.CookTimeMinutes),
+		TotalTimeMinutes: nullPositive(in.TotalTimeMinutes),
+		Servings:         nullPositive(in.Servings),
+		Tags:             in.Tags,
+		Ingredients:      nullJSON(in.Ingredients),
+		Steps:            nullJSON(in.Steps),
+		Nutrition:        nullJSON(in.Nutrition),
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRecipeNotFound
+	}
+	if !own {
+		s.audit(ctx, AuditEvent{Event: AuditRecipeUpdated, UserID: actor.UserID, Details: map[string]any{"recipe_id": id}})
+	}
+	return nil
+}
+
+// DeleteRecipe removes a recipe with its ratings and favorites. Authors may
+// delete their own recipes; editors and above may delete any recipe.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the user making the change
+//   - id: recipe identifier
+//
+// Returns ErrRecipeNotFound or ErrForbidden.
+func (s *Service) DeleteRecipe(ctx context.Context, actor Actor, id int) error {
+	own, err := s.authorizeRecipe(ctx, actor, id)
+	if err != nil {
+		return err
+	}
+	n, err := s.q.DeleteRecipe(ctx, int32(id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRecipeNotFound
+	}
+	if !own {
+		s.audit(ctx, AuditEvent{Event: AuditRecipeDeleted, UserID: actor.UserID, Details: map[string]any{"recipe_id": id}})
+	}
+	return nil
+}
+
+// SetRecipeAuthor assigns a recipe to another user, or to nobody when
+// authorID is 0, e.g. to hand a seeded recipe to its maintainer.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the admin making the change
+//   - id: recipe identifier
+//   - authorID: ID of the new author (0 for none)
+//
+// Returns ErrRecipeNotFound, or validate.Errors if the author does not exist.
+func (s *Service) SetRecipeAuthor(ctx context.Context, actor Actor, id, authorID int) error {
+	if authorID != 0 {
+		if _, err := s.q.GetUserByID(ctx, int32(authorID)); errors.Is(err, sql.ErrNoRows) {
+			return validate.Errors{{Field: "authorId", Message: "is not a known user"}}
+		} else if err != nil {
+			return err
+		}
+	}
+	n, err := s.q.SetRecipeAuthor(ctx, db.SetRecipeAuthorParams{
+		ID:       int32(id),
+		AuthorID: sql.NullInt32{Int32: int32(authorID), Valid: authorID != 0},
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrRecipeNotFound
+	}
+	s.audit(ctx, AuditEvent{Event: AuditRecipeAuthor, UserID: actor.UserID, Details: map[string]any{"recipe_id": id, "author_id": authorID}})
+	return nil
+}
+
+// authorizeRecipe checks that actor may change recipe id and reports whether
+// they are its author.
+func (s *Service) authorizeRecipe(ctx context.Context, actor Actor, id int) (bool, error) {
+	author, err := s.q.GetRecipeAuthor(ctx, int32(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrRecipeNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	own := author.Valid && int(author.Int32) == actor.UserID
+	if !own && !actor.Role.AtLeast(auth.RoleEditor) {
+		return false, ErrForbidden
+	}
+	return own, nil
+}
+
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+func nullPositive(v int) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(v), Valid: v > 0}
+}
+
+func nullJSON(raw json.RawMessage) pqtype.NullRawMessage {
+	return pqtype.NullRawMessage{RawMessage: raw, Valid: len(raw) > 0}
+}
@@ -0,0 +1,166 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createTestRecipe stores a recipe by userID, deleted when the test ends.
+func createTestRecipe(t *testing.T, s *Service, userID int32, in RecipeInput) int {
+	t.Helper()
+	if in.Ingredients == nil {
+		in.Ingredients = json.RawMessage(`["water"]`)
+	}
+	if in.Steps == nil {
+		in.Steps = json.RawMessage(`["boil"]`)
+	}
+	id, err := s.CreateRecipe(context.Background(), int(userID), in)
+	if err != nil {
+		t.Fatalf("creating recipe: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := s.q.DeleteRecipe(context.Background(), int32(id)); err != nil {
+			t.Errorf("deleting recipe %d: %v", id, err)
+		}
+	})
+	return id
+}
+
+// uniqueWord returns a word no other recipe contains.
+func uniqueWord() string {
+	return fmt.Sprintf("zq%dx", time.Now().UnixNano()%1e9)
+}
+
+// ids returns the IDs of the recipes on a page.
+func ids(items []RecipeSearchResult) []int {
+	out := make([]int, len(items))
+	for i, r := range items {
+		out[i] = int(r.ID)
+	}
+	return out
+}
+
+func TestSearchAndFilterRecipesWithoutFilters(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	createTestRecipe(t, s, user.ID, RecipeInput{Title: "Plain soup"})
+
+	page, err := s.SearchAndFilterRecipes(context.Background(), "", FilterSpec{}, "", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) == 0 || page.Total == 0 {
+		t.Errorf("listing without filters returned %d of %d recipes, want some", len(page.Items), page.Total)
+	}
+}
+
+func TestSearchAndFilterRecipesPages(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	word := uniqueWord()
+	first := createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " stew"})
+	second := createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " soup"})
+	ctx := context.Background()
+
+	var got []int
+	after := ""
+	for range 3 {
+		page, err := s.SearchAndFilterRecipes(ctx, word, FilterSpec{}, SortNewest, 1, after)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 2 {
+			t.Errorf("total %d, want 2", page.Total)
+		}
+		got = append(got, ids(page.Items)...)
+		if after = page.NextCursor; after == "" {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != second || got[1] != first {
+		t.Errorf("pages listed %v, want [%d %d]", got, second, first)
+	}
+}
+
+func TestSearchAndFilterRecipesDietImplies(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	word := uniqueWord()
+	vegan := createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " salad", DietType: "Vegan"})
+	createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " roast", DietType: "omnivore"})
+
+	page, err := s.SearchAndFilterRecipes(context.Background(), word, FilterSpec{Diets: []string{"vegetarian"}}, "", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(page.Items); len(got) != 1 || got[0] != vegan {
+		t.Errorf("vegetarian recipes %v, want [%d]", got, vegan)
+	}
+}
+
+func TestMatchWithFiltersScoresInDatabase(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	word := uniqueWord()
+	both := createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " soup", Tags: []string{word, word + "-b"}})
+	one := createTestRecipe(t, s, user.ID, RecipeInput{Title: "Other soup", Tags: []string{word + "-b"}})
+
+	page, err := s.MatchWithFilters(context.Background(), []string{word, word + "-b", " " + word}, MatchFilters{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 {
+		t.Fatalf("got %d matches, want 2", len(page.Items))
+	}
+	if page.Items[0].ID != int32(both) || page.Items[1].ID != int32(one) {
+		t.Errorf("matches ordered %d, %d; want %d, %d", page.Items[0].ID, page.Items[1].ID, both, one)
+	}
+	if page.Items[0].Score <= page.Items[1].Score {
+		t.Errorf("scores %d and %d, want the first higher", page.Items[0].Score, page.Items[1].Score)
+	}
+}
+
+func TestRecipeFacets(t *testing.T) {
+	s := testService(t)
+	user := createTestUser(t, s)
+	word := uniqueWord()
+	createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " curry", Cuisine: "Thai", DietType: "vegan", CookTimeMinutes: 20, Tags: []string{"Spicy"}})
+	createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " pasta", Cuisine: "Italian", DietType: "vegetarian", CookTimeMinutes: 10, Tags: []string{"spicy", "keto"}})
+	createTestRecipe(t, s, user.ID, RecipeInput{Title: word + " risotto", Cuisine: "italian", PrepTimeMinutes: 5, CookTimeMinutes: 40})
+
+	facets, err := s.RecipeFacets(context.Background(), word, FilterSpec{Cuisines: []string{"Italian"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := func(dim string) map[string]int {
+		out := map[string]int{}
+		for _, v := range facets[dim] {
+			out[strings.ToLower(v.Value)] = v.Count
+		}
+		return out
+	}
+
+	// The cuisine facet ignores the cuisine filter; the others apply it.
+	if got, want := counts(FacetCuisine), map[string]int{"thai": 1, "italian": 2}; !maps.Equal(got, want) {
+		t.Errorf("cuisine counts %v, want %v", got, want)
+	}
+	if got, want := counts(FacetDietType), map[string]int{"vegetarian": 1, "pescatarian": 1, "keto": 1, "low-carb": 1}; !maps.Equal(got, want) {
+		t.Errorf("diet counts %v, want %v", got, want)
+	}
+	if got, want := counts(FacetTag), map[string]int{"spicy": 1, "keto": 1}; !maps.Equal(got, want) {
+		t.Errorf("tag counts %v, want %v", got, want)
+	}
+	if got, want := counts(FacetCookTime), map[string]int{"<15": 1, "15-30": 0, "30-60": 1, "60+": 0}; !maps.Equal(got, want) {
+		t.Errorf("cook time counts %v, want %v", got, want)
+	}
+	if got, want := counts(FacetTotalTime), map[string]int{"<15": 1, "15-30": 0, "30-60": 1, "60+": 0}; !maps.Equal(got, want) {
+		t.Errorf("total time counts %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// ErrReviewNotFound is returned when a review (rating) does not exist.
+var ErrReviewNotFound = fmt.Errorf("review not found")
+
+// ListReviews returns a page of recipe reviews (ratings), newest first, for
+// moderation.
+//
+// Parameters:
+//   - ctx: request context
+//   - limit: reviews per page
+//   - after: cursor from the previous page ("" for the first page)
+//
+// Returns the page or ErrInvalidCursor.
+func (s *Service) ListReviews(ctx context.Context, limit int, after string) (Page[db.ListRatingsPageRow], error) {
+	c, err := decodeCursor(after, "reviews")
+	if err != nil {
+		return Page[db.ListRatingsPageRow]{}, err
+	}
+	params := db.ListRatingsPageParams{Limit: int32(limit + 1)}
+	if c != nil {
+		params.BeforeID = c.ID
+	}
+
+	rows, err := s.q.ListRatingsPage(ctx, params)
+	if err != nil {
+		return Page[db.ListRatingsPageRow]{}, err
+	}
+
+	page := Page[db.ListRatingsPageRow]{Items: rows}
+	if len(rows) > 0 {
+		page.Total = int(rows[0].TotalCount)
+	}
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		if limit > 0 {
+			page.NextCursor = encodeCursor(cursor{ID: rows[limit-1].ID, Order: "reviews"})
+		}
+	}
+	if page.Items == nil {
+		page.Items = []db.ListRatingsPageRow{}
+	}
+	return page, nil
+}
+
+// DeleteReview removes a review (rating). The removal is written to the
+// audit log.
+//
+// Parameters:
+//   - ctx: request context
+//   - actor: the moderator removing the review
+//   - id: review identifier
+//
+// Returns ErrReviewNotFound if there is no such review.
+func (s *Service) DeleteReview(ctx context.Context, actor Actor, id int) error {
+	n, err := s.q.DeleteRating(ctx, int32(id))
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrReviewNotFound
+	}
+	s.audit(ctx, AuditEvent{Event: AuditReviewDeleted, UserID: actor.UserID, Details: map[string]any{"review_id": id}})
+	return nil
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// RecipeSearchResult is a recipe returned by SearchAndFilterRecipes.
+// Rank and Snippet are only set when the search used a full-text query.
+type RecipeSearchResult struct {
+	db.SearchRecipesRow
+	Rank    float64 `json:"rank,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// BuildTSQuery converts free-form user input into a Postgres to_tsquery
+// expression.
+//
+// Supported syntax:
+//   - multiple words are ANDed: `tomato pasta`
+//   - "quoted phrases" must appear in order: `"green curry"`
+//   - a trailing * matches prefixes: `tom*`
+//   - a leading - excludes a term or phrase: `curry -chicken -"fish sauce"`
+//   - OR between terms matches either: `tacos OR burrito`
+//
+// The last bare word is always prefix-matched so partial input still finds
+// results while the user is typing. Everything except letters and digits is
+// stripped from terms, so the result is always safe to pass to to_tsquery.
+//
+// Returns an empty string when the input contains no searchable terms.
+func BuildTSQuery(input string) string {
+	type term struct {
+		expr   string
+		negate bool
+		prefix bool
+		phrase bool
+	}
+
+	var terms []term
+	var ops []string
+	pendingOr := false
+
+	add := func(t term) {
+		if t.expr == "" {
+			return
+		}
+		if len(terms) > 0 {
+			if pendingOr {
+				ops = append(ops, " | ")
+			} else {
+				ops = append(ops, " & ")
+			}
+		}
+		pendingOr = false
+		terms = append(terms, t)
+	}
+
+	rest := strings.TrimSpace(input)
+	for rest != "" {
+		negate := false
+		if len(rest) > 1 && (rest[0] == '-' || rest[0] == '!') && rest[1] == '"' {
+			negate, rest = true, rest[1:]
+		}
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			var phrase string
+			if end < 0 {
+				phrase, rest = rest[1:], ""
+			} else {
+				phrase, rest = rest[1:end+1], rest[end+2:]
+			}
+			add(term{expr: strings.Join(lexemes(phrase), " <-> "), phrase: true, negate: negate})
+			rest = strings.TrimSpace(rest)
+			continue
+		}
+
+		word := rest
+		if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+			word, rest = rest[:i], strings.TrimSpace(rest[i:])
+		} else {
+			rest = ""
+		}
+
+		if word == "OR" || word == "|" {
+			if len(terms) > 0 {
+				pendingOr = true
+			}
+			continue
+		}
+
+		t := term{}
+		if strings.HasPrefix(word, "-") || strings.HasPrefix(word, "!") {
+			t.negate = true
+			word = word[1:]
+		}
+		if strings.HasSuffix(word, "*") {
+			t.prefix = true
+			word = strings.TrimRight(word, "*")
+		}
+		parts := lexemes(word)
+		if len(parts) > 1 {
+			t.phrase = true
+		}
+		t.expr = strings.Join(parts, " <-> ")
+		add(t)
+	}
+
+	if len(terms) == 0 {
+		return ""
+	}
+
+	last := &terms[len(terms)-1]
+	if !last.negate && !last.phrase {
+		last.prefix = true
+	}
+
+	var b strings.Builder
+	for i, t := range terms {
+		if i > 0 {
+			b.WriteString(ops[i-1])
+		}
+		expr := t.expr
+		if t.prefix {
+			expr += ":*"
+		}
+		if t.phrase {
+			expr = "(" + expr + ")"
+		}
+		if t.negate {
+			expr = "!" + expr
+		}
+		b.WriteString(expr)
+	}
+	return b.String()
+}
+
+// lexemes splits s into lower-cased runs of letters and digits.
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func filterRowToResult(row db.FilterRecipesRow) RecipeSearchResult {
+	return RecipeSearchResult{
+		SearchRecipesRow: db.SearchRecipesRow{
+			ID:               row.ID,
+			Title:            row.Title,
+			Description:      row.Description,
+			Cuisine:          row.Cuisine,
+			Difficulty:       row.Difficulty,
+			DietType:         row.DietType,
+			PrepTimeMinutes:  row.PrepTimeMinutes,
+			CookTimeMinutes:  row.CookTimeMinutes,
+			TotalTimeMinutes: row.TotalTimeMinutes,
+			Servings:         row.Servings,
+			Ingredients:      row.Ingredients,
+			Steps:            row.Steps,
+			Nutrition:        row.Nutrition,
+			Tags:             row.Tags,
+			AverageRating:    row.AverageRating,
+		},
+		Rank:    row.Rank,
+		Snippet: row.Snippet,
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"words", "tomato pasta", "tomato & pasta:*"},
+		{"case and spacing", "  Tomato\tPASTA  ", "tomato & pasta:*"},
+		{"phrase", `"green curry"`, "(green <-> curry)"},
+		{"phrase then word", `"green curry" rice`, "(green <-> curry) & rice:*"},
+		{"unterminated phrase", `soup "green curry`, "soup & (green <-> curry)"},
+		{"prefix", "tom*", "tom:*"},
+		{"prefix mid-query", "tom* sauce", "tom:* & sauce:*"},
+		{"repeated stars", "tom** sauce", "tom:* & sauce:*"},
+		{"negation", "curry -chicken", "curry & !chicken"},
+		{"bang negation", "curry !chicken", "curry & !chicken"},
+		{"negated phrase", `curry -"fish sauce"`, "curry & !(fish <-> sauce)"},
+		{"or", "tacos OR burrito", "tacos | burrito:*"},
+		{"pipe", "tacos | burrito", "tacos | burrito:*"},
+		{"lowercase or is a word", "tacos or burrito", "tacos & or & burrito:*"},
+		{"leading or", "OR tacos", "tacos:*"},
+		{"trailing or", "tacos OR", "tacos:*"},
+		{"word with punctuation", "it's", "(it <-> s)"},
+		{"prefixed word with punctuation", "stir-fr*", "(stir <-> fr:*)"},
+		{"operators in a word", "a&b|c", "(a <-> b <-> c)"},
+		{"stray punctuation", `pasta ) ( : & ' \`, "pasta:*"},
+		{"letters and digits", "crème brûlée 7up", "crème & brûlée & 7up:*"},
+		{"empty", "", ""},
+		{"blank", " \t\n ", ""},
+		{"only punctuation", `"!&|:*()'`, ""},
+		{"only operators", "- ! * OR |", ""},
+		{"empty phrase", `""`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildTSQuery(tt.input); got != tt.want {
+				t.Errorf("BuildTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// tsQuery matches the expressions BuildTSQuery may produce: lexemes of
+// letters and digits, optionally negated or prefix-matched, grouped into
+// phrases and joined by & or |.
+var tsQuery = func() *regexp.Regexp {
+	const lexeme = `[\p{L}\p{Nd}]+`
+	const item = `!?(?:` + lexeme + `(?::\*)?|\(` + lexeme + `(?: <-> ` + lexeme + `)*(?::\*)?\))`
+	return regexp.MustCompile(`^(?:` + item + `(?: [&|] ` + item + `)*)?$`)
+}()
+
+// FuzzBuildTSQuery checks the promise that any input gives an expression
+// to_tsquery accepts.
+func FuzzBuildTSQuery(f *testing.F) {
+	for _, s := range []string{"tomato pasta", `-"fish sauce" OR tom*`, `"a`, "-", "OR |", "a&b:*(c)", "\xff\x00é"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		if got := BuildTSQuery(input); !tsQuery.MatchString(got) {
+			t.Errorf("BuildTSQuery(%q) = %q, not a valid query", input, got)
+		}
+	})
+}
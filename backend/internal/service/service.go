@@ -4,15 +4,22 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
-	"math"
+	"log/slog"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
 	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/lockout"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/tracing"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/validate"
 )
 
 // Service provides business logic operations for the recipe application.
@@ -20,6 +27,23 @@ import (
 // filtering, and recommendations.
 type Service struct {
 	q *db.Queries
+
+	// Tokens configures access and refresh tokens issued by sessions.
+	Tokens TokenSettings
+	// Mail configures verification and password reset emails.
+	Mail MailSettings
+	// Lockout throttles failed logins; nil disables it.
+	Lockout *lockout.Guard
+	// OIDC configures sign-in with external identity providers.
+	OIDC OIDCSettings
+	// Metrics records match and sign-in outcomes; nil disables them.
+	Metrics *metrics.Metrics
+	// ViewerKey keys the HMAC anonymous viewers' IP addresses are stored
+	// as. NewService sets a random one, which only lasts the process.
+	ViewerKey []byte
+
+	// background tracks work that outlives its request (see Wait).
+	background sync.WaitGroup
 }
 
 // NewService creates a new Service instance with the provided database connection.
@@ -29,7 +53,9 @@ type Service struct {
 //
 // Returns a Service ready to perform business operations.
 func NewService(conn db.DBTX) *Service {
-	return &Service{q: db.New(conn)}
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return &Service{q: db.New(conn), ViewerKey: key}
 }
 
 // RecipeSummary represents a recipe with its match score.
@@ -78,7 +104,13 @@ func (s *Service) GetRecipe(ctx context.Context, id int) (db.GetRecipeByIDRow, e
 //   - offset: pagination offset
 //
 // Returns scored recipe summaries sorted by relevance.
-func (s *Service) MatchRecipes(ctx context.Context, detected []string, limit, offset int) ([]RecipeSummary, error) {
+func (s *Service) MatchRecipes(ctx context.Context, detected []string, limit, offset int) (_ []RecipeSummary, err error) {
+	ctx, span := tracing.Start(ctx, "service.MatchRecipes", tracing.WithAttributes(slog.Int("match.ingredients", len(detected))))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	list, err := s.ListRecipes(ctx, limit, offset)
 	if err != nil {
 		return nil, err
@@ -116,103 +148,153 @@ func (s *Service) MatchRecipes(ctx context.Context, detected []string, limit, of
 	return results, nil
 }
 
-// SearchAndFilterRecipes searches recipes and applies multiple optional filters.
+// SearchAndFilterRecipes searches recipes, applies multiple optional filters
+// and returns one keyset-paginated page.
 //
 // Filter behavior:
-// - query: searches in recipe title and tags (empty = all recipes)
-// - diet: matches recipe tags (e.g., "vegetarian", "vegan")
-// - difficulty: exact match on difficulty level ("easy", "medium", "hard")
-// - maxTimeMinutes: filters recipes by cooking time
-// - cuisine: exact match on cuisine type
+// - query: ranked full-text search over title, tags, description, ingredients and steps (empty = all recipes)
+// - filters: diet, difficulty, cuisine, tag, time and servings filters (see FilterSpec)
+// - sort: "rating", "newest", "quickest" or "popular" (empty = by ID, or by relevance for full-text queries)
+//
+// Searching, filtering and paging all happen in the database, so every
+// matching recipe is reachable regardless of how many there are.
 //
 // Parameters:
 //   - ctx: request context
-//   - query: search query for title/tags
-//   - diet: dietary restriction filter
-//   - difficulty: difficulty level filter
-//   - maxTimeMinutes: maximum cooking time in minutes (nil = no limit)
-//   - cuisine: cuisine type filter
+//   - query: full-text search query
+//   - filters: optional filters to narrow results
+//   - sort: result ordering (see ParseSort)
 //   - limit: maximum results to return
-//   - offset: pagination offset
+//   - after: cursor returned with the previous page (empty = first page)
 //
-// Returns filtered and paginated recipe list.
+// Returns a page of recipes and the total number of matches. Full-text
+// matches carry a relevance rank and a highlighted snippet. Returns
+// ErrInvalidCursor if after was not issued for this query and sort.
 func (s *Service) SearchAndFilterRecipes(
 	ctx context.Context,
 	query string,
-	diet string,
-	difficulty string,
-	maxTimeMinutes *int,
-	cuisine string,
+	filters FilterSpec,
+	sort string,
 	limit int,
-	offset int,
-) ([]db.SearchRecipesRow, error) {
-	fetchLimit := int32(math.Max(float64(limit+offset), 200))
-	if fetchLimit > 2000 {
-		fetchLimit = 2000
+	after string,
+) (Page[RecipeSearchResult], error) {
+	tsq := BuildTSQuery(query)
+	order := "search:" + sort + ":" + tsq
+	c, err := decodeCursor(after, order)
+	if err != nil {
+		return Page[RecipeSearchResult]{}, err
 	}
 
-	params := db.SearchRecipesParams{Column1: sql.NullString{String: query, Valid: true}, Limit: fetchLimit, Offset: 0}
-	all, err := s.q.SearchRecipes(ctx, params)
+	// Fetch one extra row to learn whether another page follows.
+	rows, err := s.filterRecipes(ctx, query, tsq, filters, sort, scoring{}, c, int32(limit+1))
 	if err != nil {
-		return nil, err
+		return Page[RecipeSearchResult]{}, err
 	}
 
-	dietLower := strings.ToLower(strings.TrimSpace(diet))
-	diffLower := strings.ToLower(strings.TrimSpace(difficulty))
-	cuisineLower := strings.ToLower(strings.TrimSpace(cuisine))
-
-	var filtered []db.SearchRecipesRow
-	for _, r := range all {
-		if diffLower != "" {
-			if !r.Difficulty.Valid || strings.ToLower(r.Difficulty.String) != diffLower {
-				continue
-			}
-		}
-		if cuisineLower != "" {
-			if !r.Cuisine.Valid || strings.ToLower(r.Cuisine.String) != cuisineLower {
-				continue
-			}
-		}
-		if maxTimeMinutes != nil {
-			if !r.CookTimeMinutes.Valid || int(r.CookTimeMinutes.Int32) > *maxTimeMinutes {
-				continue
-			}
-		}
-		if dietLower != "" {
-			matched := false
-			for _, t := range r.Tags {
-				if strings.ToLower(t) == dietLower {
-					matched = true
-					break
-				}
-			}
-			if !matched {
-				continue
-			}
+	page := Page[RecipeSearchResult]{Items: []RecipeSearchResult{}}
+	if len(rows) > 0 {
+		page.Total = int(rows[0].TotalCount)
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		if limit > 0 {
+			last := rows[limit-1]
+			page.NextCursor = encodeCursor(cursor{Key: last.SortKey, ID: last.ID, Order: order})
 		}
-		filtered = append(filtered, r)
 	}
+	for _, row := range rows {
+		page.Items = append(page.Items, filterRowToResult(row))
+	}
+	return page, nil
+}
+
+// scoring holds the inputs of the scored sorts of FilterRecipes: the terms
+// of sortMatch, the tag weights of sortSuggestions, and the lowest score
+// listed.
+type scoring struct {
+	terms    []string
+	tags     []string
+	weights  []int32
+	minScore sql.NullFloat64
+}
+
+// Sorts of FilterRecipes that score recipes for a caller rather than
+// ordering them by a column; they are not accepted from clients.
+const (
+	sortMatch       = "match"
+	sortSuggestions = "suggestions"
+)
+
+// filterRecipes runs the FilterRecipes query, starting after c when set.
+func (s *Service) filterRecipes(ctx context.Context, query, tsq string, filters FilterSpec, sort string, sc scoring, c *cursor, limit int32) ([]db.FilterRecipesRow, error) {
+	params := db.FilterRecipesParams{
+		Sort:         sort,
+		MatchTerms:   sc.terms,
+		ScoreTags:    sc.tags,
+		ScoreWeights: sc.weights,
+		MinScore:     sc.minScore,
+		Tsquery:      tsq,
+		Query:        query,
+		Diets:        acceptedDiets(filters.Diets),
+		Difficulties: lowerAll(filters.Difficulties),
+		Cuisines:     lowerAll(filters.Cuisines),
+		Tags:         lowerAll(filters.Tags),
+		MaxTotalTime: nullInt32(filters.MaxTotalTime),
+		MaxPrepTime:  nullInt32(filters.MaxPrepTime),
+		MaxCookTime:  nullInt32(filters.MaxCookTime),
+		MinServings:  nullInt32(filters.MinServings),
+		MaxServings:  nullInt32(filters.MaxServings),
+		Limit:        limit,
+	}
+	if c != nil {
+		params.CursorKey = sql.NullFloat64{Float64: c.Key, Valid: true}
+		params.CursorID = c.ID
+	}
+	return s.q.FilterRecipes(ctx, params)
+}
 
-	if offset >= len(filtered) {
-		return []db.SearchRecipesRow{}, nil
+// scoredPage returns the page following after of the recipes passing
+// filters, by descending score under a scored sort, then ascending ID.
+// Recipes are scored and paged in the database as for
+// SearchAndFilterRecipes, so only one page is read however many match.
+func (s *Service) scoredPage(ctx context.Context, sort string, sc scoring, filters FilterSpec, limit int, after string) (Page[RecipeWithScore], error) {
+	c, err := decodeCursor(after, sort)
+	if err != nil {
+		return Page[RecipeWithScore]{}, err
 	}
 
-	end := offset + limit
-	if end > len(filtered) {
-		end = len(filtered)
+	// Fetch one extra row to learn whether another page follows.
+	rows, err := s.filterRecipes(ctx, "", "", filters, sort, sc, c, int32(limit+1))
+	if err != nil {
+		return Page[RecipeWithScore]{}, err
 	}
 
-	return filtered[offset:end], nil
+	page := Page[RecipeWithScore]{Items: []RecipeWithScore{}}
+	if len(rows) > 0 {
+		page.Total = int(rows[0].TotalCount)
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		if limit > 0 {
+			last := rows[limit-1]
+			page.NextCursor = encodeCursor(cursor{Key: last.SortKey, ID: last.ID, Order: sort})
+		}
+	}
+	for _, row := range rows {
+		page.Items = append(page.Items, RecipeWithScore{
+			SearchRecipesRow: filterRowToResult(row).SearchRecipesRow,
+			Score:            int(row.SortKey),
+		})
+	}
+	return page, nil
 }
 
 // MatchFilters defines optional filters for ingredient-based recipe matching.
 type MatchFilters struct {
-	Diet           string
-	Difficulty     string
-	MaxTimeMinutes *int
-	Cuisine        string
-	Limit          int
-	Offset         int
+	FilterSpec
+	Limit int
+	// After is the cursor returned with the previous page.
+	After string
 }
 
 // RecipeWithScore extends a recipe search result with a relevance score.
@@ -225,52 +307,46 @@ type RecipeWithScore struct {
 // MatchWithFilters combines filtering and ingredient-based scoring.
 //
 // Process:
-// 1. Apply all filters (diet, difficulty, time, cuisine)
-// 2. Score remaining recipes by ingredient overlap
-// 3. Sort by descending score
+//  1. Apply all filters (see FilterSpec)
+//  2. Score remaining recipes by ingredient overlap: one point per tag
+//     naming an ingredient and per ingredient found in the title
+//  3. Sort by descending score, then ascending ID
+//  4. Return the page following filters.After
+//
+// Scoring and paging happen in the database, so pages are consistent,
+// Total counts all matches and only one page of recipes is read.
 //
 // Parameters:
 //   - ctx: request context
 //   - ingredients: list of ingredient names to match
-//   - filters: optional filters to narrow results
+//   - filters: optional filters to narrow results, page size and cursor
 //
-// Returns scored and sorted recipes matching all criteria.
-func (s *Service) MatchWithFilters(ctx context.Context, ingredients []string, filters MatchFilters) ([]RecipeWithScore, error) {
-	candidates, err := s.SearchAndFilterRecipes(ctx, "", filters.Diet, filters.Difficulty, filters.MaxTimeMinutes, filters.Cuisine, filters.Limit, filters.Offset)
-	if err != nil {
-		return nil, err
-	}
-	detectedSet := map[string]struct{}{}
-	for _, d := range ingredients {
-		detectedSet[strings.ToLower(strings.TrimSpace(d))] = struct{}{}
-	}
-
-	var results []RecipeWithScore
-	for _, r := range candidates {
-		score := 0
-		for _, t := range r.Tags {
-			if _, ok := detectedSet[strings.ToLower(t)]; ok {
-				score++
-			}
-		}
+// Returns a page of scored recipes matching all criteria.
+func (s *Service) MatchWithFilters(ctx context.Context, ingredients []string, filters MatchFilters) (_ Page[RecipeWithScore], err error) {
+	ctx, span := tracing.Start(ctx, "service.MatchWithFilters", tracing.WithAttributes(slog.Int("match.ingredients", len(ingredients))))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 
-		titleLower := strings.ToLower(r.Title)
-		for d := range detectedSet {
-			if strings.Contains(titleLower, d) {
-				score++
-			}
+	var terms []string
+	for _, d := range ingredients {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" && !slices.Contains(terms, d) {
+			terms = append(terms, d)
 		}
-
-		results = append(results, RecipeWithScore{SearchRecipesRow: r, Score: score})
 	}
 
-	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
-	return results, nil
+	page, err := s.scoredPage(ctx, sortMatch, scoring{terms: terms}, filters.FilterSpec, filters.Limit, filters.After)
+	if err == nil {
+		s.Metrics.ObserveMatch(len(page.Items))
+	}
+	return page, err
 }
 
 // CreateUser registers a new user with hashed password.
 //
 // Security:
+// - Username, email and password are validated before anything is stored
 // - Password is hashed using bcrypt before storage
 // - Original password is never stored
 //
@@ -280,8 +356,20 @@ func (s *Service) MatchWithFilters(ctx context.Context, ingredients []string, fi
 //   - email: user's email address
 //   - password: plain text password (will be hashed)
 //
-// Returns created user data or error if registration fails.
+// Returns created user data, validate.Errors for invalid input, a
+// *ConflictError if the username or email is taken, or another error if
+// registration fails.
 func (s *Service) CreateUser(ctx context.Context, username, email, password string) (db.CreateUserRow, error) {
+	username, email = strings.TrimSpace(username), strings.TrimSpace(email)
+
+	var errs validate.Errors
+	errs.Check("username", validate.Username(username))
+	errs.Check("email", validate.Email(email))
+	errs.Check("password", validate.Password(password))
+	if err := errs.Err(); err != nil {
+		return db.CreateUserRow{}, err
+	}
+
 	hash, err := auth.HashPassword(password)
 	if err != nil {
 		return db.CreateUserRow{}, err
@@ -292,7 +380,8 @@ func (s *Service) CreateUser(ctx context.Context, username, email, password stri
 		Email:        sql.NullString{String: email, Valid: true},
 		PasswordHash: sql.NullString{String: hash, Valid: true},
 	}
-	return s.q.CreateUser(ctx, params)
+	user, err := s.q.CreateUser(ctx, params)
+	return user, conflictFrom(err)
 }
 
 // Authenticate verifies user credentials for login.
@@ -306,15 +395,15 @@ func (s *Service) CreateUser(ctx context.Context, username, email, password stri
 //   - email: user's email address
 //   - password: plain text password to verify
 //
-// Returns user data on success, error on authentication failure.
+// Returns user data on success, ErrAuthFailed on authentication failure.
 func (s *Service) Authenticate(ctx context.Context, email, password string) (db.GetUserByEmailRow, error) {
 	row, err := s.q.GetUserByEmail(ctx, sql.NullString{String: email, Valid: true})
 	if err != nil {
-		return db.GetUserByEmailRow{}, fmt.Errorf("auth failed")
+		return db.GetUserByEmailRow{}, ErrAuthFailed
 	}
 
 	if err := auth.VerifyPassword(row.PasswordHash.String, password); err != nil {
-		return db.GetUserByEmailRow{}, fmt.Errorf("auth failed")
+		return db.GetUserByEmailRow{}, ErrAuthFailed
 	}
 
 	return row, nil
@@ -360,6 +449,49 @@ func (s *Service) RemoveFavorite(ctx context.Context, userID int, recipeID int)
 	return s.q.RemoveFavorite(ctx, params)
 }
 
+// ListFavoritesPage retrieves one page of a user's favorites, newest first.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - limit: maximum favorites to return
+//   - after: cursor returned with the previous page (empty = first page)
+//
+// Returns a page of favorited recipes and the user's total favorite count.
+func (s *Service) ListFavoritesPage(ctx context.Context, userID int, limit int, after string) (Page[db.ListFavoritesByUserPageRow], error) {
+	c, err := decodeCursor(after, "favorites")
+	if err != nil {
+		return Page[db.ListFavoritesByUserPageRow]{}, err
+	}
+	params := db.ListFavoritesByUserPageParams{
+		UserID: sql.NullInt32{Int32: int32(userID), Valid: true},
+		Limit:  int32(limit + 1),
+	}
+	if c != nil {
+		params.CursorID = c.ID
+	}
+
+	rows, err := s.q.ListFavoritesByUserPage(ctx, params)
+	if err != nil {
+		return Page[db.ListFavoritesByUserPageRow]{}, err
+	}
+
+	page := Page[db.ListFavoritesByUserPageRow]{Items: rows}
+	if len(rows) > 0 {
+		page.Total = int(rows[0].TotalCount)
+	}
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		if limit > 0 {
+			page.NextCursor = encodeCursor(cursor{ID: rows[limit-1].FavoriteID, Order: "favorites"})
+		}
+	}
+	if page.Items == nil {
+		page.Items = []db.ListFavoritesByUserPageRow{}
+	}
+	return page, nil
+}
+
 // ListFavorites retrieves all recipes favorited by a user.
 //
 // Parameters:
@@ -401,48 +533,48 @@ func (s *Service) IsFavorite(ctx context.Context, userID int, recipeID int) (boo
 // Parameters:
 //   - ctx: request context
 //   - userID: ID of the user to generate suggestions for
+//   - filters: optional filters applied to candidates (see FilterSpec)
 //   - limit: maximum number of suggestions to return
+//   - after: cursor returned with the previous page (empty = first page)
 //
-// Returns scored recipe suggestions or error.
-func (s *Service) GetSuggestions(ctx context.Context, userID int, limit int) ([]RecipeWithScore, error) {
+// Returns a page of scored recipe suggestions or error.
+func (s *Service) GetSuggestions(ctx context.Context, userID int, filters FilterSpec, limit int, after string) (Page[RecipeWithScore], error) {
+	if _, err := decodeCursor(after, sortSuggestions); err != nil {
+		return Page[RecipeWithScore]{}, err
+	}
+
 	favs, err := s.ListFavorites(ctx, userID)
 	if err != nil {
-		return nil, err
+		return Page[RecipeWithScore]{}, err
+	}
+
+	counts := s.favoriteTagCounts(ctx, favs)
+	if len(counts) == 0 {
+		return Page[RecipeWithScore]{Items: []RecipeWithScore{}}, nil
+	}
+	sc := scoring{minScore: sql.NullFloat64{Float64: 1, Valid: true}}
+	for tag, n := range counts {
+		sc.tags = append(sc.tags, tag)
+		sc.weights = append(sc.weights, int32(n))
 	}
+	return s.scoredPage(ctx, sortSuggestions, sc, filters, limit, after)
+}
 
-	favoriteTagCounts := map[string]int{}
+// favoriteTagCounts counts how often each tag (lowercased) appears among
+// the recipes of a user's favorites. Recipes that cannot be loaded are
+// skipped.
+func (s *Service) favoriteTagCounts(ctx context.Context, favs []db.ListFavoritesByUserRow) map[string]int {
+	counts := map[string]int{}
 	for _, f := range favs {
 		full, err := s.q.GetRecipeByID(ctx, f.RecipeID.Int32)
 		if err != nil {
 			continue
 		}
 		for _, t := range full.Tags {
-			favoriteTagCounts[strings.ToLower(t)]++
+			counts[strings.ToLower(t)]++
 		}
 	}
-
-	candidates, err := s.SearchAndFilterRecipes(ctx, "", "", "", nil, "", int(math.Max(float64(limit*5), 100)), 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var scored []RecipeWithScore
-	for _, c := range candidates {
-		score := 0
-		for _, t := range c.Tags {
-			score += favoriteTagCounts[strings.ToLower(t)]
-		}
-		if score > 0 {
-			scored = append(scored, RecipeWithScore{SearchRecipesRow: c, Score: score})
-		}
-	}
-
-	sort.Slice(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
-	if len(scored) > limit {
-		scored = scored[:limit]
-	}
-
-	return scored, nil
+	return counts
 }
 
 // ErrBadRequest is a sentinel error for invalid requests.
@@ -0,0 +1,63 @@
+//go:build integration
+
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+	"github.com/varnit-ta/smart-recipe-generator/backend/migrations"
+)
+
+// testPassword satisfies validate.Password.
+const testPassword = "correct-horse-1"
+
+// testUsers numbers the users created by a test run.
+var testUsers atomic.Int64
+
+// testService returns a Service on the database at TEST_DATABASE_URL, which
+// must be migrated to the latest version (make migrateup). Tests using it
+// are skipped when the variable is unset.
+func testService(t *testing.T) *Service {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	conn, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatalf("opening test database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var version uint
+	var dirty bool
+	err = conn.QueryRow("SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+	if err != nil || dirty || version != migrations.Latest() {
+		t.Fatalf("test database is not migrated to version %d (version %d, dirty %t, error %v)", migrations.Latest(), version, dirty, err)
+	}
+	return NewService(conn)
+}
+
+// createTestUser registers a user with a unique name and address, deleted
+// with everything it owns when the test ends.
+func createTestUser(t *testing.T, s *Service) db.CreateUserRow {
+	t.Helper()
+	name := fmt.Sprintf("t%d-%d", time.Now().UnixNano()%1e9, testUsers.Add(1))
+	user, err := s.CreateUser(context.Background(), name, name+"@example.com", testPassword)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := s.q.DeleteUser(context.Background(), user.ID); err != nil {
+			t.Errorf("deleting user %d: %v", user.ID, err)
+		}
+	})
+	return user
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown,
+// expired, revoked or has already been rotated.
+var ErrInvalidRefreshToken = fmt.Errorf("invalid refresh token")
+
+// ErrSessionNotFound is returned when a session does not exist, belongs to
+// another user or is already revoked.
+var ErrSessionNotFound = fmt.Errorf("session not found")
+
+// TokenSettings configures how sessions issue tokens.
+type TokenSettings struct {
+	// Keys signs access tokens.
+	Keys *auth.KeyRing
+	// AccessTTL is the lifetime of an access token.
+	AccessTTL time.Duration
+	// RefreshTTL is the lifetime of a session; refreshing does not extend it.
+	RefreshTTL time.Duration
+}
+
+// SessionClient describes the device a session was started or last
+// refreshed from.
+type SessionClient struct {
+	UserAgent string
+	IPAddress string
+}
+
+// TokenPair is the result of starting or refreshing a session.
+type TokenPair struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
+	SessionID       int
+}
+
+// StartSession opens a session for an authenticated user and issues its
+// first access and refresh tokens.
+//
+// Only a hash of the refresh token is stored; the plain token is returned
+// once and cannot be recovered. The access token carries the user's current
+// role.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the authenticated user
+//   - client: device the session is started from
+//
+// Returns the issued tokens or error.
+func (s *Service) StartSession(ctx context.Context, userID int, client SessionClient) (TokenPair, error) {
+	claims, err := auth.NewClaims(userID, s.Tokens.AccessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if claims.Role, err = s.userRole(ctx, userID); err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := auth.RandomSecret()
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	session, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+		UserID:           int32(userID),
+		RefreshTokenHash: auth.HashToken(refresh),
+		AccessJti:        claims.ID,
+		AccessExpiresAt:  claims.ExpiresAt.Time,
+		UserAgent:        sql.NullString{String: client.UserAgent, Valid: client.UserAgent != ""},
+		IpAddress:        sql.NullString{String: client.IPAddress, Valid: client.IPAddress != ""},
+		ExpiresAt:        time.Now().Add(s.Tokens.RefreshTTL),
+	})
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	claims.SessionID = int(session.ID)
+	return s.signPair(claims, refresh)
+}
+
+// RefreshSession rotates a refresh token: the presented token is retired
+// and a new access and refresh token pair is issued for the same session.
+//
+// Reuse detection: presenting a token that was already rotated means it was
+// copied, so the whole session is revoked and the legitimate holder must log
+// in again. The role is read afresh, so role changes apply on refresh.
+//
+// Parameters:
+//   - ctx: request context
+//   - refreshToken: refresh token issued by StartSession or a previous refresh
+//   - client: device the refresh comes from
+//
+// Returns the new tokens, or ErrInvalidRefreshToken.
+func (s *Service) RefreshSession(ctx context.Context, refreshToken string, client SessionClient) (TokenPair, error) {
+	oldHash := auth.HashToken(refreshToken)
+	next, err := auth.RandomSecret()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	// The user ID is filled in once the session is known.
+	claims, err := auth.NewClaims(0, s.Tokens.AccessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	row, err := s.q.RotateSession(ctx, db.RotateSessionParams{
+		NewHash:         auth.HashToken(next),
+		AccessJti:       claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		UserAgent:       sql.NullString{String: client.UserAgent, Valid: client.UserAgent != ""},
+		IpAddress:       sql.NullString{String: client.IPAddress, Valid: client.IPAddress != ""},
+		OldHash:         oldHash,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		reused, err := s.q.RevokeSessionByPreviousToken(ctx, sql.NullString{String: oldHash, Valid: true})
+		if err == nil {
+			_ = s.denyToken(ctx, reused.AccessJti, reused.AccessExpiresAt)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return TokenPair{}, err
+		}
+		return TokenPair{}, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	claims.UserID = int(row.UserID)
+	claims.SessionID = int(row.ID)
+	if claims.Role, err = s.userRole(ctx, claims.UserID); err != nil {
+		return TokenPair{}, err
+	}
+	return s.signPair(claims, next)
+}
+
+func (s *Service) signPair(claims auth.Claims, refresh string) (TokenPair, error) {
+	token, err := s.Tokens.Keys.Sign(claims)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:     token,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		RefreshToken:    refresh,
+		SessionID:       claims.SessionID,
+	}, nil
+}
+
+// EndSession logs out: it revokes the session behind an access token and
+// denylists the token itself so it stops working immediately.
+//
+// Parameters:
+//   - ctx: request context
+//   - claims: claims of the access token presented on logout
+//
+// Returns error if revocation fails.
+func (s *Service) EndSession(ctx context.Context, claims *auth.Claims) error {
+	if claims.SessionID > 0 {
+		_, err := s.q.RevokeSession(ctx, db.RevokeSessionParams{ID: int32(claims.SessionID), UserID: int32(claims.UserID)})
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+	return s.denyToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
+
+// ListSessions returns a user's active sessions, most recently used first.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//
+// Returns the active sessions or error.
+func (s *Service) ListSessions(ctx context.Context, userID int) ([]db.ListUserSessionsRow, error) {
+	return s.q.ListUserSessions(ctx, int32(userID))
+}
+
+// RevokeSession signs a device out: the session can no longer be refreshed
+// and its current access token is denylisted.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user owning the session
+//   - sessionID: ID of the session to revoke
+//
+// Returns ErrSessionNotFound if the user has no such active session.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	row, err := s.q.RevokeSession(ctx, db.RevokeSessionParams{ID: int32(sessionID), UserID: int32(userID)})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.denyToken(ctx, row.AccessJti, row.AccessExpiresAt)
+}
+
+// RevokeOtherSessions signs out every device except the current session.
+//
+// Parameters:
+//   - ctx: request context
+//   - userID: ID of the user
+//   - keepSessionID: session to keep (0 revokes all of them)
+//
+// Returns the number of sessions revoked or error.
+func (s *Service) RevokeOtherSessions(ctx context.Context, userID, keepSessionID int) (int, error) {
+	rows, err := s.q.RevokeOtherSessions(ctx, db.RevokeOtherSessionsParams{UserID: int32(userID), ID: int32(keepSessionID)})
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range rows {
+		if err := s.denyToken(ctx, row.AccessJti, row.AccessExpiresAt); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), nil
+}
+
+// IsTokenRevoked reports whether an access token ID is on the denylist.
+func (s *Service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.q.IsTokenRevoked(ctx, jti)
+}
+
+// PruneSessions deletes expired sessions and denylist entries for tokens
+// that have expired anyway.
+func (s *Service) PruneSessions(ctx context.Context) error {
+	if err := s.q.DeleteExpiredSessions(ctx); err != nil {
+		return err
+	}
+	return s.q.DeleteExpiredRevokedTokens(ctx)
+}
+
+// denyToken denylists an access token until it expires. Tokens that have
+// already expired are skipped.
+func (s *Service) denyToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if jti == "" || !expiresAt.After(time.Now()) {
+		return nil
+	}
+	return s.q.RevokeToken(ctx, db.RevokeTokenParams{Jti: jti, ExpiresAt: expiresAt})
+}
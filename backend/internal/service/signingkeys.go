@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/auth"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/db"
+)
+
+// ErrNoSigningKey is returned when the database holds no key that can sign.
+var ErrNoSigningKey = fmt.Errorf("no active signing key")
+
+// LoadSigningKeys reads the JWT key ring from the signing_keys table.
+// It has the signature of auth.KeySourceFunc.
+//
+// Retired keys stay in the ring for one access-token lifetime after their
+// retirement, so tokens they signed keep validating until they expire.
+// The newest live key signs.
+//
+// Parameters:
+//   - ctx: request context
+//
+// Returns the keys and the ID of the active key, or ErrNoSigningKey.
+func (s *Service) LoadSigningKeys(ctx context.Context) ([]auth.SigningKey, string, error) {
+	rows, err := s.q.ListSigningKeys(ctx, sql.NullTime{Time: time.Now().Add(-s.Tokens.AccessTTL), Valid: true})
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]auth.SigningKey, 0, len(rows))
+	active := ""
+	for _, row := range rows {
+		data := row.PublicKeyPem
+		if row.PrivateKeyPem.Valid {
+			data = row.PrivateKeyPem.String
+		}
+		key, err := auth.ParseKeyPEM(row.Kid, []byte(data))
+		if err != nil {
+			return nil, "", err
+		}
+		keys = append(keys, key)
+		if key.CanSign() && !row.RetiredAt.Valid {
+			active = key.ID
+		}
+	}
+	if active == "" {
+		return nil, "", ErrNoSigningKey
+	}
+	return keys, active, nil
+}
+
+// RotateSigningKey generates a new signing key and retires the previous one.
+//
+// The retired key loses its private half but keeps verifying tokens until
+// they expire; keys retired longer ago than that are deleted.
+//
+// Parameters:
+//   - ctx: request context
+//   - alg: algorithm of the new key (auth.AlgRS256 or auth.AlgEdDSA)
+//
+// Returns the ID of the new key or error.
+func (s *Service) RotateSigningKey(ctx context.Context, alg string) (string, error) {
+	key, err := auth.GenerateSigningKey(alg)
+	if err != nil {
+		return "", err
+	}
+	priv, err := auth.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return "", err
+	}
+	pub, err := auth.EncodePublicKeyPEM(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.q.CreateSigningKey(ctx, db.CreateSigningKeyParams{
+		Kid:           key.ID,
+		Algorithm:     key.Algorithm,
+		PrivateKeyPem: sql.NullString{String: priv, Valid: true},
+		PublicKeyPem:  pub,
+	}); err != nil {
+		return "", err
+	}
+	if err := s.q.RetireOlderSigningKeys(ctx); err != nil {
+		return "", err
+	}
+	if err := s.q.DeleteRetiredSigningKeys(ctx, sql.NullTime{Time: time.Now().Add(-s.Tokens.AccessTTL), Valid: true}); err != nil {
+		return "", err
+	}
+	return key.ID, nil
+}
+
+// EnsureSigningKey rotates the signing key when there is none yet or when
+// the active one is older than maxAge (0 never rotates an existing key).
+//
+// Parameters:
+//   - ctx: request context
+//   - alg: algorithm for a newly generated key
+//   - maxAge: maximum age of the active key
+//
+// Returns whether a new key was generated.
+func (s *Service) EnsureSigningKey(ctx context.Context, alg string, maxAge time.Duration) (bool, error) {
+	rows, err := s.q.ListSigningKeys(ctx, sql.NullTime{Time: time.Now(), Valid: true})
+	if err != nil {
+		return false, err
+	}
+
+	var newest *db.SigningKey
+	for i := range rows {
+		if rows[i].PrivateKeyPem.Valid && !rows[i].RetiredAt.Valid {
+			newest = &rows[i]
+		}
+	}
+	if newest != nil && (maxAge <= 0 || time.Since(newest.CreatedAt) < maxAge) {
+		return false, nil
+	}
+
+	if _, err := s.RotateSigningKey(ctx, alg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+)
+
+// Conn is the database interface sqlc queries run on (db.DBTX).
+type Conn interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// DB wraps a database connection and records a client span for every
+// statement run within a traced request. Spans are named after the sqlc
+// query ("-- name: GetRecipeByID :one" gives "GetRecipeByID") and carry the
+// statement text, never its arguments.
+//
+// Spans of QueryContext end when the query returns, before its rows are
+// read.
+type DB struct {
+	Conn Conn
+}
+
+// ExecContext implements db.DBTX.
+func (d DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+	res, err := d.Conn.ExecContext(ctx, query, args...)
+	span.RecordError(err)
+	return res, err
+}
+
+// PrepareContext implements db.DBTX.
+func (d DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+	stmt, err := d.Conn.PrepareContext(ctx, query)
+	span.RecordError(err)
+	return stmt, err
+}
+
+// QueryContext implements db.DBTX.
+func (d DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+	rows, err := d.Conn.QueryContext(ctx, query, args...)
+	span.RecordError(err)
+	return rows, err
+}
+
+// QueryRowContext implements db.DBTX. sql.ErrNoRows is an expected outcome
+// and does not fail the span.
+func (d DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	defer span.End()
+	row := d.Conn.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+	}
+	return row
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, *Span) {
+	name := queryName(query)
+	return Start(ctx, name, WithKind(KindClient), WithAttributes(
+		slog.String("db.system", "postgresql"),
+		slog.String("db.operation.name", name),
+		slog.String("db.query.text", query),
+	))
+}
+
+// queryName returns the sqlc name of a query, or "db.query" for statements
+// without the "-- name:" comment.
+func queryName(query string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(query), "\n")
+	rest, ok := strings.CutPrefix(line, "-- name:")
+	if !ok {
+		return "db.query"
+	}
+	if fields := strings.Fields(rest); len(fields) > 0 {
+		return fields[0]
+	}
+	return "db.query"
+}
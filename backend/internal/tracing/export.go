@@ -0,0 +1,266 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scopeName is the instrumentation scope reported with exported spans.
+const scopeName = "github.com/varnit-ta/smart-recipe-generator/backend/internal/tracing"
+
+// Exporter sends ended spans to a tracing backend. Export is called from a
+// single goroutine.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// StdoutExporter writes every span as a line of JSON, for local runs.
+type StdoutExporter struct {
+	W  io.Writer
+	mu sync.Mutex
+}
+
+// stdoutSpan is the JSON form of a span written by StdoutExporter.
+type stdoutSpan struct {
+	Name          string         `json:"name"`
+	Kind          string         `json:"kind"`
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentID      string         `json:"parent_id,omitempty"`
+	Start         time.Time      `json:"start"`
+	DurationMS    float64        `json:"duration_ms"`
+	Status        string         `json:"status,omitempty"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+}
+
+// Export implements Exporter.
+func (e *StdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	enc := json.NewEncoder(e.W)
+	for _, s := range spans {
+		out := stdoutSpan{
+			Name:          s.Name,
+			Kind:          kindName(s.Kind),
+			TraceID:       s.SpanContext.TraceID.String(),
+			SpanID:        s.SpanContext.SpanID.String(),
+			Start:         s.Start,
+			DurationMS:    float64(s.End.Sub(s.Start).Microseconds()) / 1000,
+			Status:        statusName(s.Status),
+			StatusMessage: s.StatusMessage,
+		}
+		if s.Parent.IsValid() {
+			out.ParentID = s.Parent.String()
+		}
+		if len(s.Attributes) > 0 {
+			out.Attributes = make(map[string]any, len(s.Attributes))
+			for _, a := range flatten("", s.Attributes) {
+				out.Attributes[a.Key] = a.Value.Any()
+			}
+		}
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func kindName(k SpanKind) string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+func statusName(c StatusCode) string {
+	switch c {
+	case StatusOK:
+		return "ok"
+	case StatusError:
+		return "error"
+	default:
+		return ""
+	}
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector with OTLP over
+// HTTP, using the JSON encoding (protocol "http/json").
+type OTLPExporter struct {
+	// Endpoint is the full traces URL, e.g. http://localhost:4318/v1/traces.
+	Endpoint string
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string
+	// Client sends the requests; nil uses a client with a 10s timeout.
+	Client *http.Client
+}
+
+// Export implements Exporter.
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otlp export: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// OTLP/JSON request types, following the protobuf JSON mapping of
+// ExportTraceServiceRequest. IDs are hex encoded and 64-bit integers are
+// strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		TraceState        string         `json:"traceState,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+func (e *OTLPExporter) request(spans []SpanData) otlpRequest {
+	out := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		out[i] = otlpSpan{
+			TraceID:           s.SpanContext.TraceID.String(),
+			SpanID:            s.SpanContext.SpanID.String(),
+			TraceState:        s.SpanContext.TraceState,
+			Name:              s.Name,
+			Kind:              int(s.Kind),
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            otlpStatus{Code: int(s.Status), Message: s.StatusMessage},
+		}
+		if s.Parent.IsValid() {
+			out[i].ParentSpanID = s.Parent.String()
+		}
+	}
+	resource := otlpAttributes([]slog.Attr{slog.String("service.name", e.ServiceName)})
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: resource},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: scopeName}, Spans: out}},
+	}}}
+}
+
+func otlpAttributes(attrs []slog.Attr) []otlpKeyValue {
+	flat := flatten("", attrs)
+	out := make([]otlpKeyValue, 0, len(flat))
+	for _, a := range flat {
+		var v otlpValue
+		switch a.Value.Kind() {
+		case slog.KindInt64:
+			s := strconv.FormatInt(a.Value.Int64(), 10)
+			v.IntValue = &s
+		case slog.KindUint64:
+			s := strconv.FormatUint(a.Value.Uint64(), 10)
+			v.IntValue = &s
+		case slog.KindFloat64:
+			f := a.Value.Float64()
+			v.DoubleValue = &f
+		case slog.KindBool:
+			b := a.Value.Bool()
+			v.BoolValue = &b
+		case slog.KindDuration:
+			s := strconv.FormatInt(int64(a.Value.Duration()), 10)
+			v.IntValue = &s
+		default:
+			s := a.Value.String()
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: a.Key, Value: v})
+	}
+	return out
+}
+
+// flatten resolves attribute values and turns groups into dotted keys, as
+// span attributes cannot nest.
+func flatten(prefix string, attrs []slog.Attr) []slog.Attr {
+	var out []slog.Attr
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		key := a.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			out = append(out, flatten(key, a.Value.Group())...)
+			continue
+		}
+		out = append(out, slog.Attr{Key: key, Value: a.Value})
+	}
+	return out
+}
@@ -0,0 +1,424 @@
+// Package tracing records distributed traces compatible with OpenTelemetry.
+//
+// A Tracer starts spans, samples traces and hands ended spans to an
+// Exporter in batches: OTLPExporter sends them to an OpenTelemetry collector
+// over OTLP/HTTP (JSON encoding) and StdoutExporter prints them for local
+// runs. Trace context travels between services in W3C traceparent and
+// tracestate headers (see Extract and Inject).
+//
+// The package implements the subset of the OpenTelemetry tracing API the
+// application needs on top of the standard library. Attributes are
+// slog.Attr values, so the same helpers build log and span attributes.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// String returns the ID as 32 lowercase hex digits.
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+// IsValid reports whether the ID is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// String returns the ID as 16 lowercase hex digits.
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the ID is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is the part of a span that is propagated to child spans and
+// other services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	// TraceState is the vendor-specific tracestate header, passed on as is.
+	TraceState string
+	// Remote is set for a span context extracted from an incoming request.
+	Remote bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind describes the relationship of a span to its parent and children.
+type SpanKind int
+
+// Span kinds, numbered as in OTLP.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+)
+
+// StatusCode is the outcome of a span.
+type StatusCode int
+
+// Status codes, numbered as in OTLP.
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// SpanData is an ended span as handed to an Exporter.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	Parent        SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    []slog.Attr
+	Status        StatusCode
+	StatusMessage string
+}
+
+// Span is an operation within a trace. All methods are safe to call on a
+// nil *Span, which records nothing, so code can be instrumented whether or
+// not tracing is enabled.
+type Span struct {
+	tracer *Tracer
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// SpanContext returns the span's propagated context.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// IsRecording reports whether the span is sampled and not yet ended.
+func (s *Span) IsRecording() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.SpanContext.Sampled && !s.ended
+}
+
+// SetName replaces the span's name, e.g. once the route of a request is
+// known.
+func (s *Span) SetName(name string) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.data.Name = name
+	s.mu.Unlock()
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...slog.Attr) {
+	if !s.IsRecording() {
+		return
+	}
+	s.mu.Lock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+	s.mu.Unlock()
+}
+
+// SetStatus sets the outcome of the span; the message is only kept for
+// StatusError.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if !s.IsRecording() {
+		return
+	}
+	if code != StatusError {
+		message = ""
+	}
+	s.mu.Lock()
+	s.data.Status, s.data.StatusMessage = code, message
+	s.mu.Unlock()
+}
+
+// RecordError marks the span as failed with err; a nil err is ignored.
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.SetStatus(StatusError, err.Error())
+}
+
+// End completes the span and queues it for export. Calls after the first
+// have no effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.tracer.enqueue(data)
+	}
+}
+
+// Option configures a span started by Start.
+type Option func(*SpanData)
+
+// WithKind sets the span kind; spans are internal by default.
+func WithKind(kind SpanKind) Option {
+	return func(d *SpanData) { d.Kind = kind }
+}
+
+// WithAttributes sets the span's initial attributes.
+func WithAttributes(attrs ...slog.Attr) Option {
+	return func(d *SpanData) { d.Attributes = append(d.Attributes, attrs...) }
+}
+
+type ctxKey int
+
+const (
+	spanKey ctxKey = iota
+	remoteKey
+)
+
+// ContextWithSpan returns a copy of ctx carrying span as the current span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey, span)
+}
+
+// SpanFromContext returns the current span of ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// ContextWithRemoteSpanContext returns a copy of ctx carrying a span
+// context received from another service, to be the parent of the next
+// span started.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	sc.Remote = true
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span of
+// ctx, or else the remote one it carries, or an invalid one.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext()
+	}
+	sc, _ := ctx.Value(remoteKey).(SpanContext)
+	return sc
+}
+
+// Start starts a child of the current span of ctx with the same tracer. If
+// ctx has no current span, tracing is off for this operation and Start
+// returns ctx and a nil span, so background work is not traced unless a
+// tracer starts a root span for it.
+func Start(ctx context.Context, name string, opts ...Option) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, opts...)
+}
+
+// Config configures a Tracer.
+type Config struct {
+	// Exporter receives ended spans; required.
+	Exporter Exporter
+	// SampleRatio is the fraction of new traces recorded, from 0 to 1.
+	// Traces continued from another service follow the caller's decision.
+	SampleRatio float64
+	// BatchSize is the most spans exported at once. Default: 512.
+	BatchSize int
+	// QueueSize is the most ended spans waiting for export; further spans
+	// are dropped. Default: 2048.
+	QueueSize int
+	// FlushInterval is how often queued spans are exported. Default: 5s.
+	FlushInterval time.Duration
+}
+
+// Tracer starts spans and exports them in the background. All methods are
+// safe to call on a nil *Tracer, which disables tracing.
+type Tracer struct {
+	cfg   Config
+	queue chan SpanData
+	stop  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewTracer creates a tracer and starts its export loop; Shutdown stops it.
+func NewTracer(cfg Config) *Tracer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	t := &Tracer{
+		cfg:   cfg,
+		queue: make(chan SpanData, cfg.QueueSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Start starts a span. Its parent is the current span of ctx, or else a
+// remote span context carried by ctx; without either it starts a new
+// trace. The returned context carries the new span.
+func (t *Tracer) Start(ctx context.Context, name string, opts ...Option) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	parent := SpanContextFromContext(ctx)
+
+	data := SpanData{Name: name, Kind: KindInternal, Start: time.Now()}
+	sc := SpanContext{SpanID: newSpanID()}
+	if parent.IsValid() {
+		sc.TraceID, sc.Sampled, sc.TraceState = parent.TraceID, parent.Sampled, parent.TraceState
+		data.Parent = parent.SpanID
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Sampled = t.sample(sc.TraceID)
+	}
+	data.SpanContext = sc
+	if sc.Sampled {
+		for _, opt := range opts {
+			opt(&data)
+		}
+	}
+
+	span := &Span{tracer: t, data: data}
+	return ContextWithSpan(ctx, span), span
+}
+
+// sample decides whether to record a new trace from its ID, so every
+// service seeing the ID would decide alike.
+func (t *Tracer) sample(id TraceID) bool {
+	switch {
+	case t.cfg.SampleRatio >= 1:
+		return true
+	case t.cfg.SampleRatio <= 0:
+		return false
+	}
+	return binary.BigEndian.Uint64(id[8:]) < uint64(t.cfg.SampleRatio*math.MaxUint64)
+}
+
+func (t *Tracer) enqueue(data SpanData) {
+	if t == nil {
+		return
+	}
+	select {
+	case <-t.stop:
+	case t.queue <- data:
+	default:
+		slog.Warn("trace export queue full, span dropped", slog.String("span", data.Name))
+	}
+}
+
+// run exports queued spans in batches until Shutdown.
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]SpanData, 0, t.cfg.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := t.cfg.Exporter.Export(ctx, batch); err != nil {
+			slog.Error("trace export failed", slog.Int("spans", len(batch)), slog.Any("error", err))
+		}
+		cancel()
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case data := <-t.queue:
+			batch = append(batch, data)
+			if len(batch) == t.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-t.stop:
+			for {
+				select {
+				case data := <-t.queue:
+					batch = append(batch, data)
+					if len(batch) == t.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// Shutdown exports the spans still queued and stops the export loop.
+// Spans ended afterwards are dropped.
+//
+// Returns ctx.Err() if the queue could not be drained in time.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.once.Do(func() { close(t.stop) })
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,138 @@
+// Package validate checks user input and collects field-level errors.
+//
+// Checks return nil or an error describing the problem; Errors gathers them
+// per field so that every problem can be reported in a single response:
+//
+//	var errs validate.Errors
+//	errs.Check("email", validate.Email(email))
+//	errs.Check("password", validate.Password(password))
+//	if err := errs.Err(); err != nil {
+//		return err
+//	}
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Password length limits. bcrypt ignores everything past 72 bytes, so longer
+// passwords are rejected rather than silently truncated.
+const (
+	MinPasswordLength = 8
+	MaxPasswordBytes  = 72
+)
+
+// Username length limits.
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 30
+)
+
+// MaxEmailLength is the longest address accepted (RFC 5321 path limit).
+const MaxEmailLength = 254
+
+// FieldError describes a problem with one input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors is a list of field errors. A non-empty Errors is an error.
+type Errors []FieldError
+
+// Error joins the field errors into one message.
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, f := range e {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Add records a problem with field.
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Check records err against field if it is not nil.
+func (e *Errors) Check(field string, err error) {
+	if err != nil {
+		e.Add(field, err.Error())
+	}
+}
+
+// Err returns e as an error, or nil when no problems were recorded.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Email checks that s is a bare email address such as "jo@example.com",
+// without a display name or surrounding spaces.
+func Email(s string) error {
+	if s == "" {
+		return fmt.Errorf("is required")
+	}
+	if len(s) > MaxEmailLength {
+		return fmt.Errorf("must be at most %d characters", MaxEmailLength)
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return fmt.Errorf("is not a valid email address")
+	}
+	domain := s[strings.LastIndexByte(s, '@')+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+// Password checks that s is between MinPasswordLength characters and
+// MaxPasswordBytes bytes long and mixes letters with digits or symbols.
+func Password(s string) error {
+	if utf8.RuneCountInString(s) < MinPasswordLength {
+		return fmt.Errorf("must be at least %d characters", MinPasswordLength)
+	}
+	if len(s) > MaxPasswordBytes {
+		return fmt.Errorf("must be at most %d bytes", MaxPasswordBytes)
+	}
+	var letter, other bool
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letter = true
+		} else if !unicode.IsSpace(r) {
+			other = true
+		}
+	}
+	if !letter || !other {
+		return fmt.Errorf("must contain a letter and a digit or symbol")
+	}
+	return nil
+}
+
+// Username checks that s is MinUsernameLength to MaxUsernameLength
+// characters of ASCII letters, digits, '.', '_' or '-', starting with a
+// letter or digit.
+func Username(s string) error {
+	if s == "" {
+		return fmt.Errorf("is required")
+	}
+	if len(s) < MinUsernameLength || len(s) > MaxUsernameLength {
+		return fmt.Errorf("must be %d to %d characters", MinUsernameLength, MaxUsernameLength)
+	}
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case (r == '.' || r == '_' || r == '-') && i > 0:
+		default:
+			return fmt.Errorf("may only contain letters, digits, '.', '_' and '-', and must start with a letter or digit")
+		}
+	}
+	return nil
+}
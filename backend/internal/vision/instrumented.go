@@ -0,0 +1,46 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/metrics"
+)
+
+// Instrumented wraps a VisionService and records the latency, failures and
+// ingredient counts of its calls by provider.
+type Instrumented struct {
+	Service VisionService
+	Metrics *metrics.Metrics
+}
+
+// DetectIngredients implements VisionService.
+func (s Instrumented) DetectIngredients(ctx context.Context, imageData []byte, filename string) (*DetectionResult, error) {
+	start := time.Now()
+	result, err := s.Service.DetectIngredients(ctx, imageData, filename)
+
+	provider := "unknown"
+	var detErr *DetectionError
+	switch {
+	case err == nil && result != nil:
+		provider = result.Provider
+	case errors.As(err, &detErr):
+		provider = detErr.Provider
+	}
+	ingredients := 0
+	if result != nil {
+		ingredients = len(result.Ingredients)
+	}
+	s.Metrics.ObserveDetection(provider, time.Since(start), ingredients, err)
+	return result, err
+}
+
+// Health implements HealthChecker if the wrapped service does; otherwise the
+// service is assumed healthy.
+func (s Instrumented) Health(ctx context.Context) (map[string]any, error) {
+	if hc, ok := s.Service.(HealthChecker); ok {
+		return hc.Health(ctx)
+	}
+	return nil, nil
+}
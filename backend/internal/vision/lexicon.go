@@ -0,0 +1,33 @@
+package vision
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// customIngredients holds the admin-managed variants from the
+// ingredient_lexicon table. They are consulted before commonIngredients, so
+// they can add ingredients as well as override built-in mappings.
+var customIngredients atomic.Pointer[map[string]string]
+
+// SetCustomIngredients replaces the admin-managed ingredient variants.
+// Keys are variants, values their canonical names; both are lowercased.
+// Safe for concurrent use with parsing.
+func SetCustomIngredients(variants map[string]string) {
+	m := make(map[string]string, len(variants))
+	for variant, canonical := range variants {
+		m[strings.ToLower(strings.TrimSpace(variant))] = strings.ToLower(strings.TrimSpace(canonical))
+	}
+	customIngredients.Store(&m)
+}
+
+// lookupIngredient resolves a lowercase variant to its canonical name.
+func lookupIngredient(variant string) (string, bool) {
+	if m := customIngredients.Load(); m != nil {
+		if canonical, found := (*m)[variant]; found {
+			return canonical, true
+		}
+	}
+	canonical, found := commonIngredients[variant]
+	return canonical, found
+}
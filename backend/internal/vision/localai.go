@@ -7,12 +7,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/logging"
+	"github.com/varnit-ta/smart-recipe-generator/backend/internal/tracing"
 )
 
 // LocalAIService implements VisionService using a local Python AI service.
@@ -71,8 +75,24 @@ type AIServiceResponse struct {
 //   - imageData: Raw image bytes (JPEG, PNG, etc.)
 //   - filename: Original filename for logging/metadata
 //
+// The request ID of ctx, if any, is forwarded in the X-Request-ID header so
+// the AI service's logs can be matched with the API's, and progress is
+// logged with the logger of ctx. The call is traced as a client span whose
+// context is sent in the W3C traceparent header.
+//
 // Returns DetectionResult with ingredients or error on failure.
-func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte, filename string) (*DetectionResult, error) {
+func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte, filename string) (_ *DetectionResult, err error) {
+	logger := logging.FromContext(ctx).With(slog.String("provider", "local-ai"))
+	url := s.serviceURL + "/detect"
+	ctx, span := tracing.Start(ctx, "POST /detect", tracing.WithKind(tracing.KindClient), tracing.WithAttributes(
+		slog.String("http.request.method", http.MethodPost),
+		slog.String("url.full", url),
+		slog.Int("vision.image_size", len(imageData)),
+	))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
@@ -95,21 +115,26 @@ func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte
 		return nil, &DetectionError{Provider: "local-ai", Err: fmt.Errorf("failed to close writer: %w", err)}
 	}
 
-	url := s.serviceURL + "/detect"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return nil, &DetectionError{Provider: "local-ai", Err: err}
 	}
 
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	tracing.Inject(ctx, req.Header)
 
-	fmt.Printf("Calling local AI service at: %s (content-type: %s)\n", url, contentType)
+	logger.Debug("calling AI service", slog.String("url", url), slog.String("content_type", contentType), slog.Int("image_size", len(imageData)))
 
+	start := time.Now()
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, &DetectionError{Provider: "local-ai", Err: fmt.Errorf("AI service request failed: %w (is the service running?)", err)}
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(slog.Int("http.response.status_code", resp.StatusCode))
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -117,7 +142,7 @@ func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("AI service error. Status: %d, Body: %s\n", resp.StatusCode, string(respBody))
+		logger.Error("AI service error", slog.Int("status", resp.StatusCode), slog.String("body", string(respBody)))
 		return nil, &DetectionError{
 			Provider: "local-ai",
 			Err:      fmt.Errorf("AI service returned status %d: %s", resp.StatusCode, string(respBody)),
@@ -137,10 +162,15 @@ func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte
 
 	if len(ingredients) == 0 && aiResp.Caption != "" {
 		ingredients = ParseIngredientsFromText(aiResp.Caption)
-		fmt.Printf("No ingredients in response, parsed %d from caption\n", len(ingredients))
+		logger.Debug("no ingredients in response, parsed caption", slog.Int("count", len(ingredients)))
 	}
 
-	fmt.Printf("Local AI service detected %d ingredients: %v\n", len(ingredients), ingredients)
+	span.SetAttributes(slog.Int("vision.ingredients", len(ingredients)))
+	logger.Info("ingredients detected",
+		slog.Int("count", len(ingredients)),
+		slog.Any("ingredients", ingredients),
+		slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+	)
 
 	modelInfo := "local-ai"
 	if aiResp.Model != nil {
@@ -183,6 +213,59 @@ func (s *LocalAIService) DetectIngredients(ctx context.Context, imageData []byte
 	return result, nil
 }
 
+// aiHealthResponse is the response of the Python AI service's /health endpoint.
+type aiHealthResponse struct {
+	Status              string `json:"status"`
+	BLIPModelLoaded     bool   `json:"blip_model_loaded"`
+	BLIPProcessorLoaded bool   `json:"blip_processor_loaded"`
+	CLIPModelLoaded     bool   `json:"clip_model_loaded"`
+	CLIPProcessorLoaded bool   `json:"clip_processor_loaded"`
+	Device              string `json:"device"`
+}
+
+// Health implements HealthChecker by calling the AI service's /health
+// endpoint. The service is healthy once it answers and both the BLIP and
+// CLIP models are loaded, as detection needs them all.
+//
+// Parameters:
+//   - ctx: Context bounding the call
+//
+// Returns the device the models run on and which of them are loaded, or an
+// error if the service cannot detect ingredients.
+func (s *LocalAIService) Health(ctx context.Context) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.serviceURL+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(logging.RequestIDHeader, id)
+	}
+	tracing.Inject(ctx, req.Header)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("AI service unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("AI service returned status %d", resp.StatusCode)
+	}
+
+	var h aiHealthResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&h); err != nil {
+		return nil, fmt.Errorf("failed to parse health response: %w", err)
+	}
+	details := map[string]any{
+		"device":            h.Device,
+		"blip_model_loaded": h.BLIPModelLoaded && h.BLIPProcessorLoaded,
+		"clip_model_loaded": h.CLIPModelLoaded && h.CLIPProcessorLoaded,
+	}
+	if !h.BLIPModelLoaded || !h.BLIPProcessorLoaded || !h.CLIPModelLoaded || !h.CLIPProcessorLoaded {
+		return details, fmt.Errorf("AI models not loaded")
+	}
+	return details, nil
+}
+
 // getContentTypeFromFilename determines the MIME type based on file extension
 func getContentTypeFromFilename(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
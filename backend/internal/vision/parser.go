@@ -178,7 +178,7 @@ func ParseIngredientsFromText(text string) []string {
 	words := splitWords(lowerText)
 
 	for i := 0; i < len(words); i++ {
-		if normalized, found := commonIngredients[words[i]]; found {
+		if normalized, found := lookupIngredient(words[i]); found {
 			if !detected[normalized] {
 				detected[normalized] = true
 				ingredients = append(ingredients, normalized)
@@ -187,7 +187,7 @@ func ParseIngredientsFromText(text string) []string {
 
 		if i < len(words)-1 {
 			twoWord := words[i] + " " + words[i+1]
-			if normalized, found := commonIngredients[twoWord]; found {
+			if normalized, found := lookupIngredient(twoWord); found {
 				if !detected[normalized] {
 					detected[normalized] = true
 					ingredients = append(ingredients, normalized)
@@ -197,7 +197,7 @@ func ParseIngredientsFromText(text string) []string {
 
 		if i < len(words)-2 {
 			threeWord := words[i] + " " + words[i+1] + " " + words[i+2]
-			if normalized, found := commonIngredients[threeWord]; found {
+			if normalized, found := lookupIngredient(threeWord); found {
 				if !detected[normalized] {
 					detected[normalized] = true
 					ingredients = append(ingredients, normalized)
@@ -262,7 +262,7 @@ func splitWords(text string) []string {
 // Returns the normalized canonical name, or lowercase trimmed input if not found.
 func NormalizeIngredientName(name string) string {
 	lower := strings.ToLower(strings.TrimSpace(name))
-	if normalized, found := commonIngredients[lower]; found {
+	if normalized, found := lookupIngredient(lower); found {
 		return normalized
 	}
 	return lower
@@ -274,9 +274,10 @@ func NormalizeIngredientName(name string) string {
 // Parameters:
 //   - word: potential ingredient name
 //
-// Returns true if the word is in the ingredient database.
+// Returns true if the word is in the ingredient database or the
+// admin-managed lexicon.
 func IsLikelyFood(word string) bool {
 	normalized := strings.ToLower(strings.TrimSpace(word))
-	_, found := commonIngredients[normalized]
+	_, found := lookupIngredient(normalized)
 	return found
 }
@@ -5,6 +5,7 @@ package vision
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 // VisionService defines the interface for AI-powered ingredient detection from images.
@@ -17,6 +18,34 @@ type VisionService interface {
 	DetectIngredients(ctx context.Context, imageData []byte, filename string) (*DetectionResult, error)
 }
 
+// HealthChecker is implemented by vision services that can report whether
+// they are able to detect ingredients, for the readiness check.
+type HealthChecker interface {
+	// Health returns details worth reporting about the service, and an
+	// error if it cannot serve detections.
+	Health(ctx context.Context) (map[string]any, error)
+}
+
+// SupportedImageType reports whether contentType is an image format the
+// services accept.
+//
+// Supported types: JPEG, PNG, GIF, WebP
+func SupportedImageType(contentType string) bool {
+	validTypes := []string{
+		"image/jpeg",
+		"image/jpg",
+		"image/png",
+		"image/gif",
+		"image/webp",
+	}
+	for _, t := range validTypes {
+		if strings.Contains(strings.ToLower(contentType), t) {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectionResult contains the ingredients detected from an image
 // along with confidence scores and metadata about the detection process.
 type DetectionResult struct {
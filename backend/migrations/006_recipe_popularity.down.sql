@@ -0,0 +1,3 @@
+-- Remove popularity view and view tracking
+DROP MATERIALIZED VIEW IF EXISTS recipe_popularity;
+DROP TABLE IF EXISTS recipe_views CASCADE;
@@ -0,0 +1,46 @@
+-- Track recipe views and materialise time-decayed popularity scores
+CREATE TABLE IF NOT EXISTS recipe_views (
+  id SERIAL PRIMARY KEY,
+  recipe_id INTEGER REFERENCES recipes(id) ON DELETE CASCADE,
+  user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+  viewed_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_views_recipe_viewed ON recipe_views (recipe_id, viewed_at);
+
+-- One row per (recipe, window). Each event is weighted (favorite 3, rating
+-- rating/2.5, view 0.5); popular_score is the plain weighted sum inside the
+-- window, trending_score halves every half_life_hours. now() is evaluated at
+-- refresh time, so scores are only as fresh as the last REFRESH.
+CREATE MATERIALIZED VIEW IF NOT EXISTS recipe_popularity AS
+WITH windows (time_window, since, half_life_hours) AS (
+  VALUES
+    ('day', now() - interval '1 day', 6.0::float8),
+    ('week', now() - interval '7 days', 36.0::float8),
+    ('all', '-infinity'::timestamptz, 720.0::float8)
+),
+events AS (
+  SELECT recipe_id, 'favorite'::text AS kind, created_at AS occurred_at, 3.0::float8 AS weight FROM favorites
+  UNION ALL
+  SELECT recipe_id, 'rating'::text, created_at, rating::float8 / 2.5 FROM ratings
+  UNION ALL
+  SELECT recipe_id, 'view'::text, viewed_at, 0.5::float8 FROM recipe_views
+)
+SELECT
+  r.id AS recipe_id,
+  w.time_window::text AS time_window,
+  (COUNT(e.recipe_id) FILTER (WHERE e.kind = 'favorite'))::integer AS favorites_count,
+  (COUNT(e.recipe_id) FILTER (WHERE e.kind = 'rating'))::integer AS ratings_count,
+  (COUNT(e.recipe_id) FILTER (WHERE e.kind = 'view'))::integer AS views_count,
+  COALESCE(SUM(e.weight), 0)::float8 AS popular_score,
+  COALESCE(SUM(e.weight * power(0.5, EXTRACT(EPOCH FROM (now() - e.occurred_at))::float8 / 3600.0 / w.half_life_hours)), 0)::float8 AS trending_score,
+  now() AS refreshed_at
+FROM recipes r
+CROSS JOIN windows w
+LEFT JOIN events e ON e.recipe_id = r.id AND e.occurred_at >= w.since
+GROUP BY r.id, w.time_window;
+
+-- Required for REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipe_popularity_recipe_window ON recipe_popularity (recipe_id, time_window);
+CREATE INDEX IF NOT EXISTS idx_recipe_popularity_window_trending ON recipe_popularity (time_window, trending_score DESC);
+CREATE INDEX IF NOT EXISTS idx_recipe_popularity_window_popular ON recipe_popularity (time_window, popular_score DESC);
@@ -0,0 +1,10 @@
+-- Remove full-text search support
+DROP INDEX IF EXISTS idx_recipes_search_vector;
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+DROP FUNCTION IF EXISTS recipes_search_vector_update();
+
+ALTER TABLE recipes
+  DROP COLUMN IF EXISTS search_vector;
+
+DROP FUNCTION IF EXISTS recipe_steps_text(JSONB);
+DROP FUNCTION IF EXISTS recipe_ingredient_names(JSONB);
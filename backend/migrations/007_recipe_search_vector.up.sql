@@ -0,0 +1,41 @@
+-- Weighted full-text search over title, tags, description, ingredients and steps
+
+-- Ingredient names from the ingredients JSON array (objects with "name" or plain strings)
+CREATE OR REPLACE FUNCTION recipe_ingredient_names(ingredients JSONB) RETURNS TEXT AS $$
+  SELECT CASE WHEN jsonb_typeof(ingredients) = 'array' THEN
+    (SELECT string_agg(COALESCE(i->>'name', i #>> '{}'), ' ') FROM jsonb_array_elements(ingredients) i)
+  END
+$$ LANGUAGE sql IMMUTABLE;
+
+-- Step text from the steps JSON array
+CREATE OR REPLACE FUNCTION recipe_steps_text(steps JSONB) RETURNS TEXT AS $$
+  SELECT CASE WHEN jsonb_typeof(steps) = 'array' THEN
+    (SELECT string_agg(s #>> '{}', ' ') FROM jsonb_array_elements(steps) s)
+  END
+$$ LANGUAGE sql IMMUTABLE;
+
+ALTER TABLE recipes
+  ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Weights: title A, tags and description B, ingredients C, steps D
+CREATE OR REPLACE FUNCTION recipes_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+  NEW.search_vector :=
+    setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tags, ' '), '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(recipe_ingredient_names(NEW.ingredients), '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(recipe_steps_text(NEW.steps), '')), 'D');
+  RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+CREATE TRIGGER recipes_search_vector_trigger
+  BEFORE INSERT OR UPDATE OF title, tags, description, ingredients, steps ON recipes
+  FOR EACH ROW EXECUTE FUNCTION recipes_search_vector_update();
+
+-- Backfill existing rows through the trigger
+UPDATE recipes SET title = title;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_search_vector ON recipes USING GIN (search_vector);
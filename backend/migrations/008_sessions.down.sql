@@ -0,0 +1,3 @@
+-- Remove sessions and the access-token denylist
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,28 @@
+-- Refresh-token sessions and the denylist of revoked access-token IDs
+CREATE TABLE IF NOT EXISTS sessions (
+  id SERIAL PRIMARY KEY,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  -- SHA-256 of the current refresh token; the token itself is never stored
+  refresh_token_hash TEXT NOT NULL UNIQUE,
+  -- hash of the token it replaced, used to detect refresh-token reuse
+  previous_token_hash TEXT,
+  -- ID and expiry of the latest access token, denylisted on revocation
+  access_jti TEXT NOT NULL,
+  access_expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  user_agent TEXT,
+  ip_address TEXT,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  last_used_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_previous_token_hash ON sessions(previous_token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+  jti TEXT PRIMARY KEY,
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
@@ -0,0 +1,2 @@
+-- Remove JWT signing keys
+DROP TABLE IF EXISTS signing_keys;
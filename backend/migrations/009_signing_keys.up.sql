@@ -0,0 +1,12 @@
+-- JWT signing keys for the database key source
+CREATE TABLE IF NOT EXISTS signing_keys (
+  kid TEXT PRIMARY KEY,
+  algorithm TEXT NOT NULL CHECK (algorithm IN ('RS256', 'EdDSA')),
+  -- PKCS #8 PEM; cleared when the key is retired so it can only verify
+  private_key_pem TEXT,
+  public_key_pem TEXT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  retired_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_created_at ON signing_keys(created_at);
@@ -0,0 +1,5 @@
+-- Remove account tokens and email verification
+DROP TABLE IF EXISTS account_tokens;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS email_verified_at;
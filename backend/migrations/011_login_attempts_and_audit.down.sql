@@ -0,0 +1,3 @@
+-- Remove login lockout tracking and the audit log
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS login_attempts;
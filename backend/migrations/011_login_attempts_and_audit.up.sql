@@ -0,0 +1,22 @@
+-- Failed login tracking for lockout, and an audit log of security events
+CREATE TABLE IF NOT EXISTS login_attempts (
+  -- "account:<email>" or "ip:<address>"
+  key TEXT PRIMARY KEY,
+  failures INTEGER NOT NULL,
+  last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  locked_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+  id SERIAL PRIMARY KEY,
+  event TEXT NOT NULL,
+  user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+  -- what the event is about when there is no user, e.g. the email tried
+  subject TEXT,
+  ip_address TEXT,
+  details JSONB,
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events(user_id);
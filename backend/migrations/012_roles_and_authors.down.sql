@@ -0,0 +1,10 @@
+-- Remove user roles, recipe authorship and the ingredient lexicon
+DROP TABLE IF EXISTS ingredient_lexicon;
+
+DROP INDEX IF EXISTS idx_recipes_author_id;
+
+ALTER TABLE recipes
+  DROP COLUMN IF EXISTS author_id;
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS role;
@@ -0,0 +1,17 @@
+-- User roles, recipe authorship and admin-managed ingredient names
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'editor', 'moderator', 'admin'));
+
+ALTER TABLE recipes
+  ADD COLUMN IF NOT EXISTS author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_author_id ON recipes(author_id);
+
+-- Ingredient name variants added to, or overriding, the built-in lexicon
+-- used to parse detected ingredients
+CREATE TABLE IF NOT EXISTS ingredient_lexicon (
+  variant TEXT PRIMARY KEY,
+  canonical TEXT NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
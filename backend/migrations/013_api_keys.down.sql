@@ -0,0 +1,2 @@
+-- Remove personal API keys
+DROP TABLE IF EXISTS api_keys;
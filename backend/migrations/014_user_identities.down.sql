@@ -0,0 +1,3 @@
+-- Remove OpenID Connect identities and pending logins
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,12 @@
+-- Remove profile fields and email changes
+DELETE FROM account_tokens WHERE purpose = 'change_email';
+
+ALTER TABLE account_tokens
+  DROP CONSTRAINT IF EXISTS account_tokens_purpose_check,
+  ADD CONSTRAINT account_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password'));
+
+ALTER TABLE users
+  DROP COLUMN IF EXISTS pending_email,
+  DROP COLUMN IF EXISTS avatar_url,
+  DROP COLUMN IF EXISTS display_name;
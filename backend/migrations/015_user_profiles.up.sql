@@ -0,0 +1,12 @@
+-- Public profile fields, and a pending email address awaiting confirmation
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS display_name TEXT,
+  ADD COLUMN IF NOT EXISTS avatar_url TEXT,
+  -- new address a change was requested to; it replaces email once the
+  -- link sent to it is followed
+  ADD COLUMN IF NOT EXISTS pending_email TEXT;
+
+ALTER TABLE account_tokens
+  DROP CONSTRAINT IF EXISTS account_tokens_purpose_check,
+  ADD CONSTRAINT account_tokens_purpose_check
+    CHECK (purpose IN ('verify_email', 'reset_password', 'change_email'));
@@ -0,0 +1,11 @@
+-- Token buckets for request rate limiting shared between instances
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+  -- "<policy>:user:<id>" or "<policy>:ip:<address>"
+  key TEXT PRIMARY KEY,
+  tokens DOUBLE PRECISION NOT NULL,
+  -- whether the last request took a token
+  allowed BOOLEAN NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
@@ -0,0 +1,3 @@
+-- Remove the viewer of recipe views
+DROP INDEX IF EXISTS idx_recipe_views_viewer;
+ALTER TABLE recipe_views DROP COLUMN IF EXISTS viewer;
@@ -0,0 +1,4 @@
+-- Who made each recipe view, so one viewer's repeated views count once
+ALTER TABLE recipe_views ADD COLUMN IF NOT EXISTS viewer TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_recipe_views_viewer ON recipe_views(recipe_id, viewer, viewed_at);
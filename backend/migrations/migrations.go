@@ -0,0 +1,30 @@
+// Package migrations embeds the SQL migrations of the database schema, which
+// are applied with the golang-migrate CLI (make migrateup), so the server
+// knows which schema version it was built against.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.up.sql
+var files embed.FS
+
+// Latest returns the version of the newest migration: the version
+// golang-migrate records in the schema_migrations table once every migration
+// has been applied.
+func Latest() uint {
+	names, _ := fs.Glob(files, "*.up.sql")
+	var latest uint
+	for _, name := range names {
+		prefix, _, _ := strings.Cut(name, "_")
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err == nil && uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest
+}
@@ -0,0 +1,22 @@
+-- name: CreateAccountToken :exec
+INSERT INTO account_tokens (user_id, purpose, token_hash, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: ConsumeAccountToken :one
+-- Mark an unused, unexpired token as used and return its owner
+UPDATE account_tokens
+SET used_at = now()
+WHERE token_hash = $1
+  AND purpose = $2
+  AND used_at IS NULL
+  AND expires_at > now()
+RETURNING user_id;
+
+-- name: InvalidateAccountTokens :exec
+-- Invalidate every outstanding token of a user for one purpose
+UPDATE account_tokens
+SET used_at = now()
+WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL;
+
+-- name: DeleteExpiredAccountTokens :exec
+DELETE FROM account_tokens WHERE expires_at < now() OR used_at IS NOT NULL;
@@ -0,0 +1,33 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, name, prefix, key_hash, scopes, created_at, last_used_at, expires_at;
+
+-- name: CountUserApiKeys :one
+SELECT COUNT(*)::integer AS key_count FROM api_keys WHERE user_id = $1;
+
+-- name: ListUserApiKeys :many
+SELECT id, user_id, name, prefix, key_hash, scopes, created_at, last_used_at, expires_at
+FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
+
+-- name: DeleteApiKey :execrows
+DELETE FROM api_keys WHERE id = $1 AND user_id = $2;
+
+-- name: UseApiKey :one
+-- Look up an unexpired key with its owner's role and record its use, writing last_used_at at most once a minute
+WITH key AS (
+  SELECT k.id, k.user_id, k.scopes, u.role, k.last_used_at
+  FROM api_keys k
+  JOIN users u ON u.id = k.user_id
+  WHERE k.key_hash = $1
+    AND (k.expires_at IS NULL OR k.expires_at > now())
+), touched AS (
+  UPDATE api_keys
+  SET last_used_at = now()
+  FROM key
+  WHERE api_keys.id = key.id
+    AND (key.last_used_at IS NULL OR key.last_used_at < now() - interval '1 minute')
+)
+SELECT key.id, key.user_id, key.scopes, key.role FROM key;
@@ -0,0 +1,17 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (event, user_id, subject, ip_address, details)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListAuditEvents :many
+-- Most recent first; filter by event name prefix (empty matches all)
+SELECT id, event, user_id, subject, ip_address, details, created_at
+FROM audit_events
+WHERE sqlc.arg(event_prefix)::text = '' OR event LIKE sqlc.arg(event_prefix)::text || '%'
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListAuditEventsByUser :many
+SELECT id, event, subject, ip_address, details, created_at
+FROM audit_events
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
@@ -16,8 +16,27 @@ JOIN recipes r ON r.id = f.recipe_id
 WHERE f.user_id = $1
 ORDER BY f.created_at DESC;
 
+-- name: ListFavoritesByUserPage :many
+-- Keyset page of a user's favorites, newest first; cursor_id 0 starts at the beginning
+SELECT f.id as favorite_id, f.user_id, f.recipe_id, f.created_at, 
+  r.title, r.description, r.cuisine, r.difficulty, r.diet_type, 
+  r.prep_time_minutes, r.cook_time_minutes, r.total_time_minutes, r.servings,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1) FROM ratings WHERE recipe_id = r.id)::text, '0') as average_rating,
+  (SELECT COUNT(*) FROM favorites c WHERE c.user_id = sqlc.arg(user_id))::integer AS total_count
+FROM favorites f
+JOIN recipes r ON r.id = f.recipe_id
+WHERE f.user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(cursor_id)::int = 0 OR f.id < sqlc.arg(cursor_id)::int)
+ORDER BY f.id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: IsFavorite :one
 SELECT EXISTS(
   SELECT 1 FROM favorites
   WHERE user_id = $1 AND recipe_id = $2
 ) as is_favorite;
+
+-- name: ListFavoritedRecipeIDs :many
+-- Which of the given recipes the user has favorited
+SELECT recipe_id FROM favorites
+WHERE user_id = sqlc.arg(user_id) AND recipe_id = ANY(sqlc.arg(recipe_ids)::int[]);
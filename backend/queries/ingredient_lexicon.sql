@@ -0,0 +1,13 @@
+-- name: ListIngredientLexicon :many
+SELECT variant, canonical, updated_at
+FROM ingredient_lexicon
+ORDER BY variant;
+
+-- name: UpsertIngredientLexicon :one
+INSERT INTO ingredient_lexicon (variant, canonical)
+VALUES ($1, $2)
+ON CONFLICT (variant) DO UPDATE SET canonical = EXCLUDED.canonical, updated_at = now()
+RETURNING variant, canonical, updated_at;
+
+-- name: DeleteIngredientLexicon :execrows
+DELETE FROM ingredient_lexicon WHERE variant = $1;
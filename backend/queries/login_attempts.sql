@@ -0,0 +1,33 @@
+-- name: GetLoginAttempt :one
+SELECT failures, last_failure_at, locked_until
+FROM login_attempts
+WHERE key = $1;
+
+-- name: RecordLoginFailure :one
+-- Count a failure, starting over when the previous failures (or the lock
+-- they caused) ended before window_start
+INSERT INTO login_attempts (key, failures, last_failure_at)
+VALUES (sqlc.arg(key), 1, sqlc.arg(now))
+ON CONFLICT (key) DO UPDATE
+SET failures = CASE
+      WHEN GREATEST(login_attempts.last_failure_at, COALESCE(login_attempts.locked_until, login_attempts.last_failure_at)) < sqlc.arg(window_start)
+        THEN 1
+      ELSE login_attempts.failures + 1
+    END,
+    locked_until = CASE
+      WHEN GREATEST(login_attempts.last_failure_at, COALESCE(login_attempts.locked_until, login_attempts.last_failure_at)) < sqlc.arg(window_start)
+        THEN NULL
+      ELSE login_attempts.locked_until
+    END,
+    last_failure_at = EXCLUDED.last_failure_at
+RETURNING failures, locked_until;
+
+-- name: SetLoginLock :exec
+UPDATE login_attempts SET locked_until = $2 WHERE key = $1;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts WHERE key = $1;
+
+-- name: DeleteStaleLoginAttempts :exec
+DELETE FROM login_attempts
+WHERE GREATEST(last_failure_at, COALESCE(locked_until, last_failure_at)) < sqlc.arg(cutoff);
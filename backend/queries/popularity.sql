@@ -0,0 +1,35 @@
+-- name: RecordRecipeView :exec
+-- Skipped when the viewer already viewed the recipe since the given time.
+-- Two views racing each other may both be stored, which only slightly
+-- inflates popularity and is not worth a lock.
+INSERT INTO recipe_views (recipe_id, user_id, viewer)
+SELECT sqlc.arg(recipe_id)::int, sqlc.narg(user_id)::int, sqlc.arg(viewer)::text
+WHERE NOT EXISTS (
+  SELECT 1 FROM recipe_views
+  WHERE recipe_id = sqlc.arg(recipe_id)::int
+    AND viewer = sqlc.arg(viewer)::text
+    AND viewed_at > sqlc.arg(since)::timestamptz
+);
+
+-- name: RefreshRecipePopularity :exec
+REFRESH MATERIALIZED VIEW CONCURRENTLY recipe_popularity;
+
+-- name: ListRecipesByPopularity :many
+-- List recipes ranked by trending or popular score within a time window
+SELECT r.id, r.title, r.description, r.cuisine, r.difficulty, r.diet_type, r.prep_time_minutes, r.cook_time_minutes, r.total_time_minutes, r.servings, r.tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings rt WHERE rt.recipe_id = r.id), '0') as average_rating,
+  p.favorites_count, p.ratings_count, p.views_count, p.popular_score, p.trending_score, p.refreshed_at
+FROM recipe_popularity p
+JOIN recipes r ON r.id = p.recipe_id
+WHERE p.time_window = sqlc.arg(time_window)
+ORDER BY
+  CASE WHEN sqlc.arg(rank_by)::text = 'trending' THEN p.trending_score ELSE p.popular_score END DESC,
+  r.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListRecipeViewsByUser :many
+SELECT v.recipe_id, r.title AS recipe_title, v.viewed_at
+FROM recipe_views v
+LEFT JOIN recipes r ON r.id = v.recipe_id
+WHERE v.user_id = $1
+ORDER BY v.viewed_at DESC, v.id DESC;
@@ -0,0 +1,19 @@
+-- name: TakeRateLimitToken :one
+-- Refill the bucket for the time since its last update, capped at capacity,
+-- then take a token if a whole one is left; allowed reports whether one was
+-- taken, and tokens is what the bucket holds afterwards.
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (sqlc.arg(key), sqlc.arg(capacity)::float8 - 1, true, sqlc.arg(now)::timestamptz)
+ON CONFLICT (key) DO UPDATE
+SET tokens = CASE
+      WHEN LEAST(sqlc.arg(capacity)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (sqlc.arg(now)::timestamptz - b.updated_at))::float8, 0) * sqlc.arg(rate)::float8) >= 1
+        THEN LEAST(sqlc.arg(capacity)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (sqlc.arg(now)::timestamptz - b.updated_at))::float8, 0) * sqlc.arg(rate)::float8) - 1
+      ELSE LEAST(sqlc.arg(capacity)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (sqlc.arg(now)::timestamptz - b.updated_at))::float8, 0) * sqlc.arg(rate)::float8)
+    END,
+    allowed = LEAST(sqlc.arg(capacity)::float8, b.tokens + GREATEST(EXTRACT(EPOCH FROM (sqlc.arg(now)::timestamptz - b.updated_at))::float8, 0) * sqlc.arg(rate)::float8) >= 1,
+    updated_at = GREATEST(b.updated_at, sqlc.arg(now)::timestamptz)
+RETURNING tokens, allowed;
+
+-- name: DeleteIdleRateLimitBuckets :exec
+-- Buckets untouched since cutoff have refilled completely and can be forgotten
+DELETE FROM rate_limit_buckets WHERE updated_at < sqlc.arg(cutoff);
@@ -12,6 +12,13 @@ SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes
 FROM recipes
 WHERE recipes.id = $1;
 
+-- name: GetRecipesByIDs :many
+-- Recipes with the given IDs, in no particular order; unknown IDs are left out
+SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, ingredients, steps, nutrition, tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
+FROM recipes
+WHERE recipes.id = ANY(sqlc.arg(ids)::int[]);
+
 -- name: InsertRating :one
 INSERT INTO ratings (user_id, recipe_id, rating)
 VALUES ($1, $2, $3)
@@ -22,16 +29,235 @@ SELECT id, user_id, recipe_id, rating, created_at
 FROM ratings
 WHERE ratings.recipe_id = $1;
 
+-- name: ListRatingsByRecipeIDs :many
+-- Ratings of several recipes, newest first within each recipe
+SELECT id, user_id, recipe_id, rating, created_at
+FROM ratings
+WHERE recipe_id = ANY(sqlc.arg(recipe_ids)::int[])
+ORDER BY recipe_id, created_at DESC, id DESC;
+
+-- name: ListRatingsPage :many
+-- Newest-first rating (review) listing for moderation, keyset-paginated by ID
+SELECT ratings.id, ratings.user_id, users.username, ratings.recipe_id, recipes.title AS recipe_title, ratings.rating, ratings.created_at,
+  (SELECT COUNT(*) FROM ratings)::integer AS total_count
+FROM ratings
+LEFT JOIN users ON users.id = ratings.user_id
+LEFT JOIN recipes ON recipes.id = ratings.recipe_id
+WHERE sqlc.arg(before_id)::int = 0 OR ratings.id < sqlc.arg(before_id)::int
+ORDER BY ratings.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: DeleteRating :execrows
+DELETE FROM ratings WHERE id = $1;
+
 -- name: SearchRecipes :many
--- Simple search by title or tags
+-- Simple search by title or tags, ordered by the requested sort key
 SELECT id, title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, ingredients, steps, nutrition, tags,
   COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating
 FROM recipes
-WHERE recipes.title ILIKE '%' || $1 || '%' OR $1 = ANY(recipes.tags)
-ORDER BY recipes.id
-LIMIT $2 OFFSET $3;
+WHERE recipes.title ILIKE '%' || sqlc.arg(query)::text || '%' OR sqlc.arg(query)::text = ANY(recipes.tags)
+ORDER BY
+  CASE WHEN sqlc.arg(sort)::text = 'rating' THEN (SELECT AVG(rating) FROM ratings r WHERE r.recipe_id = recipes.id) END DESC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'newest' THEN recipes.created_at END DESC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'quickest' THEN COALESCE(recipes.total_time_minutes, recipes.cook_time_minutes) END ASC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'popular' THEN (SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all') END DESC NULLS LAST,
+  recipes.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: CreateRecipe :one
-INSERT INTO recipes (title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, tags, ingredients, steps, nutrition)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+INSERT INTO recipes (title, description, cuisine, difficulty, diet_type, prep_time_minutes, cook_time_minutes, total_time_minutes, servings, tags, ingredients, steps, nutrition, author_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING id;
+
+-- name: UpdateRecipe :execrows
+UPDATE recipes
+SET title = $2, description = $3, cuisine = $4, difficulty = $5, diet_type = $6, prep_time_minutes = $7, cook_time_minutes = $8, total_time_minutes = $9, servings = $10, tags = $11, ingredients = $12, steps = $13, nutrition = $14, updated_at = now()
+WHERE id = $1;
+
+-- name: DeleteRecipe :execrows
+DELETE FROM recipes WHERE id = $1;
+
+-- name: GetRecipeAuthor :one
+SELECT author_id FROM recipes WHERE id = $1;
+
+-- name: SearchRecipesFullText :many
+-- Ranked full-text search with highlighted snippets; query is a to_tsquery expression
+SELECT recipes.id, recipes.title, recipes.description, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.total_time_minutes, recipes.servings, recipes.ingredients, recipes.steps, recipes.nutrition, recipes.tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = recipes.id), '0') as average_rating,
+  ts_rank(recipes.search_vector, tsq, 32)::float8 AS rank,
+  ts_headline('english',
+    concat_ws(' … ', recipes.description, recipe_ingredient_names(recipes.ingredients), recipe_steps_text(recipes.steps)),
+    tsq,
+    'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "')::text AS snippet
+FROM recipes, to_tsquery('english', sqlc.arg(query)::text) tsq
+WHERE recipes.search_vector @@ tsq
+ORDER BY
+  CASE WHEN sqlc.arg(sort)::text = 'rating' THEN (SELECT AVG(rating) FROM ratings r WHERE r.recipe_id = recipes.id) END DESC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'newest' THEN recipes.created_at END DESC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'quickest' THEN COALESCE(recipes.total_time_minutes, recipes.cook_time_minutes) END ASC NULLS LAST,
+  CASE WHEN sqlc.arg(sort)::text = 'popular' THEN (SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all') END DESC NULLS LAST,
+  rank DESC,
+  recipes.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountRecipeFacets :many
+-- Facet counts of the recipes matching a search, as (facet, value, count)
+-- rows. Each facet counts the recipes passing every filter but its own,
+-- grouped case-insensitively. Diets count under the diet_type, the tags
+-- naming one of known_diets and every diet those imply (implied_from[i]
+-- implies implied_to[i]). The time buckets are those of timeBuckets.
+WITH matched AS (
+  SELECT recipes.id, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.tags,
+    recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.servings,
+    COALESCE(NULLIF(recipes.total_time_minutes, 0), COALESCE(recipes.prep_time_minutes, 0) + recipes.cook_time_minutes, recipes.prep_time_minutes) AS total_time
+  FROM recipes
+  WHERE CASE
+    WHEN sqlc.arg(tsquery)::text <> '' THEN recipes.search_vector @@ to_tsquery('english', sqlc.arg(tsquery)::text)
+    ELSE recipes.title ILIKE '%' || sqlc.arg(query)::text || '%' OR sqlc.arg(query)::text = ANY(recipes.tags)
+  END
+), checked AS (
+  SELECT m.*,
+    COALESCE(cardinality(sqlc.arg(diets)::text[]) = 0
+      OR lower(trim(m.diet_type)) = ANY(sqlc.arg(diets)::text[])
+      OR EXISTS (SELECT 1 FROM unnest(m.tags) t WHERE lower(trim(t)) = ANY(sqlc.arg(diets)::text[])), false) AS diet_ok,
+    COALESCE(cardinality(sqlc.arg(difficulties)::text[]) = 0 OR lower(trim(m.difficulty)) = ANY(sqlc.arg(difficulties)::text[]), false) AS difficulty_ok,
+    COALESCE(cardinality(sqlc.arg(cuisines)::text[]) = 0 OR lower(trim(m.cuisine)) = ANY(sqlc.arg(cuisines)::text[]), false) AS cuisine_ok,
+    COALESCE(cardinality(sqlc.arg(tags)::text[]) = 0
+      OR EXISTS (SELECT 1 FROM unnest(m.tags) t WHERE lower(trim(t)) = ANY(sqlc.arg(tags)::text[])), false) AS tag_ok,
+    COALESCE(sqlc.narg(max_total_time)::int IS NULL OR m.total_time <= sqlc.narg(max_total_time)::int, false) AS total_ok,
+    COALESCE(sqlc.narg(max_cook_time)::int IS NULL OR m.cook_time_minutes <= sqlc.narg(max_cook_time)::int, false) AS cook_ok,
+    COALESCE((sqlc.narg(max_prep_time)::int IS NULL OR m.prep_time_minutes <= sqlc.narg(max_prep_time)::int)
+      AND (sqlc.narg(min_servings)::int IS NULL OR m.servings >= sqlc.narg(min_servings)::int)
+      AND (sqlc.narg(max_servings)::int IS NULL OR m.servings <= sqlc.narg(max_servings)::int), false) AS rest_ok
+  FROM matched m
+), diet_seeds AS (
+  SELECT c.id, lower(trim(c.diet_type)) AS diet
+  FROM checked c
+  WHERE c.rest_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_ok AND trim(c.diet_type) <> ''
+  UNION
+  SELECT c.id, lower(trim(t))
+  FROM checked c, unnest(c.tags) t
+  WHERE c.rest_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_ok
+    AND lower(trim(t)) = ANY(sqlc.arg(known_diets)::text[])
+)
+SELECT 'cuisine'::text AS facet, MIN(trim(cuisine))::text AS value, COUNT(*) AS count
+FROM checked
+WHERE rest_ok AND diet_ok AND difficulty_ok AND tag_ok AND total_ok AND cook_ok AND trim(cuisine) <> ''
+GROUP BY lower(trim(cuisine))
+UNION ALL
+SELECT 'difficulty', MIN(trim(difficulty))::text, COUNT(*)
+FROM checked
+WHERE rest_ok AND diet_ok AND cuisine_ok AND tag_ok AND total_ok AND cook_ok AND trim(difficulty) <> ''
+GROUP BY lower(trim(difficulty))
+UNION ALL
+SELECT 'diet_type', d.diet, COUNT(DISTINCT d.id)
+FROM (
+  SELECT id, diet FROM diet_seeds
+  UNION
+  SELECT s.id, i.implied
+  FROM diet_seeds s
+  JOIN unnest(sqlc.arg(implied_from)::text[], sqlc.arg(implied_to)::text[]) AS i(diet, implied) ON i.diet = s.diet
+) d
+GROUP BY d.diet
+UNION ALL
+SELECT 'tag', MIN(trim(t))::text, COUNT(DISTINCT c.id)
+FROM checked c, unnest(c.tags) t
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.total_ok AND c.cook_ok AND trim(t) <> ''
+GROUP BY lower(trim(t))
+UNION ALL
+SELECT 'cook_time', b.bucket, COUNT(*)
+FROM checked c, LATERAL (SELECT CASE
+    WHEN c.cook_time_minutes < 15 THEN '<15'
+    WHEN c.cook_time_minutes < 30 THEN '15-30'
+    WHEN c.cook_time_minutes < 60 THEN '30-60'
+    ELSE '60+'
+  END::text AS bucket) b
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.total_ok AND c.cook_time_minutes IS NOT NULL
+GROUP BY b.bucket
+UNION ALL
+SELECT 'total_time', b.bucket, COUNT(*)
+FROM checked c, LATERAL (SELECT CASE
+    WHEN c.total_time < 15 THEN '<15'
+    WHEN c.total_time < 30 THEN '15-30'
+    WHEN c.total_time < 60 THEN '30-60'
+    ELSE '60+'
+  END::text AS bucket) b
+WHERE c.rest_ok AND c.diet_ok AND c.difficulty_ok AND c.cuisine_ok AND c.tag_ok AND c.cook_ok AND c.total_time IS NOT NULL
+GROUP BY b.bucket;
+
+-- name: FilterRecipes :many
+-- Search, filter and keyset-paginate recipes by (sort_key DESC, id); total_count ignores the cursor.
+-- The match sort scores recipes by match_terms found in their tags and title, the suggestions sort
+-- by the score_weights of their score_tags; recipes scoring below min_score are left out
+WITH filtered AS (
+  SELECT recipes.id, recipes.title, recipes.description, recipes.cuisine, recipes.difficulty, recipes.diet_type, recipes.prep_time_minutes, recipes.cook_time_minutes, recipes.total_time_minutes, recipes.servings, recipes.ingredients, recipes.steps, recipes.nutrition, recipes.tags, recipes.search_vector,
+    (CASE
+      WHEN sqlc.arg(sort)::text = 'rating' THEN COALESCE((SELECT AVG(rating)::float8 FROM ratings r WHERE r.recipe_id = recipes.id), 0)
+      WHEN sqlc.arg(sort)::text = 'newest' THEN COALESCE(EXTRACT(EPOCH FROM recipes.created_at)::float8, 0)
+      WHEN sqlc.arg(sort)::text = 'quickest' THEN -COALESCE(NULLIF(recipes.total_time_minutes, 0), recipes.prep_time_minutes + recipes.cook_time_minutes, recipes.cook_time_minutes, recipes.prep_time_minutes, 1000000)::float8
+      WHEN sqlc.arg(sort)::text = 'popular' THEN COALESCE((SELECT p.popular_score FROM recipe_popularity p WHERE p.recipe_id = recipes.id AND p.time_window = 'all'), 0)
+      WHEN sqlc.arg(sort)::text = 'match' THEN (
+        (SELECT COUNT(*) FROM unnest(recipes.tags) t WHERE lower(t) = ANY(sqlc.arg(match_terms)::text[]))
+        + (SELECT COUNT(*) FROM unnest(sqlc.arg(match_terms)::text[]) m WHERE strpos(lower(recipes.title), m) > 0))::float8
+      WHEN sqlc.arg(sort)::text = 'suggestions' THEN COALESCE((SELECT SUM(w.weight) FROM unnest(recipes.tags) t
+        JOIN unnest(sqlc.arg(score_tags)::text[], sqlc.arg(score_weights)::int[]) AS w(tag, weight) ON lower(t) = w.tag), 0)::float8
+      WHEN sqlc.arg(tsquery)::text <> '' THEN ts_rank(recipes.search_vector, to_tsquery('english', sqlc.arg(tsquery)::text), 32)::float8
+      ELSE -recipes.id::float8
+    END)::float8 AS sort_key
+  FROM recipes
+  WHERE (CASE
+      WHEN sqlc.arg(tsquery)::text <> '' THEN recipes.search_vector @@ to_tsquery('english', sqlc.arg(tsquery)::text)
+      ELSE recipes.title ILIKE '%' || sqlc.arg(query)::text || '%' OR sqlc.arg(query)::text = ANY(recipes.tags)
+    END)
+    AND (cardinality(sqlc.arg(diets)::text[]) = 0
+      OR lower(trim(recipes.diet_type)) = ANY(sqlc.arg(diets)::text[])
+      OR EXISTS (SELECT 1 FROM unnest(recipes.tags) t WHERE lower(trim(t)) = ANY(sqlc.arg(diets)::text[])))
+    AND (cardinality(sqlc.arg(difficulties)::text[]) = 0 OR lower(trim(recipes.difficulty)) = ANY(sqlc.arg(difficulties)::text[]))
+    AND (cardinality(sqlc.arg(cuisines)::text[]) = 0 OR lower(trim(recipes.cuisine)) = ANY(sqlc.arg(cuisines)::text[]))
+    AND (cardinality(sqlc.arg(tags)::text[]) = 0
+      OR EXISTS (SELECT 1 FROM unnest(recipes.tags) t WHERE lower(trim(t)) = ANY(sqlc.arg(tags)::text[])))
+    AND (sqlc.narg(max_total_time)::int IS NULL
+      OR COALESCE(NULLIF(recipes.total_time_minutes, 0), COALESCE(recipes.prep_time_minutes, 0) + recipes.cook_time_minutes, recipes.prep_time_minutes) <= sqlc.narg(max_total_time)::int)
+    AND (sqlc.narg(max_prep_time)::int IS NULL OR recipes.prep_time_minutes <= sqlc.narg(max_prep_time)::int)
+    AND (sqlc.narg(max_cook_time)::int IS NULL OR recipes.cook_time_minutes <= sqlc.narg(max_cook_time)::int)
+    AND (sqlc.narg(min_servings)::int IS NULL OR recipes.servings >= sqlc.narg(min_servings)::int)
+    AND (sqlc.narg(max_servings)::int IS NULL OR recipes.servings <= sqlc.narg(max_servings)::int)
+), scored AS (
+  SELECT * FROM filtered
+  WHERE sqlc.narg(min_score)::float8 IS NULL OR filtered.sort_key >= sqlc.narg(min_score)::float8
+)
+SELECT f.id, f.title, f.description, f.cuisine, f.difficulty, f.diet_type, f.prep_time_minutes, f.cook_time_minutes, f.total_time_minutes, f.servings, f.ingredients, f.steps, f.nutrition, f.tags,
+  COALESCE((SELECT ROUND(AVG(rating)::numeric, 1)::text FROM ratings r WHERE r.recipe_id = f.id), '0') as average_rating,
+  f.sort_key,
+  (CASE WHEN sqlc.arg(tsquery)::text <> ''
+    THEN ts_rank(f.search_vector, to_tsquery('english', sqlc.arg(tsquery)::text), 32)::float8
+    ELSE 0 END)::float8 AS rank,
+  (CASE WHEN sqlc.arg(tsquery)::text <> ''
+    THEN ts_headline('english',
+      concat_ws(' … ', f.description, recipe_ingredient_names(f.ingredients), recipe_steps_text(f.steps)),
+      to_tsquery('english', sqlc.arg(tsquery)::text),
+      'StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=8, MaxFragments=2, FragmentDelimiter=" … "')
+    ELSE '' END)::text AS snippet,
+  (SELECT COUNT(*) FROM scored)::integer AS total_count
+FROM scored f
+WHERE sqlc.narg(cursor_key)::float8 IS NULL
+  OR f.sort_key < sqlc.narg(cursor_key)::float8
+  OR (f.sort_key = sqlc.narg(cursor_key)::float8 AND f.id > sqlc.arg(cursor_id)::int)
+ORDER BY f.sort_key DESC, f.id
+LIMIT sqlc.arg('limit');
+
+-- name: SetRecipeAuthor :execrows
+UPDATE recipes SET author_id = sqlc.narg(author_id) WHERE id = sqlc.arg(id);
+
+-- name: ListRatingsByUser :many
+SELECT ra.id, ra.recipe_id, r.title AS recipe_title, ra.rating, ra.created_at
+FROM ratings ra
+LEFT JOIN recipes r ON r.id = ra.recipe_id
+WHERE ra.user_id = $1
+ORDER BY ra.created_at DESC, ra.id DESC;
+
+-- name: ListRecipesByAuthor :many
+SELECT id, title, created_at, updated_at
+FROM recipes
+WHERE author_id = $1
+ORDER BY id;
@@ -0,0 +1,62 @@
+-- name: CreateSession :one
+INSERT INTO sessions (user_id, refresh_token_hash, access_jti, access_expires_at, user_agent, ip_address, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, refresh_token_hash, previous_token_hash, access_jti, access_expires_at, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at;
+
+-- name: RotateSession :one
+-- Swap a live refresh token for a new one; no row means the token is unknown, revoked, expired or already rotated
+UPDATE sessions
+SET previous_token_hash = refresh_token_hash,
+  refresh_token_hash = sqlc.arg(new_hash),
+  access_jti = sqlc.arg(access_jti),
+  access_expires_at = sqlc.arg(access_expires_at),
+  user_agent = sqlc.arg(user_agent),
+  ip_address = sqlc.arg(ip_address),
+  last_used_at = now()
+WHERE refresh_token_hash = sqlc.arg(old_hash)
+  AND revoked_at IS NULL
+  AND expires_at > now()
+RETURNING id, user_id;
+
+-- name: RevokeSessionByPreviousToken :one
+-- Revoke the session whose already-rotated refresh token was presented again
+UPDATE sessions
+SET revoked_at = now()
+WHERE previous_token_hash = $1 AND revoked_at IS NULL
+RETURNING access_jti, access_expires_at;
+
+-- name: ListUserSessions :many
+SELECT id, user_agent, ip_address, created_at, last_used_at, expires_at
+FROM sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeSession :one
+UPDATE sessions
+SET revoked_at = now()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+RETURNING access_jti, access_expires_at;
+
+-- name: RevokeOtherSessions :many
+UPDATE sessions
+SET revoked_at = now()
+WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+RETURNING access_jti, access_expires_at;
+
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (jti, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS(
+  SELECT 1 FROM revoked_tokens WHERE jti = $1
+) as revoked;
+
+-- name: DeleteExpiredSessions :exec
+DELETE FROM sessions
+WHERE expires_at < now()
+  OR revoked_at < now() - interval '1 day';
+
+-- name: DeleteExpiredRevokedTokens :exec
+DELETE FROM revoked_tokens WHERE expires_at < now();
@@ -0,0 +1,25 @@
+-- name: ListSigningKeys :many
+-- Live keys plus keys retired after retired_after, oldest first
+SELECT kid, algorithm, private_key_pem, public_key_pem, created_at, retired_at
+FROM signing_keys
+WHERE retired_at IS NULL OR retired_at > sqlc.arg(retired_after)
+ORDER BY created_at, kid;
+
+-- name: CreateSigningKey :exec
+INSERT INTO signing_keys (kid, algorithm, private_key_pem, public_key_pem)
+VALUES ($1, $2, $3, $4);
+
+-- name: RetireOlderSigningKeys :exec
+-- Retire every live key except the newest one
+UPDATE signing_keys
+SET retired_at = now(), private_key_pem = NULL
+WHERE retired_at IS NULL
+  AND kid <> (
+    SELECT newest.kid FROM signing_keys newest
+    WHERE newest.retired_at IS NULL
+    ORDER BY newest.created_at DESC, newest.kid DESC
+    LIMIT 1
+  );
+
+-- name: DeleteRetiredSigningKeys :exec
+DELETE FROM signing_keys WHERE retired_at < $1;
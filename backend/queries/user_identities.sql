@@ -0,0 +1,40 @@
+-- name: UseUserIdentity :one
+-- Record a sign-in with a linked identity and return its user
+UPDATE user_identities
+SET last_login_at = now(), email = sqlc.narg(email)
+WHERE provider = sqlc.arg(provider) AND subject = sqlc.arg(subject)
+RETURNING user_id;
+
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (user_id, provider, subject, email)
+VALUES ($1, $2, $3, $4);
+
+-- name: CreateUserWithIdentity :one
+-- Create a user without a password together with the identity they signed in with
+WITH new_user AS (
+  INSERT INTO users (username, email, email_verified_at)
+  VALUES (sqlc.arg(username), sqlc.arg(email), CASE WHEN sqlc.arg(email_verified)::boolean THEN now() END)
+  RETURNING id
+)
+INSERT INTO user_identities (user_id, provider, subject, email)
+SELECT id, sqlc.arg(provider), sqlc.arg(subject), sqlc.arg(email) FROM new_user
+RETURNING user_id;
+
+-- name: ListUserIdentities :many
+SELECT id, provider, subject, email, created_at, last_login_at
+FROM user_identities
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: CreateOidcLoginState :exec
+INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ConsumeOidcLoginState :one
+-- Take a pending login for a provider; each state works once
+DELETE FROM oidc_login_states
+WHERE state_hash = $1 AND provider = $2 AND expires_at > now()
+RETURNING nonce, code_verifier;
+
+-- name: DeleteExpiredOidcLoginStates :exec
+DELETE FROM oidc_login_states WHERE expires_at < now();
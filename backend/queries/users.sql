@@ -4,7 +4,7 @@ VALUES ($1, $2, $3)
 RETURNING id, username, email, created_at;
 
 -- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at
+SELECT id, username, email, password_hash, created_at, email_verified_at
 FROM users
 WHERE email = $1;
 
@@ -12,3 +12,60 @@ WHERE email = $1;
 SELECT id, username, email, created_at
 FROM users
 WHERE id = $1;
+
+-- name: GetUsersByIDs :many
+-- Public profiles of several users; unknown IDs are left out
+SELECT id, username, display_name, avatar_url, role, created_at
+FROM users
+WHERE id = ANY(sqlc.arg(ids)::int[]);
+
+-- name: GetUserCredentials :one
+SELECT id, email, password_hash, email_verified_at
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserPassword :exec
+UPDATE users SET password_hash = $2 WHERE id = $1;
+
+-- name: MarkEmailVerified :exec
+UPDATE users SET email_verified_at = now() WHERE id = $1 AND email_verified_at IS NULL;
+
+-- name: GetUserRole :one
+SELECT role FROM users WHERE id = $1;
+
+-- name: ListUsersPage :many
+-- Keyset-paginated user listing by ID; total_count ignores the cursor
+SELECT id, username, email, role, email_verified_at, created_at,
+  (SELECT COUNT(*) FROM users)::integer AS total_count
+FROM users
+WHERE id > sqlc.arg(after_id)::int
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = sqlc.arg(role) WHERE id = sqlc.arg(id)
+RETURNING id, username, email, role, email_verified_at, created_at;
+
+-- name: GetUserProfile :one
+SELECT id, username, email, pending_email, email_verified_at, display_name, avatar_url, role, created_at,
+  (password_hash IS NOT NULL)::boolean AS has_password
+FROM users
+WHERE id = $1;
+
+-- name: UpdateUserProfile :exec
+UPDATE users SET username = $2, display_name = $3, avatar_url = $4 WHERE id = $1;
+
+-- name: SetPendingEmail :exec
+UPDATE users SET pending_email = $2 WHERE id = $1;
+
+-- name: ConfirmEmailChange :one
+-- Swap in the pending address; following the link sent to it verified it
+UPDATE users
+SET email = pending_email, pending_email = NULL, email_verified_at = now()
+WHERE id = $1 AND pending_email IS NOT NULL
+RETURNING email;
+
+-- name: DeleteUser :execrows
+-- Favorites, ratings, views, sessions, tokens, keys and identities cascade;
+-- authored recipes and audit events are kept without the user
+DELETE FROM users WHERE id = $1;
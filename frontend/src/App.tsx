@@ -20,6 +20,7 @@ import {
   DropdownMenuTrigger,
 } from './components/ui/dropdown-menu'
 import { useAuth } from './auth'
+import { api } from './api'
 
 import RecipesList from './pages/RecipesList'
 import RecipeDetail from './pages/RecipeDetail'
@@ -28,6 +29,8 @@ import FavoritesPage from './pages/FavoritesPage'
 import SuggestionsPage from './pages/SuggestionsPage'
 import LoginPage from './pages/LoginPage'
 import RegisterPage from './pages/RegisterPage'
+import ResetPasswordPage from './pages/ResetPasswordPage'
+import VerifyEmailPage from './pages/VerifyEmailPage'
 
 /**
  * Navigation Component
@@ -44,6 +47,9 @@ function Navigation() {
   const location = useLocation()
 
   const handleLogout = () => {
+    if (token) {
+      api.logout(token).catch(() => {})
+    }
     setToken(null)
     navigate('/login')
   }
@@ -261,6 +267,9 @@ function App() {
         <Route path="/suggestions" element={<SuggestionsPage />} />
         <Route path="/login" element={<LoginPage />} />
         <Route path="/register" element={<RegisterPage />} />
+        <Route path="/forgot-password" element={<ResetPasswordPage />} />
+        <Route path="/reset-password" element={<ResetPasswordPage />} />
+        <Route path="/verify-email" element={<VerifyEmailPage />} />
         <Route path="*" element={<Navigate to="/" replace />} />
       </Routes>
     </div>
@@ -13,11 +13,23 @@
 export type Json = Record<string, unknown> | unknown[] | string | number | boolean | null
 
 /**
- * Standard API error structure
+ * Problem with a single input field, as reported by the API
+ */
+export type FieldError = {
+  field: string
+  message: string
+}
+
+/**
+ * Standard API error structure. The backend sends the same shape for every
+ * error: a machine-readable code, a message and, for invalid input, the
+ * offending fields.
  */
 export type ApiError = {
   status: number
+  code?: string
   message: string
+  fields?: FieldError[]
 }
 
 /**
@@ -25,6 +37,88 @@ export type ApiError = {
  */
 const BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8081'
 
+/**
+ * Cursor-paginated list envelope returned by /recipes, /match, /favorites
+ * and /suggestions. Pass nextCursor back as the `cursor` query parameter to
+ * fetch the next page; it is null on the last page.
+ */
+export interface Page<T> {
+  items: T[]
+  nextCursor: string | null
+  total: number
+}
+
+/**
+ * Tokens returned by login, register and refresh. The access token is
+ * short-lived; the refresh token is single-use and kept in localStorage.
+ */
+export interface TokenResponse {
+  token: string
+  refreshToken: string
+  expiresAt: string
+}
+
+const REFRESH_TOKEN_KEY = 'refreshToken'
+
+type TokenListener = (token: string | null) => void
+const tokenListeners = new Set<TokenListener>()
+
+/**
+ * Subscribe to access-token changes caused by silent refreshes.
+ * The listener receives null when the session could not be refreshed.
+ * @returns {() => void} Unsubscribe function
+ */
+export function onTokenRefresh(listener: TokenListener) {
+  tokenListeners.add(listener)
+  return () => {
+    tokenListeners.delete(listener)
+  }
+}
+
+/**
+ * Remember the refresh token from a login, register or refresh response
+ */
+function rememberSession(res: TokenResponse) {
+  localStorage.setItem(REFRESH_TOKEN_KEY, res.refreshToken)
+  return res
+}
+
+let pendingRefresh: Promise<string | null> | null = null
+
+/**
+ * Exchange the stored refresh token for a new token pair.
+ * Concurrent callers share one request, since each refresh token works once.
+ * @returns {Promise<string | null>} New access token, or null if the session ended
+ */
+function refreshAccessToken(): Promise<string | null> {
+  const refreshToken = localStorage.getItem(REFRESH_TOKEN_KEY)
+  if (!refreshToken) return Promise.resolve(null)
+
+  if (!pendingRefresh) {
+    pendingRefresh = fetch(`${BASE_URL}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refreshToken }),
+    })
+      .then(async (res) => {
+        if (!res.ok) {
+          localStorage.removeItem(REFRESH_TOKEN_KEY)
+          return null
+        }
+        return rememberSession((await res.json()) as TokenResponse).token
+      })
+      .catch(() => null)
+      .then((token) => {
+        tokenListeners.forEach((listener) => listener(token))
+        return token
+      })
+      .finally(() => {
+        pendingRefresh = null
+      })
+  }
+  return pendingRefresh
+}
+
 /**
  * Generic HTTP request handler with authentication and error handling
  * 
@@ -32,6 +126,7 @@ const BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:8081'
  * @param {string} path - API endpoint path
  * @param {RequestInit} opts - Fetch options
  * @param {string} token - Optional authentication token
+ * @param {boolean} retried - Set on the retry after a silent token refresh
  * @returns {Promise<T>} Parsed response data
  * @throws {ApiError} API error with status and message
  */
@@ -39,6 +134,7 @@ async function request<T>(
   path: string,
   opts: RequestInit = {},
   token?: string,
+  retried = false,
 ): Promise<T> {
   const headers: Record<string, string> = {
     ...(opts.headers as Record<string, string> | undefined),
@@ -53,12 +149,24 @@ async function request<T>(
   }
 
   const res = await fetch(`${BASE_URL}${path}`, { ...opts, headers })
+
+  if (res.status === 401 && token && !retried) {
+    const refreshed = await refreshAccessToken()
+    if (refreshed) {
+      return request<T>(path, opts, refreshed, true)
+    }
+  }
+
   const text = await res.text()
   const data = text ? (JSON.parse(text) as T) : (undefined as unknown as T)
 
   if (!res.ok) {
-    const message = (data as any)?.message || res.statusText
-    throw { status: res.status, message } as ApiError
+    const body = data as Partial<ApiError> | undefined
+    const fields = body?.fields
+    const message = fields?.length
+      ? fields.map((f) => `${f.field} ${f.message}`).join('; ')
+      : body?.message || res.statusText
+    throw { status: res.status, code: body?.code, message, fields } as ApiError
   }
 
   return data
@@ -72,34 +180,86 @@ export const api = {
    * Authenticate user with email and password
    * @param {string} email - User email
    * @param {string} password - User password
-   * @returns {Promise<{token: string}>} Authentication token
+   * @returns {Promise<TokenResponse>} Access and refresh tokens
    */
   login: (email: string, password: string) =>
-    request<{ token: string }>('/auth/login', {
+    request<TokenResponse>('/auth/login', {
       method: 'POST',
       body: JSON.stringify({ email, password }),
-    }),
+    }).then(rememberSession),
+
+  /**
+   * End the current session on the server and forget the refresh token
+   * @param {string} token - Authentication token
+   */
+  logout: (token: string) => {
+    localStorage.removeItem(REFRESH_TOKEN_KEY)
+    return request<void>('/auth/logout', { method: 'POST' }, token, true)
+  },
 
   /**
    * Register a new user account
    * @param {string} username - Desired username
    * @param {string} email - User email
    * @param {string} password - User password
-   * @returns {Promise<{token: string}>} Authentication token
+   * @returns {Promise<TokenResponse>} Access and refresh tokens
    */
   register: (username: string, email: string, password: string) =>
-    request<{ token: string }>('/auth/register', {
+    request<TokenResponse>('/auth/register', {
       method: 'POST',
       body: JSON.stringify({ username, email, password }),
+    }).then(rememberSession),
+
+  /**
+   * Email a password reset link. Succeeds whether or not the email is registered.
+   * @param {string} email - Account email
+   */
+  forgotPassword: (email: string) =>
+    request<void>('/auth/forgot-password', {
+      method: 'POST',
+      body: JSON.stringify({ email }),
     }),
 
+  /**
+   * Set a new password with the token from a reset email
+   * @param {string} token - Reset token from the emailed link
+   * @param {string} password - New password
+   */
+  resetPassword: (token: string, password: string) =>
+    request<void>('/auth/reset-password', {
+      method: 'POST',
+      body: JSON.stringify({ token, password }),
+    }),
+
+  /**
+   * Confirm an email address with the token from a verification email
+   * @param {string} token - Verification token from the emailed link
+   */
+  verifyEmail: (token: string) =>
+    request<void>('/auth/verify-email', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    }),
+
+  /**
+   * Change the signed-in user's password. Other sessions are signed out.
+   * @param {string} token - Authentication token
+   * @param {string} currentPassword - Current password
+   * @param {string} newPassword - New password
+   */
+  changePassword: (token: string, currentPassword: string, newPassword: string) =>
+    request<void>('/me/password', {
+      method: 'PUT',
+      body: JSON.stringify({ currentPassword, newPassword }),
+    }, token),
+
   /**
    * Fetch list of recipes with optional filters
    * @param {URLSearchParams} params - Query parameters for filtering
-   * @returns {Promise<any[]>} Array of recipe objects
+   * @returns {Promise<Page<any>>} Page of recipe objects
    */
   listRecipes: (params: URLSearchParams) =>
-    request<any[]>(`/recipes?${params.toString()}`),
+    request<Page<any>>(`/recipes?${params.toString()}`),
 
   /**
    * Get detailed information for a specific recipe
@@ -113,10 +273,10 @@ export const api = {
    * Find recipes matching given ingredients
    * @param {string[]} ingredients - List of ingredients to match
    * @param {URLSearchParams} params - Optional filters
-   * @returns {Promise<any>} Matching recipes
+   * @returns {Promise<Page<any>>} Page of matching recipes
    */
   match: (ingredients: string[], params?: URLSearchParams) =>
-    request(`/match${params && params.toString() ? `?${params.toString()}` : ''}`, {
+    request<Page<any>>(`/match${params && params.toString() ? `?${params.toString()}` : ''}`, {
       method: 'POST',
       body: JSON.stringify({ detectedIngredients: ingredients }),
     }),
@@ -180,10 +340,10 @@ export const api = {
   /**
    * Get list of user's favorite recipes
    * @param {string} token - Authentication token
-   * @returns {Promise<any>} Array of favorite recipes
+   * @returns {Promise<Page<any>>} Page of favorite recipes
    */
   listFavorites: (token: string) =>
-    request('/favorites', {}, token),
+    request<Page<any>>('/favorites', {}, token),
 
   /**
    * Check if a specific recipe is in user's favorites
@@ -197,10 +357,10 @@ export const api = {
   /**
    * Get personalized recipe suggestions based on user preferences
    * @param {string} token - Authentication token
-   * @returns {Promise<any>} Array of suggested recipes
+   * @returns {Promise<Page<any>>} Page of suggested recipes
    */
   suggestions: (token: string) =>
-    request('/suggestions', {}, token),
+    request<Page<any>>('/suggestions', {}, token),
 }
 
 
@@ -9,6 +9,7 @@
  */
 
 import { createContext, useContext, useEffect, useMemo, useState } from 'react'
+import { onTokenRefresh } from './api'
 
 /**
  * Authentication state structure
@@ -27,7 +28,8 @@ const AuthContext = createContext<AuthState | undefined>(undefined)
  * Authentication Provider Component
  * 
  * Wraps the application to provide authentication state and token management.
- * Automatically syncs authentication token with localStorage for persistence.
+ * Automatically syncs authentication token with localStorage for persistence,
+ * and picks up access tokens renewed by the API client's silent refresh.
  * 
  * @param {Object} props - Component props
  * @param {React.ReactNode} props.children - Child components
@@ -46,6 +48,8 @@ export function AuthProvider({ children }: { children: React.ReactNode }) {
     }
   }, [token])
 
+  useEffect(() => onTokenRefresh(setToken), [])
+
   const value = useMemo(() => ({ token, setToken }), [token])
 
   return (
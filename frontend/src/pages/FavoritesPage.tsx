@@ -47,7 +47,7 @@ export default function FavoritesPage() {
     
     setLoading(true)
     api.listFavorites(token)
-      .then((l) => setList(l as any[]))
+      .then((page) => setList(page.items))
       .catch(() => setList([]))
       .finally(() => setLoading(false))
   }
@@ -148,8 +148,8 @@ export default function MatchPage() {
       params.set('limit', '12')
 
       const res = await api.match(detected, params)
-      setMatchedRecipes(res as any[])
-      toast.success(`Found ${(res as any[]).length} matching recipes!`)
+      setMatchedRecipes(res.items)
+      toast.success(`Found ${res.total} matching recipes!`)
     } catch (e) {
       toast.error('Failed to find matching recipes')
       setMatchedRecipes([])
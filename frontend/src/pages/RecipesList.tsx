@@ -59,7 +59,7 @@ export default function RecipesList() {
     if (maxTime) params.set('maxTime', maxTime)
     
     try {
-      const data = await api.listRecipes(params)
+      const { items: data } = await api.listRecipes(params)
       setRecipes(data)
       
       if (token && data.length > 0) {
@@ -105,7 +105,7 @@ export default function RecipesList() {
     setCuisine('')
     setMaxTime('')
     const params = new URLSearchParams({ limit: '50' })
-    api.listRecipes(params).then(setRecipes).catch(() => setRecipes([]))
+    api.listRecipes(params).then((page) => setRecipes(page.items)).catch(() => setRecipes([]))
   }
 
   /**
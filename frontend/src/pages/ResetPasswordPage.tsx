@@ -0,0 +1,129 @@
+/**
+ * Reset Password Page Component
+ * 
+ * Without a token, asks for the account email and sends a reset link.
+ * Opened from that link (?token=...), lets the user choose a new password.
+ * 
+ * @module ResetPasswordPage
+ */
+
+import React, { useState } from 'react'
+import { Link, useNavigate, useSearchParams } from 'react-router-dom'
+import { api } from '../api'
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from '../components/ui/card'
+import { Input } from '../components/ui/input'
+import { Label } from '../components/ui/label'
+import { Button } from '../components/ui/button'
+import { Mail, Lock } from 'lucide-react'
+import { toast } from 'sonner'
+
+/**
+ * ResetPasswordPage Component
+ * 
+ * @returns {JSX.Element} Forgot or reset password form
+ */
+export default function ResetPasswordPage() {
+  const [params] = useSearchParams()
+  const token = params.get('token')
+  const navigate = useNavigate()
+  const [email, setEmail] = useState('')
+  const [password, setPassword] = useState('')
+  const [loading, setLoading] = useState(false)
+  const [sent, setSent] = useState(false)
+
+  /**
+   * Request a reset link, or set the new password when a token is present
+   * 
+   * @param {React.FormEvent} e - Form submission event
+   */
+  async function submit(e: React.FormEvent) {
+    e.preventDefault()
+    setLoading(true)
+
+    try {
+      if (token) {
+        await api.resetPassword(token, password)
+        toast.success('Password updated. Please log in.')
+        navigate('/login')
+      } else {
+        await api.forgotPassword(email)
+        setSent(true)
+      }
+    } catch (e: any) {
+      toast.error(e.message || 'Request failed')
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  return (
+    <div className="container mx-auto px-4 py-8">
+      <div className="max-w-md mx-auto">
+        <Card>
+          <form onSubmit={submit}>
+            <CardHeader>
+              <CardTitle>{token ? 'Choose a new password' : 'Forgot your password?'}</CardTitle>
+              <CardDescription>
+                {token
+                  ? 'All your devices will be signed out'
+                  : "Enter your email and we'll send you a reset link"}
+              </CardDescription>
+            </CardHeader>
+            <CardContent className="space-y-4">
+              {sent ? (
+                <p className="text-sm text-muted-foreground">
+                  If an account exists for {email}, a reset link is on its way.
+                </p>
+              ) : token ? (
+                <div className="space-y-2">
+                  <Label htmlFor="password">New password</Label>
+                  <div className="relative">
+                    <Lock className="absolute left-3 top-1/2 -translate-y-1/2 h-4 w-4 text-muted-foreground" />
+                    <Input
+                      id="password"
+                      type="password"
+                      placeholder="••••••••"
+                      value={password}
+                      onChange={(e) => setPassword(e.target.value)}
+                      minLength={8}
+                      required
+                      className="pl-9"
+                    />
+                  </div>
+                </div>
+              ) : (
+                <div className="space-y-2">
+                  <Label htmlFor="email">Email</Label>
+                  <div className="relative">
+                    <Mail className="absolute left-3 top-1/2 -translate-y-1/2 h-4 w-4 text-muted-foreground" />
+                    <Input
+                      id="email"
+                      type="email"
+                      placeholder="you@example.com"
+                      value={email}
+                      onChange={(e) => setEmail(e.target.value)}
+                      required
+                      className="pl-9"
+                    />
+                  </div>
+                </div>
+              )}
+            </CardContent>
+            <CardFooter className="flex flex-col space-y-4">
+              {!sent && (
+                <Button type="submit" className="w-full" disabled={loading}>
+                  {loading ? 'Please wait...' : token ? 'Set password' : 'Send reset link'}
+                </Button>
+              )}
+              <div className="text-sm text-center text-muted-foreground">
+                <Link to="/login" className="text-primary hover:underline font-medium">
+                  Back to login
+                </Link>
+              </div>
+            </CardFooter>
+          </form>
+        </Card>
+      </div>
+    </div>
+  )
+}
@@ -45,7 +45,7 @@ export default function SuggestionsPage() {
     
     setLoading(true)
     api.suggestions(token)
-      .then((l) => setList(l as any[]))
+      .then((page) => setList(page.items))
       .catch(() => setList([]))
       .finally(() => setLoading(false))
   }, [token])
@@ -0,0 +1,54 @@
+/**
+ * Verify Email Page Component
+ * 
+ * Opened from the verification email (?token=...). Confirms the address
+ * with the backend and reports the result.
+ * 
+ * @module VerifyEmailPage
+ */
+
+import { useEffect, useState } from 'react'
+import { Link, useSearchParams } from 'react-router-dom'
+import { api } from '../api'
+import { Card, CardContent, CardHeader, CardTitle } from '../components/ui/card'
+
+/**
+ * VerifyEmailPage Component
+ * 
+ * @returns {JSX.Element} Verification status card
+ */
+export default function VerifyEmailPage() {
+  const [params] = useSearchParams()
+  const token = params.get('token') || ''
+  const [status, setStatus] = useState<'pending' | 'done' | 'failed'>('pending')
+  const [message, setMessage] = useState('')
+
+  useEffect(() => {
+    api.verifyEmail(token)
+      .then(() => setStatus('done'))
+      .catch((e: any) => {
+        setMessage(e.message || 'Verification failed')
+        setStatus('failed')
+      })
+  }, [token])
+
+  return (
+    <div className="container mx-auto px-4 py-8">
+      <div className="max-w-md mx-auto">
+        <Card>
+          <CardHeader>
+            <CardTitle>Email verification</CardTitle>
+          </CardHeader>
+          <CardContent className="space-y-4 text-sm text-muted-foreground">
+            {status === 'pending' && <p>Verifying your email...</p>}
+            {status === 'done' && <p>Your email address is confirmed. Thanks!</p>}
+            {status === 'failed' && <p>{message}. The link may have expired or already been used.</p>}
+            <Link to="/" className="text-primary hover:underline font-medium">
+              Go to recipes
+            </Link>
+          </CardContent>
+        </Card>
+      </div>
+    </div>
+  )
+}